@@ -12,6 +12,7 @@ import (
 
 	"github.com/lexlapax/go-flock/pkg/agents"
 	"github.com/lexlapax/go-flock/pkg/common"
+	"github.com/lexlapax/go-flock/pkg/tools"
 	ldomain "github.com/lexlapax/go-llms/pkg/llm/domain"
 	"github.com/lexlapax/go-llms/pkg/llm/provider"
 )
@@ -22,6 +23,7 @@ func main() {
 		query        = flag.String("query", "", "Research query (required)")
 		format       = flag.String("format", "markdown", "Output format: markdown, json, or text")
 		model        = flag.String("model", "", "LLM model to use (optional, uses provider default if not specified)")
+		models       = flag.String("models", "", "Path to models.json, used to fit tool output into the -model's context window (optional)")
 		providerName = flag.String("provider", "", "LLM provider: openai, anthropic, or gemini (uses environment default if not specified)")
 		output       = flag.String("output", "", "Output file (optional, prints to stdout if not specified)")
 		debug        = flag.Bool("debug", false, "Enable debug logging")
@@ -37,6 +39,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s -query \"deep learning medical imaging\"\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -query \"climate change impacts\" -format json -output results.json\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -query \"quantum computing algorithms\" -provider openai -model gpt-4\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -query \"protein folding\" -model gpt-4o -models models.json\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nEnvironment Variables:\n")
 		fmt.Fprintf(os.Stderr, "  OPENAI_API_KEY     - API key for OpenAI\n")
 		fmt.Fprintf(os.Stderr, "  ANTHROPIC_API_KEY  - API key for Anthropic\n")
@@ -94,6 +97,14 @@ func main() {
 	if *model != "" {
 		agentOpts.Model = *model
 	}
+	if *models != "" {
+		catalog, err := tools.LoadModelCatalog(*models)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading model catalog: %v\n", err)
+			os.Exit(1)
+		}
+		agentOpts.Models = catalog
+	}
 
 	// Create the research papers agent
 	logger.Debug(ctx, "Creating research papers agent", "format", outputFormat, "model", agentOpts.Model)
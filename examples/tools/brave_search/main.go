@@ -22,9 +22,8 @@ func main() {
 	fmt.Printf("Created tool: %s\n", searchTool.Name())
 	fmt.Printf("Description: %s\n\n", searchTool.Description())
 
-	// Check for API key
-	apiKey := os.Getenv("BRAVE_SEARCH_API_KEY")
-	if apiKey == "" {
+	// Check for API key (the tool reads BRAVE_SEARCH_API_KEY through its default credential provider)
+	if os.Getenv("BRAVE_SEARCH_API_KEY") == "" {
 		fmt.Println("⚠️  No BRAVE_SEARCH_API_KEY environment variable found")
 		fmt.Println("You can get a free API key at https://api.search.brave.com")
 		fmt.Println("Then set it: export BRAVE_SEARCH_API_KEY=your_api_key")
@@ -38,9 +37,8 @@ func main() {
 	fmt.Println("---------------------------")
 
 	searchParams := tools.SearchWebBraveParams{
-		Query:  "golang web frameworks 2024",
-		APIKey: apiKey,
-		Count:  5,
+		Query: "golang web frameworks 2024",
+		Count: 5,
 	}
 
 	ctx := context.Background()
@@ -74,7 +72,6 @@ func main() {
 
 	summaryParams := tools.SearchWebBraveParams{
 		Query:   "what is quantum computing explained simply",
-		APIKey:  apiKey,
 		Count:   3,
 		Summary: true,
 	}
@@ -107,7 +104,6 @@ func main() {
 
 	newsParams := tools.SearchWebBraveParams{
 		Query:        "artificial intelligence breakthroughs",
-		APIKey:       apiKey,
 		Count:        10,
 		Freshness:    "pd", // Past day
 		ResultFilter: []string{"news"},
@@ -144,7 +140,6 @@ func main() {
 
 	multiParams := tools.SearchWebBraveParams{
 		Query:        "SpaceX starship launch",
-		APIKey:       apiKey,
 		Count:        10,
 		ResultFilter: []string{"web", "news", "videos"},
 	}
@@ -194,7 +189,6 @@ func main() {
 
 	safeParams := tools.SearchWebBraveParams{
 		Query:      "educational content for kids",
-		APIKey:     apiKey,
 		Count:      5,
 		SafeSearch: "strict",
 		SearchLang: "en",
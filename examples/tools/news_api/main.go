@@ -22,9 +22,8 @@ func main() {
 	fmt.Printf("Created tool: %s\n", searchTool.Name())
 	fmt.Printf("Description: %s\n\n", searchTool.Description())
 
-	// Check for API key
-	apiKey := os.Getenv("NEWS_API_KEY")
-	if apiKey == "" {
+	// Check for API key (the tool reads NEWS_API_KEY through its default credential provider)
+	if os.Getenv("NEWS_API_KEY") == "" {
 		fmt.Println("⚠️  No NEWS_API_KEY environment variable found")
 		fmt.Println("You can get a free API key at https://newsapi.org")
 		fmt.Println("Then set it: export NEWS_API_KEY=your_api_key")
@@ -39,7 +38,6 @@ func main() {
 
 	searchParams := tools.SearchNewsAPIParams{
 		Query:    "artificial intelligence",
-		APIKey:   apiKey,
 		Language: "en",
 		PageSize: 5,
 		SortBy:   "popularity",
@@ -78,7 +76,6 @@ func main() {
 
 	filteredParams := tools.SearchNewsAPIParams{
 		Query:    "climate change",
-		APIKey:   apiKey,
 		Language: "en",
 		DateFrom: weekAgo,
 		DateTo:   today,
@@ -105,7 +102,6 @@ func main() {
 
 	domainParams := tools.SearchNewsAPIParams{
 		Query:    "technology",
-		APIKey:   apiKey,
 		Domains:  "techcrunch.com,wired.com,arstechnica.com",
 		PageSize: 5,
 	}
@@ -130,7 +126,6 @@ func main() {
 	// Get first page
 	page1Params := tools.SearchNewsAPIParams{
 		Query:    "sports",
-		APIKey:   apiKey,
 		PageSize: 10,
 		Page:     1,
 	}
@@ -3,8 +3,11 @@ module github.com/lexlapax/go-flock
 go 1.24.3
 
 require (
+	github.com/andybalholm/cascadia v1.3.3
+	github.com/ledongthuc/pdf v0.0.0-20250511090121-5959a4027728
 	github.com/lexlapax/go-llms v0.2.6
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/net v0.47.0
 )
 
 require (
@@ -15,5 +18,6 @@ require (
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
+	golang.org/x/text v0.31.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
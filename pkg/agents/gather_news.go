@@ -42,7 +42,7 @@ func NewGatherNewsAgent(provider ldomain.Provider, opts ...AgentOptions) domain.
 	// Add news gathering tools
 	newsAPITool := tools.NewSearchNewsAPITool()
 	braveSearchTool := tools.NewSearchWebBraveTool()
-	fetchTool := tools.NewFetchWebPageTool()
+	fetchTool := tools.NewFetchWebPageTool(options.contentToolOptions()...)
 	metadataTool := tools.NewExtractMetadataTool()
 
 	agent.AddTool(newsAPITool)
@@ -104,12 +104,11 @@ CRITICAL INSTRUCTIONS:
 
 When given a news query:
 1. IMMEDIATELY call BOTH search_news_api AND search_web_brave tools for comprehensive coverage
-2. When calling these tools, DO NOT include the "api_key" parameter - they automatically use environment variables
-3. For search_web_brave, set result_filter to ["news"] for news-focused results
-4. WAIT for the tools to return actual articles
-5. Analyze ONLY the articles returned by the tools (do not invent articles)
-6. Use fetch_webpage if you need full article content
-7. Use extract_metadata for additional publication information
+2. For search_web_brave, set result_filter to ["news"] for news-focused results
+3. WAIT for the tools to return actual articles
+4. Analyze ONLY the articles returned by the tools (do not invent articles)
+5. Use fetch_webpage if you need full article content
+6. Use extract_metadata for additional publication information
 
 Your analysis should:
 - Focus on recent and relevant news from reputable sources
@@ -41,15 +41,17 @@ func NewResearchPapersAgent(provider ldomain.Provider, opts ...AgentOptions) dom
 
 	// Add research-specific tools
 	researchTool := tools.NewResearchPaperAPITool()
-	fetchTool := tools.NewFetchWebPageTool()
+	fetchTool := tools.NewFetchWebPageTool(options.contentToolOptions()...)
 	metadataTool := tools.NewExtractMetadataTool()
+	pdfTool := tools.NewFetchPDFTextTool(options.contentToolOptions()...)
 
 	agent.AddTool(researchTool)
 	agent.AddTool(fetchTool)
 	agent.AddTool(metadataTool)
+	agent.AddTool(pdfTool)
 
 	logger.Debug(ctx, "Created ResearchPapersAgent", "tools", []string{
-		researchTool.Name(), fetchTool.Name(), metadataTool.Name(),
+		researchTool.Name(), fetchTool.Name(), metadataTool.Name(), pdfTool.Name(),
 	})
 
 	// Set model if specified
@@ -86,9 +88,10 @@ func getResearchPapersPrompt(format OutputFormat) string {
 const coreResearchPapersPrompt = `You are a research specialist focused on finding and analyzing academic papers. You have access to research databases through your tools.
 
 Tools available to you:
-- ResearchPaperAPI: Search for academic papers across arXiv, PubMed, and CORE databases
+- ResearchPaperAPI: Search for academic papers across arXiv, PubMed, CORE, Semantic Scholar and OpenAlex, with citation counts and venues where available
 - FetchWebPage: Retrieve full content from paper URLs or research websites
 - ExtractMetadata: Extract structured metadata from research sources
+- FetchPDFText: Read the full text of a paper from its pdf_url, by page with section headings
 
 CRITICAL INSTRUCTIONS:
 1. When you receive a research query, your FIRST action MUST be to call the ResearchPaperAPI tool
@@ -105,6 +108,7 @@ When given a research query:
 3. Analyze ONLY the papers returned by the tool (do not invent papers)
 4. Use FetchWebPage if you need more details about specific papers
 5. Use ExtractMetadata for additional structured information
+6. Use FetchPDFText on a paper's pdf_url to analyze its methods and results in depth; request page ranges or follow next_page for long papers
 
 Your analysis should:
 - Focus on peer-reviewed and reputable sources from the actual search results
@@ -10,10 +10,16 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/lexlapax/go-flock/pkg/tools"
 	ldomain "github.com/lexlapax/go-llms/pkg/llm/domain"
 	"github.com/lexlapax/go-llms/pkg/llm/provider"
 )
 
+func testModelCatalog() *tools.ModelCatalog {
+	catalog, _ := tools.ParseModelCatalog([]byte(`[{"name": "gpt-4", "context_window": 8192}]`))
+	return catalog
+}
+
 func TestNewResearchPapersAgent(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -47,6 +53,15 @@ func TestNewResearchPapersAgent(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "with model catalog",
+			options: AgentOptions{
+				OutputFormat: OutputFormatMarkdown,
+				Model:        "gpt-4",
+				Models:       testModelCatalog(),
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
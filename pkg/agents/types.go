@@ -3,6 +3,8 @@
 
 package agents
 
+import "github.com/lexlapax/go-flock/pkg/tools"
+
 // OutputFormat defines the output format for agent responses
 type OutputFormat string
 
@@ -16,6 +18,10 @@ const (
 type AgentOptions struct {
 	OutputFormat OutputFormat // Output format for responses
 	Model        string       // LLM model to use (optional, uses provider default if empty)
+
+	// Models sizes the content tools return to Model's context window
+	// (optional, see tools.WithModelBudget)
+	Models *tools.ModelCatalog
 }
 
 // DefaultAgentOptions returns the default options for agents
@@ -24,3 +30,11 @@ func DefaultAgentOptions() AgentOptions {
 		OutputFormat: OutputFormatMarkdown,
 	}
 }
+
+// contentToolOptions returns the options of tools that return page content
+func (o AgentOptions) contentToolOptions() []tools.ToolOption {
+	if o.Models == nil || o.Model == "" {
+		return nil
+	}
+	return []tools.ToolOption{tools.WithModelBudget(o.Models, o.Model)}
+}
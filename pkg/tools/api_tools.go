@@ -5,14 +5,16 @@ package tools
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -25,7 +27,6 @@ import (
 // Tool Parameters
 type SearchNewsAPIParams struct {
 	Query          string `json:"query" description:"Search query for news articles"`
-	APIKey         string `json:"api_key" description:"API key for the news service"`
 	Language       string `json:"language,omitempty" description:"Language code (e.g., 'en', 'es')"`
 	SortBy         string `json:"sort_by,omitempty" description:"Sort order: 'relevancy', 'popularity', 'publishedAt'"`
 	PageSize       int    `json:"page_size,omitempty" description:"Number of results per page (default: 20)"`
@@ -41,6 +42,7 @@ type SearchNewsAPIResult struct {
 	Status       string        `json:"status"`
 	TotalResults int           `json:"total_results"`
 	Articles     []NewsArticle `json:"articles"`
+	Cache        string        `json:"cache,omitempty"` // "hit" or "miss" when a cache is configured
 	FetchedAt    string        `json:"fetched_at"`
 }
 
@@ -68,10 +70,6 @@ var SearchNewsAPIParamSchema = &sdomain.Schema{
 			Type:        "string",
 			Description: "Search query for news articles",
 		},
-		"api_key": {
-			Type:        "string",
-			Description: "API key for the news service",
-		},
 		"language": {
 			Type:        "string",
 			Description: "Language code (e.g., 'en', 'es')",
@@ -112,35 +110,36 @@ var SearchNewsAPIParamSchema = &sdomain.Schema{
 			Description: "Comma-separated list of domains to exclude",
 		},
 	},
-	Required: []string{"query", "api_key"},
+	Required: []string{"query"},
 }
 
-// NewSearchNewsAPITool creates a new news API search tool
-func NewSearchNewsAPITool() domain.Tool {
+// NewSearchNewsAPITool creates a new news API search tool. The API key is
+// resolved per call from the context, WithAPIKey or the credential provider.
+func NewSearchNewsAPITool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
 	return tools.NewTool(
 		"search_news_api",
 		"Searches for news articles using a news API service",
-		searchNewsAPIHandler,
+		cfg.searchNewsAPIHandler,
 		SearchNewsAPIParamSchema,
 	)
 }
 
-// NewsAPI.org endpoint (can be overridden for testing)
-var newsAPIURL = "https://newsapi.org/v2/everything"
+// NewsAPI.org endpoint (override with WithBaseURL)
+const defaultNewsAPIURL = "https://newsapi.org/v2/everything"
 
-func searchNewsAPIHandler(ctx context.Context, params SearchNewsAPIParams) (*SearchNewsAPIResult, error) {
+func (c *toolConfig) searchNewsAPIHandler(ctx context.Context, params SearchNewsAPIParams) (*SearchNewsAPIResult, error) {
 	// Validate required parameters
 	if strings.TrimSpace(params.Query) == "" {
 		return nil, fmt.Errorf("query parameter is required")
 	}
 
-	// Use environment variable for API key if not provided
-	apiKey := params.APIKey
+	apiKey, err := c.credential(ctx, CredentialNewsAPI, c.apiKey)
+	if err != nil {
+		return nil, err
+	}
 	if apiKey == "" {
-		apiKey = os.Getenv("NEWS_API_KEY")
-		if apiKey == "" {
-			return nil, fmt.Errorf("API key is required (pass in params or set NEWS_API_KEY environment variable)")
-		}
+		return nil, fmt.Errorf("API key is required (configure a credential provider or set NEWS_API_KEY environment variable)")
 	}
 
 	// Set defaults
@@ -179,7 +178,8 @@ func searchNewsAPIHandler(ctx context.Context, params SearchNewsAPIParams) (*Sea
 	}
 
 	// Build full URL
-	fullURL := newsAPIURL + "?" + queryParams.Encode()
+	fullURL := c.endpoint(defaultNewsAPIURL) + "?" + queryParams.Encode()
+	ctx, cache := withCacheRecorder(ctx)
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
@@ -192,9 +192,7 @@ func searchNewsAPIHandler(ctx context.Context, params SearchNewsAPIParams) (*Sea
 	req.Header.Set("Accept", "application/json")
 
 	// Make request
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := c.client(30 * time.Second)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -203,7 +201,7 @@ func searchNewsAPIHandler(ctx context.Context, params SearchNewsAPIParams) (*Sea
 	defer resp.Body.Close()
 
 	// Read response
-	body, err := io.ReadAll(resp.Body)
+	body, err := readWholeBody(resp, c.responseLimit(DefaultMaxResponseSize))
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
@@ -224,6 +222,7 @@ func searchNewsAPIHandler(ctx context.Context, params SearchNewsAPIParams) (*Sea
 		Status:       apiResponse.Status,
 		TotalResults: apiResponse.TotalResults,
 		Articles:     make([]NewsArticle, len(apiResponse.Articles)),
+		Cache:        cache.status(),
 		FetchedAt:    time.Now().UTC().Format(time.RFC3339),
 	}
 
@@ -275,7 +274,6 @@ type newsAPISource struct {
 // Tool Parameters
 type SearchWebBraveParams struct {
 	Query         string   `json:"query" description:"Search query (max 400 chars/50 words)"`
-	APIKey        string   `json:"api_key" description:"API key for Brave Search"`
 	Count         int      `json:"count,omitempty" description:"Results per page (max 20, default 10)"`
 	Offset        int      `json:"offset,omitempty" description:"Page offset for pagination (max 9)"`
 	Country       string   `json:"country,omitempty" description:"Country code (e.g., 'US', 'GB')"`
@@ -301,6 +299,7 @@ type SearchWebBraveResult struct {
 	Locations   []BraveLocation   `json:"locations,omitempty"`
 	Discussions []BraveDiscussion `json:"discussions,omitempty"`
 	FAQ         []BraveFAQ        `json:"faq,omitempty"`
+	Cache       string            `json:"cache,omitempty"` // "hit" or "miss" when a cache is configured
 	FetchedAt   string            `json:"fetched_at"`
 }
 
@@ -430,10 +429,6 @@ var SearchWebBraveParamSchema = &sdomain.Schema{
 			Description: "Search query (max 400 chars/50 words)",
 			MaxLength:   intPtr(400),
 		},
-		"api_key": {
-			Type:        "string",
-			Description: "API key for Brave Search",
-		},
 		"count": {
 			Type:        "integer",
 			Description: "Results per page (max 20, default 10)",
@@ -487,23 +482,24 @@ var SearchWebBraveParamSchema = &sdomain.Schema{
 			Description: "Custom ranking profile URL",
 		},
 	},
-	Required: []string{"query", "api_key"},
+	Required: []string{"query"},
 }
 
 // NewSearchWebBraveTool creates a new Brave web search tool
-func NewSearchWebBraveTool() domain.Tool {
+func NewSearchWebBraveTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
 	return tools.NewTool(
 		"search_web_brave",
 		"Performs web search using Brave Search API with support for multiple content types, AI summaries, and advanced filtering",
-		searchWebBraveHandler,
+		cfg.searchWebBraveHandler,
 		SearchWebBraveParamSchema,
 	)
 }
 
-// Brave Search API endpoint (can be overridden for testing)
-var braveSearchURL = "https://api.search.brave.com/res/v1/web/search"
+// Brave Search API endpoint (override with WithBaseURL)
+const defaultBraveSearchURL = "https://api.search.brave.com/res/v1/web/search"
 
-func searchWebBraveHandler(ctx context.Context, params SearchWebBraveParams) (*SearchWebBraveResult, error) {
+func (c *toolConfig) searchWebBraveHandler(ctx context.Context, params SearchWebBraveParams) (*SearchWebBraveResult, error) {
 	// Validate required parameters
 	if strings.TrimSpace(params.Query) == "" {
 		return nil, fmt.Errorf("query parameter is required")
@@ -514,13 +510,12 @@ func searchWebBraveHandler(ctx context.Context, params SearchWebBraveParams) (*S
 		return nil, fmt.Errorf("query exceeds 400 character limit")
 	}
 
-	// Use environment variable for API key if not provided
-	apiKey := params.APIKey
+	apiKey, err := c.credential(ctx, CredentialBraveSearch, c.apiKey)
+	if err != nil {
+		return nil, err
+	}
 	if apiKey == "" {
-		apiKey = os.Getenv("BRAVE_SEARCH_API_KEY")
-		if apiKey == "" {
-			return nil, fmt.Errorf("API key is required (pass in params or set BRAVE_SEARCH_API_KEY environment variable)")
-		}
+		return nil, fmt.Errorf("API key is required (configure a credential provider or set BRAVE_SEARCH_API_KEY environment variable)")
 	}
 
 	// Set defaults
@@ -562,7 +557,8 @@ func searchWebBraveHandler(ctx context.Context, params SearchWebBraveParams) (*S
 	}
 
 	// Build full URL
-	fullURL := braveSearchURL + "?" + queryParams.Encode()
+	fullURL := c.endpoint(defaultBraveSearchURL) + "?" + queryParams.Encode()
+	ctx, cache := withCacheRecorder(ctx)
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
@@ -576,9 +572,7 @@ func searchWebBraveHandler(ctx context.Context, params SearchWebBraveParams) (*S
 	req.Header.Set("User-Agent", "go-flock/1.0 BraveSearcher")
 
 	// Make request
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := c.client(30 * time.Second)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -587,7 +581,7 @@ func searchWebBraveHandler(ctx context.Context, params SearchWebBraveParams) (*S
 	defer resp.Body.Close()
 
 	// Read response
-	body, err := io.ReadAll(resp.Body)
+	body, err := readWholeBody(resp, c.responseLimit(DefaultMaxResponseSize))
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
@@ -615,6 +609,7 @@ func searchWebBraveHandler(ctx context.Context, params SearchWebBraveParams) (*S
 	result := &SearchWebBraveResult{
 		Query:     apiResponse.Query.Original,
 		Type:      apiResponse.Type,
+		Cache:     cache.status(),
 		FetchedAt: time.Now().UTC().Format(time.RFC3339),
 	}
 
@@ -981,8 +976,7 @@ type ResearchPaperAPIParams struct {
 	Categories []string `json:"categories,omitempty" description:"Subject categories (cs, physics, medicine, etc.)"`
 	OpenAccess bool     `json:"open_access,omitempty" description:"Only return open access papers"`
 	SortBy     string   `json:"sort_by,omitempty" description:"Sort order: 'relevance', 'date', 'citations'"`
-	Providers  []string `json:"providers,omitempty" description:"Specific providers to search (arxiv, pubmed, core)"`
-	CoreAPIKey string   `json:"core_api_key,omitempty" description:"CORE API key for enhanced access"`
+	Providers  []string `json:"providers,omitempty" description:"Specific providers to search (arxiv, pubmed, core, semanticscholar, openalex)"`
 }
 
 // Tool Results
@@ -1010,19 +1004,35 @@ type ResearchPaper struct {
 	PubMedID string `json:"pubmed_id,omitempty"`
 
 	// Additional metadata
-	Journal        string  `json:"journal,omitempty"`
-	RelevanceScore float64 `json:"relevance_score,omitempty"`
+	Journal        string   `json:"journal,omitempty"`
+	Venue          string   `json:"venue,omitempty"`           // Journal or conference, e.g. "NeurIPS"
+	CitationCount  *int     `json:"citation_count,omitempty"`  // Nil when the provider does not count citations
+	FieldsOfStudy  []string `json:"fields_of_study,omitempty"` // Broad fields, e.g. "Computer Science"
+	OpenAccess     bool     `json:"open_access,omitempty"`
+	RelevanceScore float64  `json:"relevance_score,omitempty"`
 }
 
 type ProviderInfo struct {
 	Name         string `json:"name"`
 	ResultCount  int    `json:"result_count"`
 	Error        string `json:"error,omitempty"`
+	Truncated    bool   `json:"truncated,omitempty"` // The response exceeded the size limit; later results are missing
 	ResponseTime int64  `json:"response_time_ms"`
+	Cache        string `json:"cache,omitempty"` // "hit" or "miss" when a cache is configured
+
+	// Requested filters the provider applied in its query, and those applied
+	// to its papers afterwards, e.g. "date", "authors"
+	NativeFilters []string `json:"native_filters,omitempty"`
+	PostFilters   []string `json:"post_filters,omitempty"`
+	FilteredOut   int      `json:"filtered_out,omitempty"` // Papers removed by the post-filters
 }
 
-// Schema definition
-var ResearchPaperAPIParamSchema = &sdomain.Schema{
+// ResearchPaperAPIParamSchema describes the parameters of research_paper_api
+// with the built-in providers. Tools list the providers of their registry.
+var ResearchPaperAPIParamSchema = researchPaperAPIParamSchema(defaultResearchProviders)
+
+// researchPaperAPIBaseSchema is completed with the providers of a registry
+var researchPaperAPIBaseSchema = &sdomain.Schema{
 	Type:        "object",
 	Description: "Parameters for searching academic research papers",
 	Properties: map[string]sdomain.Property{
@@ -1069,40 +1079,33 @@ var ResearchPaperAPIParamSchema = &sdomain.Schema{
 			Description: "Sort order: 'relevance', 'date', 'citations'",
 			Enum:        []string{"relevance", "date", "citations"},
 		},
-		"providers": {
-			Type:        "array",
-			Description: "Specific providers to search (arxiv, pubmed, core)",
-			Items: &sdomain.Property{
-				Type: "string",
-				Enum: []string{"arxiv", "pubmed", "core"},
-			},
-		},
-		"core_api_key": {
-			Type:        "string",
-			Description: "CORE API key for enhanced access",
-		},
+		// "providers" is added by researchPaperAPIParamSchema
 	},
 	Required: []string{"query"},
 }
 
-// NewResearchPaperAPITool creates a new research paper API tool
-func NewResearchPaperAPITool() domain.Tool {
+// NewResearchPaperAPITool creates a new research paper API tool. Provider
+// endpoints and keys can be set with WithProviderBaseURL and WithProviderAPIKey,
+// and the providers searched with WithResearchProviders.
+func NewResearchPaperAPITool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
+	registry := cfg.researchProviders()
 	return tools.NewTool(
 		"research_paper_api",
-		"Searches for academic papers across multiple research databases (arXiv, PubMed, CORE) in parallel",
-		researchPaperAPIHandler,
-		ResearchPaperAPIParamSchema,
+		researchPaperAPIDescription(registry),
+		cfg.researchPaperAPIHandler,
+		researchPaperAPIParamSchema(registry),
 	)
 }
 
-// API URLs - package-level for test overrides
-var (
-	arxivAPIURL   = "http://export.arxiv.org/api/query"
-	pubmedBaseURL = "https://eutils.ncbi.nlm.nih.gov/entrez/eutils/"
-	coreAPIURL    = "https://api.core.ac.uk/v3/search/works"
+// Default provider endpoints (override with WithProviderBaseURL)
+const (
+	defaultArxivAPIURL   = "http://export.arxiv.org/api/query"
+	defaultPubMedBaseURL = "https://eutils.ncbi.nlm.nih.gov/entrez/eutils/"
+	defaultCOREAPIURL    = "https://api.core.ac.uk/v3/search/works"
 )
 
-func researchPaperAPIHandler(ctx context.Context, params ResearchPaperAPIParams) (*ResearchPaperAPIResult, error) {
+func (c *toolConfig) researchPaperAPIHandler(ctx context.Context, params ResearchPaperAPIParams) (*ResearchPaperAPIResult, error) {
 	// Validate required parameters
 	if strings.TrimSpace(params.Query) == "" {
 		return nil, fmt.Errorf("query parameter is required")
@@ -1115,48 +1118,75 @@ func researchPaperAPIHandler(ctx context.Context, params ResearchPaperAPIParams)
 	if params.SortBy == "" {
 		params.SortBy = "relevance"
 	}
+	if _, _, err := dateRange(params); err != nil {
+		return nil, err
+	}
 
 	// Select providers
-	providers := selectProviders(params)
+	registry := c.researchProviders()
+	providers := selectProviders(registry, params)
 
 	// Channel for collecting results
 	type providerResult struct {
-		provider string
-		papers   []ResearchPaper
-		err      error
-		duration time.Duration
+		index       int
+		provider    string
+		papers      []ResearchPaper
+		err         error
+		duration    time.Duration
+		cache       string
+		native      []string
+		post        []string
+		filteredOut int
 	}
 
 	resultChan := make(chan providerResult, len(providers))
 
 	// Search all providers in parallel
-	for _, provider := range providers {
-		go func(p string) {
+	for i, provider := range providers {
+		go func(index int, p string) {
 			start := time.Now()
 			var papers []ResearchPaper
 			var err error
+			var native, post []string
+			ctx, cache := withCacheRecorder(ctx)
+
+			if provider, ok := registry.Provider(p); ok {
+				env := &ProviderEnv{cfg: c, provider: provider.Name()}
+				papers, err = provider.Search(ctx, env, params)
+				marked := env.nativeFilters()
+				for _, f := range requestedFilters(params) {
+					if slices.Contains(marked, f) {
+						native = append(native, f)
+					}
+				}
+			} else {
+				err = fmt.Errorf("unknown research provider %q", p)
+			}
 
-			switch p {
-			case "arxiv":
-				papers, err = searchArxiv(ctx, params)
-			case "pubmed":
-				papers, err = searchPubMed(ctx, params)
-			case "core":
-				papers, err = searchCORE(ctx, params)
+			found := len(papers)
+			if len(papers) > 0 {
+				papers, post = filterPapers(papers, params, native)
+				rankRelevance(papers)
 			}
 
 			resultChan <- providerResult{
-				provider: p,
-				papers:   papers,
-				err:      err,
-				duration: time.Since(start),
+				index:       index,
+				provider:    p,
+				papers:      papers,
+				err:         err,
+				duration:    time.Since(start),
+				cache:       cache.status(),
+				native:      native,
+				post:        post,
+				filteredOut: found - len(papers),
 			}
-		}(provider)
+		}(i, provider)
 	}
 
-	// Collect results with timeout
+	// Collect results with timeout, keeping papers in provider order so
+	// duplicates and ties resolve the same way whichever provider answers first
 	timeout := time.After(30 * time.Second)
-	var allPapers []ResearchPaper
+	papersByProvider := make([][]ResearchPaper, len(providers))
 	providerInfos := make([]ProviderInfo, 0, len(providers))
 
 	for i := 0; i < len(providers); i++ {
@@ -1165,13 +1195,26 @@ func researchPaperAPIHandler(ctx context.Context, params ResearchPaperAPIParams)
 			info := ProviderInfo{
 				Name:         result.provider,
 				ResponseTime: result.duration.Milliseconds(),
+				Cache:        result.cache,
+			}
+			if result.err == nil || len(result.papers) > 0 {
+				info.NativeFilters = result.native
+				info.PostFilters = result.post
+				info.FilteredOut = result.filteredOut
 			}
 
-			if result.err != nil {
+			var tooLarge *ResponseTooLargeError
+			switch {
+			case errors.As(result.err, &tooLarge) && len(result.papers) > 0:
+				// Keep what was read before the size limit
+				info.Truncated = true
+				info.ResultCount = len(result.papers)
+				papersByProvider[result.index] = result.papers
+			case result.err != nil:
 				info.Error = result.err.Error()
-			} else {
+			default:
 				info.ResultCount = len(result.papers)
-				allPapers = append(allPapers, result.papers...)
+				papersByProvider[result.index] = result.papers
 			}
 
 			providerInfos = append(providerInfos, info)
@@ -1185,7 +1228,7 @@ func researchPaperAPIHandler(ctx context.Context, params ResearchPaperAPIParams)
 	}
 
 	// Deduplicate papers
-	deduplicatedPapers := deduplicatePapers(allPapers)
+	deduplicatedPapers := deduplicatePapers(slices.Concat(papersByProvider...))
 
 	// Sort papers
 	sortPapers(deduplicatedPapers, params.SortBy)
@@ -1202,95 +1245,232 @@ func researchPaperAPIHandler(ctx context.Context, params ResearchPaperAPIParams)
 	return result, nil
 }
 
-// selectProviders chooses which providers to search based on parameters
-func selectProviders(params ResearchPaperAPIParams) []string {
-	if len(params.Providers) > 0 {
-		return params.Providers
-	}
-
-	// Default to all providers
-	providers := []string{"arxiv", "pubmed", "core"}
+// deduplicatePapers removes duplicate papers based on DOI and title
+// similarity. Details only a duplicate carries, such as the citation count
+// of a paper also found on arXiv, are merged into the paper kept.
+func deduplicatePapers(papers []ResearchPaper) []ResearchPaper {
+	seen := make(map[string]int)
+	unique := make([]ResearchPaper, 0, len(papers))
 
-	// If categories specified, optimize provider selection
-	if len(params.Categories) > 0 {
-		selectedProviders := make(map[string]bool)
+	for _, paper := range papers {
+		doiKey := strings.ToLower(paper.DOI)
+		titleKey := strings.ToLower(strings.TrimSpace(paper.Title))
 
-		for _, cat := range params.Categories {
-			switch strings.ToLower(cat) {
-			case "cs", "math", "physics", "astro-ph", "cond-mat", "q-bio", "q-fin", "stat":
-				selectedProviders["arxiv"] = true
-			case "medicine", "biology", "health", "clinical":
-				selectedProviders["pubmed"] = true
+		// Check DOI first, then similar titles (simple approach)
+		idx, ok := seen[doiKey]
+		if !ok || doiKey == "" {
+			idx, ok = seen[titleKey]
+		}
+		if ok {
+			mergePaper(&unique[idx], paper)
+			if doiKey != "" {
+				seen[doiKey] = idx
 			}
-			// CORE is general purpose, always include if any category matches
-			selectedProviders["core"] = true
+			continue
 		}
 
-		if len(selectedProviders) > 0 {
-			providers = make([]string, 0, len(selectedProviders))
-			for p := range selectedProviders {
-				providers = append(providers, p)
-			}
+		if doiKey != "" {
+			seen[doiKey] = len(unique)
 		}
+		seen[titleKey] = len(unique)
+		unique = append(unique, paper)
 	}
 
-	return providers
+	return unique
 }
 
-// deduplicatePapers removes duplicate papers based on DOI and title similarity
-func deduplicatePapers(papers []ResearchPaper) []ResearchPaper {
-	seen := make(map[string]bool)
-	unique := make([]ResearchPaper, 0, len(papers))
+// mergePaper fills in the details of paper that dup has and it lacks
+func mergePaper(paper *ResearchPaper, dup ResearchPaper) {
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&paper.Abstract, dup.Abstract)
+	fill(&paper.PublishedDate, dup.PublishedDate)
+	fill(&paper.PDFURL, dup.PDFURL)
+	fill(&paper.DOI, dup.DOI)
+	fill(&paper.ArxivID, dup.ArxivID)
+	fill(&paper.PubMedID, dup.PubMedID)
+	fill(&paper.Journal, dup.Journal)
+	fill(&paper.Venue, dup.Venue)
+	if paper.CitationCount == nil {
+		paper.CitationCount = dup.CitationCount
+	}
+	if len(paper.FieldsOfStudy) == 0 {
+		paper.FieldsOfStudy = dup.FieldsOfStudy
+	}
+	paper.OpenAccess = paper.OpenAccess || dup.OpenAccess
+	paper.RelevanceScore += dup.RelevanceScore // Found by several providers
+}
+
+// relevanceRankOffset damps the difference between the top ranks of a
+// provider, as in reciprocal rank fusion
+const relevanceRankOffset = 60
+
+// rankRelevance replaces the relevance scores of a provider's papers, which
+// are on scales of the provider's own, with scores from their rank: 1 for
+// the best match, decreasing slowly down the list. Summed over the providers
+// finding a paper, they make a cross-provider relevance score.
+func rankRelevance(papers []ResearchPaper) {
+	// Providers list papers best first; one reporting scores out of order
+	// is ranked by its scores
+	slices.SortStableFunc(papers, func(a, b ResearchPaper) int {
+		return cmp.Compare(b.RelevanceScore, a.RelevanceScore)
+	})
+	for rank := range papers {
+		papers[rank].RelevanceScore = float64(relevanceRankOffset) / float64(relevanceRankOffset+rank)
+	}
+}
+
+// sortPapers sorts papers by relevance, newest first ("date") or most cited
+// first ("citations"). Papers without a known date or citation count go
+// last, and ties keep their order by relevance.
+func sortPapers(papers []ResearchPaper, sortBy string) {
+	slices.SortStableFunc(papers, func(a, b ResearchPaper) int {
+		return cmp.Compare(b.RelevanceScore, a.RelevanceScore)
+	})
 
-	for _, paper := range papers {
-		// Check DOI first
-		if paper.DOI != "" {
-			if seen[paper.DOI] {
-				continue
+	switch sortBy {
+	case "date":
+		sortByKnown(papers, func(p ResearchPaper) (time.Time, bool) {
+			from, _, ok := parsePublishedDate(p.PublishedDate)
+			return from, ok
+		}, func(a, b time.Time) int { return b.Compare(a) })
+	case "citations":
+		sortByKnown(papers, func(p ResearchPaper) (int, bool) {
+			if p.CitationCount == nil {
+				return 0, false
 			}
-			seen[paper.DOI] = true
+			return *p.CitationCount, true
+		}, func(a, b int) int { return cmp.Compare(b, a) })
+	}
+}
+
+// sortByKnown stably sorts papers by a key computed once per paper, with
+// papers whose key is unknown last
+func sortByKnown[K any](papers []ResearchPaper, key func(ResearchPaper) (K, bool), compare func(a, b K) int) {
+	type keyed struct {
+		paper ResearchPaper
+		key   K
+		known bool
+	}
+	items := make([]keyed, len(papers))
+	for i, p := range papers {
+		k, ok := key(p)
+		items[i] = keyed{p, k, ok}
+	}
+	slices.SortStableFunc(items, func(a, b keyed) int {
+		switch {
+		case a.known && b.known:
+			return compare(a.key, b.key)
+		case a.known:
+			return -1
+		case b.known:
+			return 1
 		}
+		return 0
+	})
+	for i, item := range items {
+		papers[i] = item.paper
+	}
+}
 
-		// Check for similar titles (simple approach)
-		titleKey := strings.ToLower(strings.TrimSpace(paper.Title))
-		if seen[titleKey] {
-			continue
-		}
-		seen[titleKey] = true
+// arxivProvider searches arXiv preprints
+type arxivProvider struct{}
 
-		unique = append(unique, paper)
+func (arxivProvider) Name() string { return "arxiv" }
+
+func (arxivProvider) Capabilities() ResearchCapabilities {
+	return ResearchCapabilities{
+		Source:           "arXiv",
+		Categories:       []string{"cs", "math", "physics", "astro-ph", "cond-mat", "q-bio", "q-fin", "stat"},
+		DateFilter:       true,
+		AuthorFilter:     true,
+		CategoryFilter:   true,
+		OpenAccessFilter: true, // Every arXiv paper is open access
 	}
+}
 
-	return unique
+// arxivArchives are the arXiv archives divided into subject classes such
+// as cs.AI, searched with a wildcard
+var arxivArchives = []string{"cs", "math", "physics", "astro-ph", "cond-mat", "q-bio", "q-fin", "stat", "eess", "econ", "nlin"}
+
+// arxivStandaloneArchives are the arXiv archives without subject classes
+var arxivStandaloneArchives = []string{"gr-qc", "hep-ex", "hep-lat", "hep-ph", "hep-th", "math-ph", "nucl-ex", "nucl-th", "quant-ph"}
+
+// arxivCategory returns the arXiv category query for a category, or "" for
+// categories arXiv does not have, e.g. "medicine"
+func arxivCategory(category string) string {
+	switch lower := strings.ToLower(category); {
+	case slices.Contains(arxivArchives, lower):
+		return "cat:" + lower + ".*"
+	case slices.Contains(arxivStandaloneArchives, lower):
+		return "cat:" + lower
+	case strings.Contains(category, ".") && slices.Contains(arxivArchives, lower[:strings.Index(lower, ".")]):
+		return "cat:" + category // A subject class, e.g. "cs.AI"
+	}
+	return ""
 }
 
-// sortPapers sorts papers based on the specified criteria
-func sortPapers(papers []ResearchPaper, sortBy string) {
-	// Simple sorting implementation
-	// In production, would implement more sophisticated sorting
-	switch sortBy {
-	case "date":
-		// Sort by published date (newest first)
-		// Implementation would parse dates and sort
-	case "citations":
-		// Sort by citation count
-		// Implementation would sort by CitationCount field
-	default:
-		// Relevance sorting (keep original order from providers)
+// arxivSearchQuery adds the filters of params to the query in arXiv's
+// search syntax
+func arxivSearchQuery(env *ProviderEnv, params ResearchPaperAPIParams) string {
+	var filters []string
+
+	if start, end, _ := dateRange(params); !start.IsZero() || !end.IsZero() {
+		from, until := "199101010000", time.Now().UTC().Format("200601021504")
+		if !start.IsZero() {
+			from = start.Format("20060102") + "0000"
+		}
+		if !end.IsZero() {
+			until = end.Format("20060102") + "2359"
+		}
+		filters = append(filters, fmt.Sprintf("submittedDate:[%s TO %s]", from, until))
+		env.MarkNative(ResearchFilterDate)
 	}
+
+	if len(params.Authors) > 0 {
+		authors := make([]string, len(params.Authors))
+		for i, author := range params.Authors {
+			authors[i] = fmt.Sprintf("au:%q", strings.TrimSpace(author))
+		}
+		filters = append(filters, "("+strings.Join(authors, " OR ")+")")
+		env.MarkNative(ResearchFilterAuthors)
+	}
+
+	var categories []string
+	for _, category := range params.Categories {
+		if cat := arxivCategory(category); cat != "" {
+			categories = append(categories, cat)
+		}
+	}
+	if len(categories) > 0 {
+		filters = append(filters, "("+strings.Join(categories, " OR ")+")")
+		env.MarkNative(ResearchFilterCategories)
+	}
+
+	if params.OpenAccess {
+		env.MarkNative(ResearchFilterOpenAccess)
+	}
+
+	if len(filters) == 0 {
+		return params.Query
+	}
+	return "(" + params.Query + ") AND " + strings.Join(filters, " AND ")
 }
 
-func searchArxiv(ctx context.Context, params ResearchPaperAPIParams) ([]ResearchPaper, error) {
+func (arxivProvider) Search(ctx context.Context, env *ProviderEnv, params ResearchPaperAPIParams) ([]ResearchPaper, error) {
 	// Build query parameters
 	query := url.Values{}
-	query.Set("search_query", params.Query)
+	query.Set("search_query", arxivSearchQuery(env, params))
 	query.Set("start", "0")
 	query.Set("max_results", fmt.Sprintf("%d", params.MaxResults))
 	query.Set("sortBy", "relevance")
 	query.Set("sortOrder", "descending")
 
 	// Build URL
-	apiURL := fmt.Sprintf("%s?%s", arxivAPIURL, query.Encode())
+	apiURL := fmt.Sprintf("%s?%s", env.Endpoint(defaultArxivAPIURL), query.Encode())
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
@@ -1299,7 +1479,7 @@ func searchArxiv(ctx context.Context, params ResearchPaperAPIParams) ([]Research
 	}
 
 	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := env.Client(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("executing request: %w", err)
@@ -1307,7 +1487,7 @@ func searchArxiv(ctx context.Context, params ResearchPaperAPIParams) ([]Research
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -1330,9 +1510,23 @@ func searchArxiv(ctx context.Context, params ResearchPaperAPIParams) ([]Research
 		Entries []ArxivEntry `xml:"entry"`
 	}
 
+	// Stream entries so an oversized response still yields those before the cut
 	var feed ArxivFeed
-	decoder := xml.NewDecoder(resp.Body)
-	if err := decoder.Decode(&feed); err != nil {
+	limit := env.ResponseLimit(DefaultMaxResponseSize)
+	body := newCappedReader(resp.Body, limit)
+	decoder, err := newXMLDecoder(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	err = streamXMLElements(decoder, "entry", func(start *xml.StartElement) error {
+		var entry ArxivEntry
+		if err := decoder.DecodeElement(&entry, start); err != nil {
+			return err
+		}
+		feed.Entries = append(feed.Entries, entry)
+		return nil
+	})
+	if err != nil && !body.truncated {
 		return nil, fmt.Errorf("parsing response: %w", err)
 	}
 
@@ -1369,28 +1563,104 @@ func searchArxiv(ctx context.Context, params ResearchPaperAPIParams) ([]Research
 			URL:            entry.ID,
 			PDFURL:         pdfURL,
 			ArxivID:        arxivID,
+			OpenAccess:     true,
 			RelevanceScore: 1.0, // arXiv doesn't provide relevance scores
 		})
 	}
 
+	if body.truncated {
+		// The papers read before the cut, along with why the list is short
+		return papers, &ResponseTooLargeError{URL: resp.Request.URL.String(), Limit: limit}
+	}
 	return papers, nil
 }
 
-func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]ResearchPaper, error) {
+// pubMedProvider searches biomedical literature in PubMed
+type pubMedProvider struct{}
+
+func (pubMedProvider) Name() string { return "pubmed" }
+
+func (pubMedProvider) Capabilities() ResearchCapabilities {
+	return ResearchCapabilities{
+		Source:           "PubMed",
+		Categories:       []string{"medicine", "biology", "health", "clinical"},
+		DateFilter:       true,
+		AuthorFilter:     true,
+		OpenAccessFilter: true,
+	}
+}
+
+// pubMedAuthor returns an author name in PubMed's "Surname Initials" form,
+// e.g. "Hinton GE" for "Geoffrey E. Hinton"
+func pubMedAuthor(name string) string {
+	surname, given := authorName(name)
+	var initials strings.Builder
+	for _, g := range given {
+		initials.WriteString(initial(g))
+	}
+	if initials.Len() == 0 {
+		return surname
+	}
+	return surname + " " + initials.String()
+}
+
+// pubMedSearchTerm adds the filters of params to the query in PubMed's
+// search syntax
+func pubMedSearchTerm(env *ProviderEnv, params ResearchPaperAPIParams) string {
+	terms := []string{"(" + params.Query + ")"}
+
+	if start, end, _ := dateRange(params); !start.IsZero() || !end.IsZero() {
+		from, until := "1800", "3000"
+		if !start.IsZero() {
+			from = start.Format("2006/01/02")
+		}
+		if !end.IsZero() {
+			until = end.Format("2006/01/02")
+		}
+		terms = append(terms, fmt.Sprintf("(%q[dp] : %q[dp])", from, until))
+		env.MarkNative(ResearchFilterDate)
+	}
+
+	if len(params.Authors) > 0 {
+		authors := make([]string, len(params.Authors))
+		for i, author := range params.Authors {
+			authors[i] = fmt.Sprintf("%q[au]", pubMedAuthor(author))
+		}
+		terms = append(terms, "("+strings.Join(authors, " OR ")+")")
+		env.MarkNative(ResearchFilterAuthors)
+	}
+
+	if params.OpenAccess {
+		terms = append(terms, "free full text[sb]")
+		env.MarkNative(ResearchFilterOpenAccess)
+	}
+
+	if len(terms) == 1 {
+		return params.Query
+	}
+	return strings.Join(terms, " AND ")
+}
+
+func (pubMedProvider) Search(ctx context.Context, env *ProviderEnv, params ResearchPaperAPIParams) ([]ResearchPaper, error) {
 	// PubMed requires two API calls: esearch to get IDs, then efetch to get details
 
 	// First, search for IDs
+	pubmedBaseURL := env.Endpoint(defaultPubMedBaseURL)
 	searchURL := pubmedBaseURL + "esearch.fcgi"
 	searchQuery := url.Values{}
 	searchQuery.Set("db", "pubmed")
-	searchQuery.Set("term", params.Query)
+	searchQuery.Set("term", pubMedSearchTerm(env, params))
 	searchQuery.Set("retmax", fmt.Sprintf("%d", params.MaxResults))
 	searchQuery.Set("retmode", "json")
 	searchQuery.Set("sort", "relevance")
 
 	// Add API key if available
-	if apiKey := os.Getenv("PUBMED_API_KEY"); apiKey != "" {
-		searchQuery.Set("api_key", apiKey)
+	pubmedAPIKey, err := env.APIKey(ctx, CredentialPubMed)
+	if err != nil {
+		return nil, err
+	}
+	if pubmedAPIKey != "" {
+		searchQuery.Set("api_key", pubmedAPIKey)
 	}
 
 	fullSearchURL := fmt.Sprintf("%s?%s", searchURL, searchQuery.Encode())
@@ -1402,7 +1672,7 @@ func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]Researc
 	}
 
 	// Execute search request
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := env.Client(30 * time.Second)
 	searchResp, err := client.Do(searchReq)
 	if err != nil {
 		return nil, fmt.Errorf("executing search request: %w", err)
@@ -1410,7 +1680,7 @@ func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]Researc
 	defer searchResp.Body.Close()
 
 	if searchResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(searchResp.Body)
+		body, _ := io.ReadAll(io.LimitReader(searchResp.Body, maxErrorBodySize))
 		return nil, fmt.Errorf("search API returned status %d: %s", searchResp.StatusCode, string(body))
 	}
 
@@ -1421,7 +1691,11 @@ func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]Researc
 		} `json:"esearchresult"`
 	}
 
-	if err := json.NewDecoder(searchResp.Body).Decode(&searchResult); err != nil {
+	searchBody, err := readWholeBody(searchResp, env.ResponseLimit(DefaultMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading search response: %w", err)
+	}
+	if err := json.Unmarshal(searchBody, &searchResult); err != nil {
 		return nil, fmt.Errorf("parsing search response: %w", err)
 	}
 
@@ -1435,8 +1709,8 @@ func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]Researc
 	fetchQuery.Set("db", "pubmed")
 	fetchQuery.Set("id", strings.Join(searchResult.ESearchResult.IDList, ","))
 	fetchQuery.Set("retmode", "xml")
-	if apiKey := os.Getenv("PUBMED_API_KEY"); apiKey != "" {
-		fetchQuery.Set("api_key", apiKey)
+	if pubmedAPIKey != "" {
+		fetchQuery.Set("api_key", pubmedAPIKey)
 	}
 
 	fullFetchURL := fmt.Sprintf("%s?%s", fetchURL, fetchQuery.Encode())
@@ -1455,7 +1729,7 @@ func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]Researc
 	defer fetchResp.Body.Close()
 
 	if fetchResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(fetchResp.Body)
+		body, _ := io.ReadAll(io.LimitReader(fetchResp.Body, maxErrorBodySize))
 		return nil, fmt.Errorf("fetch API returned status %d: %s", fetchResp.StatusCode, string(body))
 	}
 
@@ -1478,7 +1752,15 @@ func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]Researc
 					} `xml:"Author"`
 				} `xml:"AuthorList"`
 				Journal struct {
-					Title string `xml:"Title"`
+					Title        string `xml:"Title"`
+					JournalIssue struct {
+						PubDate struct {
+							Year        string `xml:"Year"`
+							Month       string `xml:"Month"`
+							Day         string `xml:"Day"`
+							MedlineDate string `xml:"MedlineDate"` // e.g. "2019 Jan-Feb"
+						} `xml:"PubDate"`
+					} `xml:"JournalIssue"`
 				} `xml:"Journal"`
 				ArticleDate struct {
 					Year  string `xml:"Year"`
@@ -1501,9 +1783,23 @@ func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]Researc
 		Articles []PubMedArticle `xml:"PubmedArticle"`
 	}
 
+	// efetch returns full records, so stream them one article at a time
 	var articleSet PubMedArticleSet
-	decoder := xml.NewDecoder(fetchResp.Body)
-	if err := decoder.Decode(&articleSet); err != nil {
+	limit := env.ResponseLimit(DefaultMaxResponseSize)
+	body := newCappedReader(fetchResp.Body, limit)
+	decoder, err := newXMLDecoder(body, fetchResp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing fetch response: %w", err)
+	}
+	err = streamXMLElements(decoder, "PubmedArticle", func(start *xml.StartElement) error {
+		var article PubMedArticle
+		if err := decoder.DecodeElement(&article, start); err != nil {
+			return err
+		}
+		articleSet.Articles = append(articleSet.Articles, article)
+		return nil
+	})
+	if err != nil && !body.truncated {
 		return nil, fmt.Errorf("parsing fetch response: %w", err)
 	}
 
@@ -1546,6 +1842,19 @@ func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]Researc
 				day = "01"
 			}
 			pubDate = fmt.Sprintf("%s-%02s-%02s", year, month, day)
+		} else if issue := article.MedlineCitation.Article.Journal.JournalIssue.PubDate; issue.Year != "" {
+			// Print-only articles carry the issue date, often without a day
+			pubDate = issue.Year
+			if month, err := time.Parse("Jan", issue.Month); err == nil {
+				pubDate += month.Format("-01")
+			} else if len(issue.Month) == 2 {
+				pubDate += "-" + issue.Month
+			}
+			if issue.Day != "" && pubDate != issue.Year {
+				pubDate += fmt.Sprintf("-%02s", issue.Day)
+			}
+		} else {
+			pubDate = issue.MedlineDate
 		}
 
 		// Build URL
@@ -1561,23 +1870,70 @@ func searchPubMed(ctx context.Context, params ResearchPaperAPIParams) ([]Researc
 			DOI:            doi,
 			PubMedID:       pmid,
 			Journal:        article.MedlineCitation.Article.Journal.Title,
+			OpenAccess:     params.OpenAccess,         // Only free full text was searched
 			RelevanceScore: 1.0 - (float64(i) * 0.01), // Approximate relevance based on order
 		})
 	}
 
+	if body.truncated {
+		// The papers read before the cut, along with why the list is short
+		return papers, &ResponseTooLargeError{URL: fetchResp.Request.URL.String(), Limit: limit}
+	}
 	return papers, nil
 }
 
-func searchCORE(ctx context.Context, params ResearchPaperAPIParams) ([]ResearchPaper, error) {
+// coreProvider searches open access research aggregated by CORE. It covers
+// all subjects.
+type coreProvider struct{}
+
+func (coreProvider) Name() string { return "core" }
+
+func (coreProvider) Capabilities() ResearchCapabilities {
+	return ResearchCapabilities{Source: "CORE", AuthorFilter: true}
+}
+
+// coreSearchQuery adds the filters of params to the query in CORE's search
+// syntax. CORE filters dates by year, so papers are still post-filtered on
+// exact dates.
+func coreSearchQuery(env *ProviderEnv, params ResearchPaperAPIParams) string {
+	terms := []string{"(" + params.Query + ")"}
+
+	start, end, _ := dateRange(params)
+	if !start.IsZero() {
+		terms = append(terms, fmt.Sprintf("yearPublished>=%d", start.Year()))
+	}
+	if !end.IsZero() {
+		terms = append(terms, fmt.Sprintf("yearPublished<=%d", end.Year()))
+	}
+
+	if len(params.Authors) > 0 {
+		authors := make([]string, len(params.Authors))
+		for i, author := range params.Authors {
+			authors[i] = fmt.Sprintf("authors:%q", strings.TrimSpace(author))
+		}
+		terms = append(terms, "("+strings.Join(authors, " OR ")+")")
+		env.MarkNative(ResearchFilterAuthors)
+	}
+
+	if len(terms) == 1 {
+		return params.Query
+	}
+	return strings.Join(terms, " AND ")
+}
+
+func (coreProvider) Search(ctx context.Context, env *ProviderEnv, params ResearchPaperAPIParams) ([]ResearchPaper, error) {
 	// Get API key
-	apiKey := os.Getenv("CORE_API_KEY")
+	apiKey, err := env.APIKey(ctx, CredentialCORE)
+	if err != nil {
+		return nil, err
+	}
 	if apiKey == "" {
 		return nil, fmt.Errorf("CORE_API_KEY environment variable not set")
 	}
 
 	// Build request body
 	reqBody := map[string]interface{}{
-		"q":     params.Query,
+		"q":     coreSearchQuery(env, params),
 		"limit": params.MaxResults,
 	}
 
@@ -1587,7 +1943,7 @@ func searchCORE(ctx context.Context, params ResearchPaperAPIParams) ([]ResearchP
 	}
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", coreAPIURL, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", env.Endpoint(defaultCOREAPIURL), bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -1596,7 +1952,7 @@ func searchCORE(ctx context.Context, params ResearchPaperAPIParams) ([]ResearchP
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := env.Client(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("executing request: %w", err)
@@ -1604,7 +1960,7 @@ func searchCORE(ctx context.Context, params ResearchPaperAPIParams) ([]ResearchP
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -1621,12 +1977,17 @@ func searchCORE(ctx context.Context, params ResearchPaperAPIParams) ([]ResearchP
 				URL  string `json:"url"`
 				Type string `json:"type"`
 			} `json:"links"`
-			Journals []string `json:"journals"`
-			Score    float64  `json:"score"`
+			Journals      []string `json:"journals"`
+			CitationCount *int     `json:"citationCount"`
+			Score         float64  `json:"score"`
 		} `json:"results"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&coreResp); err != nil {
+	body, err := readWholeBody(resp, env.ResponseLimit(DefaultMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if err := json.Unmarshal(body, &coreResp); err != nil {
 		return nil, fmt.Errorf("parsing response: %w", err)
 	}
 
@@ -1684,6 +2045,7 @@ func searchCORE(ctx context.Context, params ResearchPaperAPIParams) ([]ResearchP
 			PDFURL:         pdfURL,
 			DOI:            result.DOI,
 			Journal:        journal,
+			CitationCount:  result.CitationCount,
 			RelevanceScore: result.Score,
 		})
 	}
@@ -8,12 +8,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 )
 
 func TestResearchPaperAPIHandler_SuccessWithProperMocks(t *testing.T) {
+	t.Parallel()
+
 	// Set up mock servers with correct response structures
 	arxivServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Mock arXiv response (Atom feed)
@@ -117,8 +120,9 @@ func TestResearchPaperAPIHandler_SuccessWithProperMocks(t *testing.T) {
 						{"url": "https://core.ac.uk/display/123456", "type": "display"},
 						{"url": "https://core.ac.uk/download/pdf/123456.pdf", "type": "pdf"},
 					},
-					"journals": []string{"Computer Science Review"},
-					"score":    0.95,
+					"journals":      []string{"Computer Science Review"},
+					"citationCount": 42,
+					"score":         0.95,
 				},
 			},
 		}
@@ -130,24 +134,24 @@ func TestResearchPaperAPIHandler_SuccessWithProperMocks(t *testing.T) {
 	}))
 	defer coreServer.Close()
 
-	// Override API URLs for testing
-	arxivAPIURL = arxivServer.URL + "/api/query"
-	pubmedBaseURL = pubmedServer.URL + "/entrez/eutils/"
-	coreAPIURL = coreServer.URL + "/v3/search/works"
-
-	// Set test CORE API key
-	os.Setenv("CORE_API_KEY", "test-core-key")
-	defer os.Unsetenv("CORE_API_KEY")
+	// Point providers at the mock servers
+	cfg := newToolConfig(
+		WithProviderBaseURL("arxiv", arxivServer.URL+"/api/query"),
+		WithProviderBaseURL("pubmed", pubmedServer.URL+"/entrez/eutils/"),
+		WithProviderBaseURL("core", coreServer.URL+"/v3/search/works"),
+		WithProviderAPIKey("core", "test-core-key"),
+	)
 
 	// Create test parameters
 	params := ResearchPaperAPIParams{
 		Query:      "machine learning",
 		MaxResults: 10,
+		Providers:  []string{"arxiv", "pubmed", "core"},
 	}
 
 	// Execute handler
 	ctx := context.Background()
-	result, err := researchPaperAPIHandler(ctx, params)
+	result, err := cfg.researchPaperAPIHandler(ctx, params)
 
 	// Assertions
 	if err != nil {
@@ -237,11 +241,16 @@ func TestResearchPaperAPIHandler_SuccessWithProperMocks(t *testing.T) {
 			if paper.RelevanceScore <= 0.0 {
 				t.Error("CORE paper has invalid relevance score")
 			}
+			if paper.CitationCount == nil || *paper.CitationCount != 42 {
+				t.Errorf("Expected CORE citation count 42, got %v", paper.CitationCount)
+			}
 		}
 	}
 }
 
 func TestResearchPaperAPIHandler_PartialFailure(t *testing.T) {
+	t.Parallel()
+
 	// Set up only arXiv server (others will fail)
 	arxivServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		arxivResponse := `<?xml version="1.0" encoding="UTF-8"?>
@@ -258,10 +267,14 @@ func TestResearchPaperAPIHandler_PartialFailure(t *testing.T) {
 	}))
 	defer arxivServer.Close()
 
-	// Override only arXiv URL
-	arxivAPIURL = arxivServer.URL + "/api/query"
-	pubmedBaseURL = "http://invalid-url/"
-	coreAPIURL = "http://invalid-url/"
+	// Only arXiv points at a working server
+	cfg := newToolConfig(
+		WithProviderBaseURL("arxiv", arxivServer.URL+"/api/query"),
+		WithProviderBaseURL("pubmed", "http://invalid-url/"),
+		WithProviderBaseURL("core", "http://invalid-url/"),
+		WithProviderBaseURL("semanticscholar", "http://invalid-url/"),
+		WithProviderBaseURL("openalex", "http://invalid-url/"),
+	)
 
 	params := ResearchPaperAPIParams{
 		Query:      "test",
@@ -269,7 +282,7 @@ func TestResearchPaperAPIHandler_PartialFailure(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	result, err := researchPaperAPIHandler(ctx, params)
+	result, err := cfg.researchPaperAPIHandler(ctx, params)
 
 	// Should succeed with partial results
 	if err != nil {
@@ -299,7 +312,7 @@ func TestResearchPaperAPIHandler_PartialFailure(t *testing.T) {
 
 func TestResearchPaperAPIHandler_NoAPIKey(t *testing.T) {
 	// Ensure no CORE API key is set
-	os.Unsetenv("CORE_API_KEY")
+	t.Setenv("CORE_API_KEY", "")
 
 	// Set up mock servers
 	arxivServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -307,7 +320,7 @@ func TestResearchPaperAPIHandler_NoAPIKey(t *testing.T) {
 	}))
 	defer arxivServer.Close()
 
-	arxivAPIURL = arxivServer.URL
+	cfg := newToolConfig(WithProviderBaseURL("arxiv", arxivServer.URL))
 
 	params := ResearchPaperAPIParams{
 		Query:      "test",
@@ -316,7 +329,7 @@ func TestResearchPaperAPIHandler_NoAPIKey(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	result, err := researchPaperAPIHandler(ctx, params)
+	result, err := cfg.researchPaperAPIHandler(ctx, params)
 
 	// Should not fail completely, but CORE should have an error
 	if err != nil {
@@ -341,3 +354,133 @@ func TestResearchPaperAPIHandler_NoAPIKey(t *testing.T) {
 		t.Error("Expected to find CORE provider info")
 	}
 }
+
+func TestSortPapers(t *testing.T) {
+	t.Parallel()
+
+	papers := []ResearchPaper{
+		{Title: "a", PublishedDate: "2019 Jan-Feb", CitationCount: intPtr(5), RelevanceScore: 0.9},
+		{Title: "b", PublishedDate: "2021", RelevanceScore: 1},
+		{Title: "c", PublishedDate: "March 3, 2021", CitationCount: intPtr(5), RelevanceScore: 0.95},
+		{Title: "d", PublishedDate: "n.d.", CitationCount: intPtr(100), RelevanceScore: 0.5},
+		{Title: "e", PublishedDate: "2021-03-03T10:00:00Z", CitationCount: intPtr(0), RelevanceScore: 0.99},
+	}
+	tests := []struct {
+		sortBy string
+		want   string
+	}{
+		{"relevance", "becad"},
+		{"date", "ecbad"},      // Same day keeps relevance order, undated last
+		{"citations", "dcaeb"}, // Equal counts keep relevance order, unknown counts last
+		{"unknown", "becad"},   // Anything else is relevance
+	}
+	for _, tt := range tests {
+		sorted := slices.Clone(papers)
+		sortPapers(sorted, tt.sortBy)
+		var got strings.Builder
+		for _, p := range sorted {
+			got.WriteString(p.Title)
+		}
+		if got.String() != tt.want {
+			t.Errorf("sortPapers(%q) = %s, want %s", tt.sortBy, got.String(), tt.want)
+		}
+	}
+}
+
+func TestRankRelevance(t *testing.T) {
+	t.Parallel()
+
+	// OpenAlex-style raw scores, one out of order
+	papers := []ResearchPaper{{Title: "x", RelevanceScore: 12}, {Title: "y", RelevanceScore: 900}, {Title: "z", RelevanceScore: 12}}
+	rankRelevance(papers)
+	if papers[0].Title != "y" || papers[1].Title != "x" || papers[2].Title != "z" {
+		t.Errorf("Expected papers ranked by score, got %+v", papers)
+	}
+	if papers[0].RelevanceScore != 1 || !(papers[1].RelevanceScore < 1 && papers[1].RelevanceScore > papers[2].RelevanceScore) {
+		t.Errorf("Unexpected scores %v, %v, %v", papers[0].RelevanceScore, papers[1].RelevanceScore, papers[2].RelevanceScore)
+	}
+
+	// A paper found by two providers outranks the top result of one
+	merged := deduplicatePapers([]ResearchPaper{
+		{Title: "Only here", RelevanceScore: 1},
+		{Title: "Everywhere", DOI: "10.1/x", RelevanceScore: papers[2].RelevanceScore},
+		{Title: "everywhere", DOI: "10.1/X", RelevanceScore: papers[2].RelevanceScore},
+	})
+	sortPapers(merged, "relevance")
+	if len(merged) != 2 || merged[0].Title != "Everywhere" {
+		t.Errorf("Expected the merged paper first, got %+v", merged)
+	}
+}
+
+func TestResearchPaperAPIHandler_SortBy(t *testing.T) {
+	t.Parallel()
+
+	s2Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(semanticScholarResponse))
+	}))
+	defer s2Server.Close()
+	openAlexServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(openAlexResponse))
+	}))
+	defer openAlexServer.Close()
+	pubmedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "esearch.fcgi") {
+			_, _ = w.Write([]byte(`{"esearchresult": {"count": "1", "idlist": ["30000001"]}}`))
+			return
+		}
+		// A print-only article dated by its journal issue
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<PubmedArticleSet>
+	<PubmedArticle>
+		<MedlineCitation>
+			<PMID>30000001</PMID>
+			<Article>
+				<ArticleTitle>Attention in Clinical Notes</ArticleTitle>
+				<Journal>
+					<Title>Medical Informatics</Title>
+					<JournalIssue><PubDate><Year>2019</Year><Month>Jan</Month></PubDate></JournalIssue>
+				</Journal>
+			</Article>
+		</MedlineCitation>
+	</PubmedArticle>
+</PubmedArticleSet>`))
+	}))
+	defer pubmedServer.Close()
+
+	cfg := newToolConfig(
+		WithProviderBaseURL("semanticscholar", s2Server.URL),
+		WithProviderBaseURL("openalex", openAlexServer.URL),
+		WithProviderBaseURL("pubmed", pubmedServer.URL+"/"),
+	)
+	tests := []struct {
+		sortBy string
+		want   []string
+	}{
+		// Each provider's best match first, in provider order
+		{"relevance", []string{"Attention is All you Need", "The state of OA: a large-scale analysis", "Attention in Clinical Notes", "A Workshop Note"}},
+		{"date", []string{"A Workshop Note", "Attention in Clinical Notes", "The state of OA: a large-scale analysis", "Attention is All you Need"}},
+		{"citations", []string{"Attention is All you Need", "The state of OA: a large-scale analysis", "A Workshop Note", "Attention in Clinical Notes"}},
+	}
+	for _, tt := range tests {
+		result, err := cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{
+			Query:     "attention",
+			SortBy:    tt.sortBy,
+			Providers: []string{"semanticscholar", "openalex", "pubmed"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var titles []string
+		for _, p := range result.Papers {
+			titles = append(titles, p.Title)
+		}
+		if !reflect.DeepEqual(titles, tt.want) {
+			t.Errorf("sort_by %s: got %v, want %v", tt.sortBy, titles, tt.want)
+		}
+		for _, p := range result.Papers {
+			if p.Source == "PubMed" && p.PublishedDate != "2019-01" {
+				t.Errorf("Expected the journal issue date, got %q", p.PublishedDate)
+			}
+		}
+	}
+}
@@ -11,9 +11,13 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
 )
 
 func TestNewSearchNewsAPITool(t *testing.T) {
+	t.Parallel()
+
 	tool := NewSearchNewsAPITool()
 
 	if tool.Name() != "search_news_api" {
@@ -28,6 +32,8 @@ func TestNewSearchNewsAPITool(t *testing.T) {
 }
 
 func TestSearchNewsAPIHandler_Success(t *testing.T) {
+	t.Parallel()
+
 	// Create mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Verify request
@@ -92,21 +98,18 @@ func TestSearchNewsAPIHandler_Success(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override NewsAPI URL for testing
-	originalURL := newsAPIURL
-	newsAPIURL = server.URL + "/v2/everything"
-	defer func() { newsAPIURL = originalURL }()
+	// Point the tool at the mock server
+	cfg := newToolConfig(WithBaseURL(server.URL+"/v2/everything"), WithAPIKey("test-api-key"))
 
 	params := SearchNewsAPIParams{
 		Query:    "artificial intelligence",
-		APIKey:   "test-api-key",
 		Language: "en",
 		SortBy:   "popularity",
 		PageSize: 10,
 	}
 
 	ctx := context.Background()
-	result, err := searchNewsAPIHandler(ctx, params)
+	result, err := cfg.searchNewsAPIHandler(ctx, params)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -139,6 +142,8 @@ func TestSearchNewsAPIHandler_Success(t *testing.T) {
 }
 
 func TestSearchNewsAPIHandler_WithFilters(t *testing.T) {
+	t.Parallel()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check date filters
 		q := r.URL.Query()
@@ -179,20 +184,17 @@ func TestSearchNewsAPIHandler_WithFilters(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalURL := newsAPIURL
-	newsAPIURL = server.URL + "/v2/everything"
-	defer func() { newsAPIURL = originalURL }()
+	cfg := newToolConfig(WithBaseURL(server.URL+"/v2/everything"), WithAPIKey("test-api-key"))
 
 	params := SearchNewsAPIParams{
 		Query:    "technology",
-		APIKey:   "test-api-key",
 		DateFrom: "2024-01-01",
 		DateTo:   "2024-01-31",
 		Domains:  "bbc.com,cnn.com",
 	}
 
 	ctx := context.Background()
-	result, err := searchNewsAPIHandler(ctx, params)
+	result, err := cfg.searchNewsAPIHandler(ctx, params)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -203,14 +205,58 @@ func TestSearchNewsAPIHandler_WithFilters(t *testing.T) {
 	}
 }
 
+func TestSearchNewsAPITool_IndependentInstances(t *testing.T) {
+	t.Parallel()
+
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("apiKey") != name+"-key" {
+				t.Errorf("Expected apiKey '%s-key', got '%s'", name, r.URL.Query().Get("apiKey"))
+			}
+			response := map[string]any{
+				"status":       "ok",
+				"totalResults": 1,
+				"articles": []map[string]any{
+					{"title": name, "url": "https://example.com/" + name},
+				},
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(response)
+		}))
+	}
+	first := newServer("first")
+	defer first.Close()
+	second := newServer("second")
+	defer second.Close()
+
+	firstTool := NewSearchNewsAPITool(WithBaseURL(first.URL), WithAPIKey("first-key"))
+	secondTool := NewSearchNewsAPITool(WithBaseURL(second.URL), WithAPIKey("second-key"))
+
+	ctx := context.Background()
+	for name, tool := range map[string]domain.Tool{"first": firstTool, "second": secondTool} {
+		out, err := tool.Execute(ctx, map[string]any{"query": "test"})
+		if err != nil {
+			t.Fatalf("Unexpected error from %s tool: %v", name, err)
+		}
+		result, ok := out.(*SearchNewsAPIResult)
+		if !ok {
+			t.Fatalf("Unexpected result type %T", out)
+		}
+		if len(result.Articles) != 1 || result.Articles[0].Title != name {
+			t.Errorf("Expected article from %s server, got %+v", name, result.Articles)
+		}
+	}
+}
+
 func TestSearchNewsAPIHandler_EmptyQuery(t *testing.T) {
+	t.Parallel()
+
 	params := SearchNewsAPIParams{
-		Query:  "",
-		APIKey: "test-api-key",
+		Query: "",
 	}
 
 	ctx := context.Background()
-	_, err := searchNewsAPIHandler(ctx, params)
+	_, err := newToolConfig(WithAPIKey("test-api-key")).searchNewsAPIHandler(ctx, params)
 
 	if err == nil {
 		t.Error("Expected error for empty query")
@@ -218,6 +264,8 @@ func TestSearchNewsAPIHandler_EmptyQuery(t *testing.T) {
 }
 
 func TestSearchNewsAPIHandler_APIError(t *testing.T) {
+	t.Parallel()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Send error response
 		response := map[string]interface{}{
@@ -234,17 +282,14 @@ func TestSearchNewsAPIHandler_APIError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalURL := newsAPIURL
-	newsAPIURL = server.URL + "/v2/everything"
-	defer func() { newsAPIURL = originalURL }()
+	cfg := newToolConfig(WithBaseURL(server.URL+"/v2/everything"), WithAPIKey("invalid-key"))
 
 	params := SearchNewsAPIParams{
-		Query:  "test",
-		APIKey: "invalid-key",
+		Query: "test",
 	}
 
 	ctx := context.Background()
-	_, err := searchNewsAPIHandler(ctx, params)
+	_, err := cfg.searchNewsAPIHandler(ctx, params)
 
 	if err == nil {
 		t.Error("Expected error for API error response")
@@ -255,26 +300,25 @@ func TestSearchNewsAPIHandler_APIError(t *testing.T) {
 }
 
 func TestSearchNewsAPIHandler_Timeout(t *testing.T) {
+	t.Parallel()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Simulate timeout
 		time.Sleep(3 * time.Second)
 	}))
 	defer server.Close()
 
-	originalURL := newsAPIURL
-	newsAPIURL = server.URL + "/v2/everything"
-	defer func() { newsAPIURL = originalURL }()
+	cfg := newToolConfig(WithBaseURL(server.URL+"/v2/everything"), WithAPIKey("test-key"))
 
 	params := SearchNewsAPIParams{
-		Query:  "test",
-		APIKey: "test-key",
+		Query: "test",
 	}
 
 	// Create context with short timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
-	_, err := searchNewsAPIHandler(ctx, params)
+	_, err := cfg.searchNewsAPIHandler(ctx, params)
 
 	if err == nil {
 		t.Error("Expected timeout error")
@@ -282,6 +326,8 @@ func TestSearchNewsAPIHandler_Timeout(t *testing.T) {
 }
 
 func TestSearchNewsAPIHandler_Pagination(t *testing.T) {
+	t.Parallel()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		q := r.URL.Query()
 		if q.Get("page") != "2" {
@@ -304,19 +350,16 @@ func TestSearchNewsAPIHandler_Pagination(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalURL := newsAPIURL
-	newsAPIURL = server.URL + "/v2/everything"
-	defer func() { newsAPIURL = originalURL }()
+	cfg := newToolConfig(WithBaseURL(server.URL+"/v2/everything"), WithAPIKey("test-key"))
 
 	params := SearchNewsAPIParams{
 		Query:    "test",
-		APIKey:   "test-key",
 		Page:     2,
 		PageSize: 50,
 	}
 
 	ctx := context.Background()
-	result, err := searchNewsAPIHandler(ctx, params)
+	result, err := cfg.searchNewsAPIHandler(ctx, params)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -328,6 +371,8 @@ func TestSearchNewsAPIHandler_Pagination(t *testing.T) {
 }
 
 func TestSearchNewsAPIHandler_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		if _, err := w.Write([]byte("invalid json")); err != nil {
@@ -336,17 +381,14 @@ func TestSearchNewsAPIHandler_InvalidJSON(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalURL := newsAPIURL
-	newsAPIURL = server.URL + "/v2/everything"
-	defer func() { newsAPIURL = originalURL }()
+	cfg := newToolConfig(WithBaseURL(server.URL+"/v2/everything"), WithAPIKey("test-key"))
 
 	params := SearchNewsAPIParams{
-		Query:  "test",
-		APIKey: "test-key",
+		Query: "test",
 	}
 
 	ctx := context.Background()
-	_, err := searchNewsAPIHandler(ctx, params)
+	_, err := cfg.searchNewsAPIHandler(ctx, params)
 
 	if err == nil {
 		t.Error("Expected error for invalid JSON")
@@ -356,6 +398,8 @@ func TestSearchNewsAPIHandler_InvalidJSON(t *testing.T) {
 // Brave Search API Tests
 
 func TestNewSearchWebBraveTool(t *testing.T) {
+	t.Parallel()
+
 	tool := NewSearchWebBraveTool()
 
 	if tool.Name() != "search_web_brave" {
@@ -368,6 +412,8 @@ func TestNewSearchWebBraveTool(t *testing.T) {
 }
 
 func TestSearchWebBraveHandler_Success(t *testing.T) {
+	t.Parallel()
+
 	// Create mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Verify request
@@ -454,19 +500,16 @@ func TestSearchWebBraveHandler_Success(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override Brave API URL for testing
-	originalURL := braveSearchURL
-	braveSearchURL = server.URL + "/res/v1/web/search"
-	defer func() { braveSearchURL = originalURL }()
+	// Point the tool at the mock server
+	cfg := newToolConfig(WithBaseURL(server.URL+"/res/v1/web/search"), WithAPIKey("test-api-key"))
 
 	params := SearchWebBraveParams{
-		Query:  "artificial intelligence",
-		APIKey: "test-api-key",
-		Count:  10,
+		Query: "artificial intelligence",
+		Count: 10,
 	}
 
 	ctx := context.Background()
-	result, err := searchWebBraveHandler(ctx, params)
+	result, err := cfg.searchWebBraveHandler(ctx, params)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -499,6 +542,8 @@ func TestSearchWebBraveHandler_Success(t *testing.T) {
 }
 
 func TestSearchWebBraveHandler_WithFilters(t *testing.T) {
+	t.Parallel()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check filter parameters
 		q := r.URL.Query()
@@ -543,13 +588,10 @@ func TestSearchWebBraveHandler_WithFilters(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalURL := braveSearchURL
-	braveSearchURL = server.URL + "/res/v1/web/search"
-	defer func() { braveSearchURL = originalURL }()
+	cfg := newToolConfig(WithBaseURL(server.URL+"/res/v1/web/search"), WithAPIKey("test-api-key"))
 
 	params := SearchWebBraveParams{
 		Query:        "technology",
-		APIKey:       "test-api-key",
 		Country:      "US",
 		SearchLang:   "en",
 		SafeSearch:   "moderate",
@@ -558,7 +600,7 @@ func TestSearchWebBraveHandler_WithFilters(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	result, err := searchWebBraveHandler(ctx, params)
+	result, err := cfg.searchWebBraveHandler(ctx, params)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -570,6 +612,8 @@ func TestSearchWebBraveHandler_WithFilters(t *testing.T) {
 }
 
 func TestSearchWebBraveHandler_WithAISummary(t *testing.T) {
+	t.Parallel()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check AI summary parameter
 		q := r.URL.Query()
@@ -612,18 +656,15 @@ func TestSearchWebBraveHandler_WithAISummary(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalURL := braveSearchURL
-	braveSearchURL = server.URL + "/res/v1/web/search"
-	defer func() { braveSearchURL = originalURL }()
+	cfg := newToolConfig(WithBaseURL(server.URL+"/res/v1/web/search"), WithAPIKey("test-api-key"))
 
 	params := SearchWebBraveParams{
 		Query:   "climate change",
-		APIKey:  "test-api-key",
 		Summary: true,
 	}
 
 	ctx := context.Background()
-	result, err := searchWebBraveHandler(ctx, params)
+	result, err := cfg.searchWebBraveHandler(ctx, params)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -639,13 +680,14 @@ func TestSearchWebBraveHandler_WithAISummary(t *testing.T) {
 }
 
 func TestSearchWebBraveHandler_EmptyQuery(t *testing.T) {
+	t.Parallel()
+
 	params := SearchWebBraveParams{
-		Query:  "",
-		APIKey: "test-api-key",
+		Query: "",
 	}
 
 	ctx := context.Background()
-	_, err := searchWebBraveHandler(ctx, params)
+	_, err := newToolConfig(WithAPIKey("test-api-key")).searchWebBraveHandler(ctx, params)
 
 	if err == nil {
 		t.Error("Expected error for empty query")
@@ -653,16 +695,17 @@ func TestSearchWebBraveHandler_EmptyQuery(t *testing.T) {
 }
 
 func TestSearchWebBraveHandler_QueryTooLong(t *testing.T) {
+	t.Parallel()
+
 	// Create a query longer than 400 characters
 	longQuery := strings.Repeat("test ", 100)
 
 	params := SearchWebBraveParams{
-		Query:  longQuery,
-		APIKey: "test-api-key",
+		Query: longQuery,
 	}
 
 	ctx := context.Background()
-	_, err := searchWebBraveHandler(ctx, params)
+	_, err := newToolConfig(WithAPIKey("test-api-key")).searchWebBraveHandler(ctx, params)
 
 	if err == nil {
 		t.Error("Expected error for query too long")
@@ -673,6 +716,8 @@ func TestSearchWebBraveHandler_QueryTooLong(t *testing.T) {
 }
 
 func TestSearchWebBraveHandler_APIError(t *testing.T) {
+	t.Parallel()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Send error response
 		w.WriteHeader(http.StatusUnauthorized)
@@ -695,17 +740,14 @@ func TestSearchWebBraveHandler_APIError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalURL := braveSearchURL
-	braveSearchURL = server.URL + "/res/v1/web/search"
-	defer func() { braveSearchURL = originalURL }()
+	cfg := newToolConfig(WithBaseURL(server.URL+"/res/v1/web/search"), WithAPIKey("invalid-key"))
 
 	params := SearchWebBraveParams{
-		Query:  "test",
-		APIKey: "invalid-key",
+		Query: "test",
 	}
 
 	ctx := context.Background()
-	_, err := searchWebBraveHandler(ctx, params)
+	_, err := cfg.searchWebBraveHandler(ctx, params)
 
 	if err == nil {
 		t.Error("Expected error for API error response")
@@ -716,6 +758,8 @@ func TestSearchWebBraveHandler_APIError(t *testing.T) {
 }
 
 func TestSearchWebBraveHandler_Pagination(t *testing.T) {
+	t.Parallel()
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		q := r.URL.Query()
 		if q.Get("offset") != "20" {
@@ -743,19 +787,16 @@ func TestSearchWebBraveHandler_Pagination(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalURL := braveSearchURL
-	braveSearchURL = server.URL + "/res/v1/web/search"
-	defer func() { braveSearchURL = originalURL }()
+	cfg := newToolConfig(WithBaseURL(server.URL+"/res/v1/web/search"), WithAPIKey("test-key"))
 
 	params := SearchWebBraveParams{
 		Query:  "test",
-		APIKey: "test-key",
 		Count:  20,
 		Offset: 20,
 	}
 
 	ctx := context.Background()
-	result, err := searchWebBraveHandler(ctx, params)
+	result, err := cfg.searchWebBraveHandler(ctx, params)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -769,13 +810,15 @@ func TestSearchWebBraveHandler_Pagination(t *testing.T) {
 // Research Search API Tests
 
 func TestNewResearchPaperAPITool(t *testing.T) {
+	t.Parallel()
+
 	tool := NewResearchPaperAPITool()
 
 	if tool.Name() != "research_paper_api" {
 		t.Errorf("Expected tool name 'research_paper_api', got '%s'", tool.Name())
 	}
 
-	expectedDesc := "Searches for academic papers across multiple research databases (arXiv, PubMed, CORE) in parallel"
+	expectedDesc := "Searches for academic papers across multiple research databases (arXiv, PubMed, CORE, Semantic Scholar, OpenAlex) in parallel"
 	if tool.Description() != expectedDesc {
 		t.Errorf("Unexpected tool description: %s", tool.Description())
 	}
@@ -784,6 +827,8 @@ func TestNewResearchPaperAPITool(t *testing.T) {
 // Research search tests are in api_tools_research_test.go
 
 func TestResearchToolRegistration(t *testing.T) {
+	t.Parallel()
+
 	tool := NewResearchPaperAPITool()
 
 	if tool.Name() != "research_paper_api" {
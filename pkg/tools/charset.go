@@ -0,0 +1,125 @@
+// ABOUTME: Character set detection and UTF-8 transcoding for fetched documents
+// ABOUTME: Detects encodings from BOMs, Content-Type, <meta charset> and XML declarations
+
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+var xmlEncodingRegex = regexp.MustCompile(`^(<\?xml[^>]*?encoding\s*=\s*["'])([A-Za-z0-9._:-]+)(["'][^>]*\?>)`)
+
+// byte order marks and the encodings they select
+var byteOrderMarks = []struct {
+	bom      []byte
+	encoding string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, "utf-8"},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// decodeHTML converts an HTML document to UTF-8. The encoding comes from a
+// byte order mark, the Content-Type charset or a <meta> declaration in the
+// first 1024 bytes; undeclared documents that are not valid UTF-8 are read as
+// Windows-1252, as browsers do.
+func decodeHTML(body []byte, contentType string) string {
+	enc, name, _ := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" {
+		return strings.TrimPrefix(string(body), "\ufeff")
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(stripBOM(body))
+	if err != nil {
+		return string(body)
+	}
+	return string(decoded)
+}
+
+// decodeBody converts a fetched body to UTF-8. HTML is decoded with
+// decodeHTML; other content is decoded only when Content-Type names a charset.
+func decodeBody(body []byte, contentType string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return string(body)
+	}
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		return decodeHTML(body, contentType)
+	}
+
+	if label := params["charset"]; label != "" {
+		if enc, name := charset.Lookup(label); enc != nil && name != "utf-8" {
+			if decoded, err := enc.NewDecoder().Bytes(stripBOM(body)); err == nil {
+				return string(decoded)
+			}
+		}
+	}
+	return string(body)
+}
+
+// newXMLDecoder returns a streaming decoder that reads an XML document as
+// UTF-8, detecting the encoding from the start of r
+func newXMLDecoder(r io.Reader, contentType string) (*xml.Decoder, error) {
+	br := bufio.NewReaderSize(r, 1024)
+	// A short document yields fewer bytes along with the read error, which
+	// the decoder will report itself
+	prefix, _ := br.Peek(1024)
+	label := xmlCharsetLabel(prefix, contentType)
+	if n := len(prefix) - len(stripBOM(prefix)); n > 0 {
+		br.Discard(n)
+	}
+
+	var src io.Reader = br
+	if label != "" {
+		enc, name := charset.Lookup(label)
+		if enc == nil {
+			return nil, fmt.Errorf("unsupported character set %q", label)
+		}
+		if name != "utf-8" {
+			src = enc.NewDecoder().Reader(br)
+		}
+	}
+
+	decoder := xml.NewDecoder(src)
+	// The stream is UTF-8 by now, whatever the declaration says
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return decoder, nil
+}
+
+// xmlCharsetLabel returns the encoding an XML document declares through a
+// byte order mark, the Content-Type charset or its XML declaration, in that
+// order of precedence, or "" when it declares none
+func xmlCharsetLabel(prefix []byte, contentType string) string {
+	for _, b := range byteOrderMarks {
+		if bytes.HasPrefix(prefix, b.bom) {
+			return b.encoding
+		}
+	}
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		return params["charset"]
+	}
+	if m := xmlEncodingRegex.FindSubmatch(bytes.TrimLeft(prefix, " \t\r\n")); m != nil {
+		return string(m[2])
+	}
+	return ""
+}
+
+func stripBOM(body []byte) []byte {
+	for _, b := range byteOrderMarks {
+		if bytes.HasPrefix(body, b.bom) {
+			return body[len(b.bom):]
+		}
+	}
+	return body
+}
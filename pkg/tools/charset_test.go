@@ -0,0 +1,127 @@
+// ABOUTME: Unit tests for character set detection and transcoding
+// ABOUTME: Tests header, BOM, meta and XML declaration detection in web and feed tools
+
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// "日本語" encoded as Shift_JIS
+var shiftJISBytes = []byte{0x93, 0xFA, 0x96, 0x7B, 0x8C, 0xEA}
+
+func TestDecodeHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		want        string
+	}{
+		{
+			name:        "content-type charset",
+			body:        append([]byte("<p>"), append(shiftJISBytes, "</p>"...)...),
+			contentType: "text/html; charset=Shift_JIS",
+			want:        "<p>日本語</p>",
+		},
+		{
+			name:        "meta charset",
+			body:        []byte("<meta charset=\"iso-8859-1\"><p>caf\xe9</p>"),
+			contentType: "text/html",
+			want:        "<meta charset=\"iso-8859-1\"><p>café</p>",
+		},
+		{
+			name:        "meta http-equiv",
+			body:        []byte("<meta http-equiv=\"Content-Type\" content=\"text/html; charset=windows-1252\"><p>\x93quoted\x94</p>"),
+			contentType: "text/html",
+			want:        "<meta http-equiv=\"Content-Type\" content=\"text/html; charset=windows-1252\"><p>“quoted”</p>",
+		},
+		{
+			name:        "utf-8 byte order mark",
+			body:        []byte("\xef\xbb\xbf<p>naïve</p>"),
+			contentType: "text/html; charset=iso-8859-1",
+			want:        "<p>naïve</p>",
+		},
+		{
+			name:        "undeclared utf-8",
+			body:        []byte("<p>naïve</p>"),
+			contentType: "text/html",
+			want:        "<p>naïve</p>",
+		},
+		{
+			name:        "undeclared legacy bytes",
+			body:        []byte("<p>caf\xe9</p>"),
+			contentType: "text/html",
+			want:        "<p>café</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeHTML(tt.body, tt.contentType); got != tt.want {
+				t.Errorf("decodeHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchWebPageHandler_ShiftJIS(t *testing.T) {
+	t.Parallel()
+
+	page := append([]byte("<html><head><title>"), shiftJISBytes...)
+	page = append(page, "</title></head><body><p>"...)
+	page = append(page, shiftJISBytes...)
+	page = append(page, "</p></body></html>"...)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=Shift_JIS")
+		_, _ = w.Write(page)
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL, ExtractText: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Title != "日本語" {
+		t.Errorf("Expected title '日本語', got %q", result.Title)
+	}
+	if !strings.Contains(result.Content, "日本語") {
+		t.Errorf("Expected decoded content, got %q", result.Content)
+	}
+}
+
+func TestFetchRSSFeedHandler_Latin1(t *testing.T) {
+	t.Parallel()
+
+	feed := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+		"<rss version=\"2.0\"><channel><title>Caf\xe9 News</title>" +
+		"<item><title>Cr\xe8me br\xfbl\xe9e</title><link>https://example.com/1</link></item>" +
+		"</channel></rss>"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(feed))
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().fetchRSSFeedHandler(context.Background(), FetchRSSFeedParams{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Title != "Café News" {
+		t.Errorf("Expected title 'Café News', got %q", result.Title)
+	}
+	if len(result.Items) != 1 || result.Items[0].Title != "Crème brûlée" {
+		t.Errorf("Expected decoded item title, got %+v", result.Items)
+	}
+}
@@ -0,0 +1,191 @@
+// ABOUTME: Pluggable credential providers for tool API keys
+// ABOUTME: Supports environment, dotenv file, command and per-request context credentials
+
+package tools
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Credential names used by the built-in tools
+const (
+	CredentialNewsAPI     = "NEWS_API_KEY"
+	CredentialBraveSearch = "BRAVE_SEARCH_API_KEY"
+	CredentialCORE        = "CORE_API_KEY"
+	CredentialPubMed      = "PUBMED_API_KEY"
+
+	CredentialSemanticScholar = "SEMANTIC_SCHOLAR_API_KEY"
+	CredentialOpenAlex        = "OPENALEX_API_KEY"
+)
+
+// ErrCredentialNotFound is returned when a provider has no value for a credential
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// CredentialProvider supplies API keys to tools. Keys are resolved at call
+// time and never appear in the parameter schemas sent to the model.
+type CredentialProvider interface {
+	// Credential returns the value stored under name, or ErrCredentialNotFound
+	Credential(ctx context.Context, name string) (string, error)
+}
+
+// CredentialProviderFunc adapts a function to the CredentialProvider interface
+type CredentialProviderFunc func(ctx context.Context, name string) (string, error)
+
+// Credential calls f(ctx, name)
+func (f CredentialProviderFunc) Credential(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// EnvCredentialProvider reads credentials from environment variables,
+// optionally prefixed (e.g. "TENANT_A_" + "NEWS_API_KEY")
+type EnvCredentialProvider struct {
+	Prefix string
+}
+
+// Credential implements CredentialProvider
+func (p EnvCredentialProvider) Credential(ctx context.Context, name string) (string, error) {
+	if value := os.Getenv(p.Prefix + name); value != "" {
+		return value, nil
+	}
+	return "", ErrCredentialNotFound
+}
+
+// FileCredentialProvider reads credentials from a dotenv-style file of
+// KEY=value lines. The file is read on every lookup so rotated keys are
+// picked up without a restart.
+type FileCredentialProvider struct {
+	Path string
+}
+
+// Credential implements CredentialProvider
+func (p FileCredentialProvider) Credential(ctx context.Context, name string) (string, error) {
+	values, err := parseDotenvFile(p.Path)
+	if err != nil {
+		return "", fmt.Errorf("reading credentials file: %w", err)
+	}
+	if value := values[name]; value != "" {
+		return value, nil
+	}
+	return "", ErrCredentialNotFound
+}
+
+// parseDotenvFile parses KEY=value lines, ignoring blank lines, comments and
+// an optional "export " prefix. Values may be single or double quoted.
+func parseDotenvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			// Strip trailing comments from unquoted values
+			value = strings.TrimSpace(value[:i])
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// CommandCredentialProvider runs an external command (e.g. a secrets manager
+// CLI) to obtain a credential. The credential name is appended as the last
+// argument and the trimmed standard output is used as the value. An empty
+// output means the credential is not available.
+type CommandCredentialProvider struct {
+	Command []string
+}
+
+// Credential implements CredentialProvider
+func (p CommandCredentialProvider) Credential(ctx context.Context, name string) (string, error) {
+	if len(p.Command) == 0 {
+		return "", fmt.Errorf("credential command is empty")
+	}
+
+	args := append(append([]string{}, p.Command[1:]...), name)
+	cmd := exec.CommandContext(ctx, p.Command[0], args...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("running credential command: %w", err)
+	}
+
+	value := strings.TrimSpace(string(out))
+	if value == "" {
+		return "", ErrCredentialNotFound
+	}
+	return value, nil
+}
+
+// ChainCredentialProvider tries each provider in order and returns the first
+// credential found
+type ChainCredentialProvider []CredentialProvider
+
+// Credential implements CredentialProvider
+func (c ChainCredentialProvider) Credential(ctx context.Context, name string) (string, error) {
+	for _, p := range c {
+		value, err := p.Credential(ctx, name)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrCredentialNotFound) {
+			return "", err
+		}
+	}
+	return "", ErrCredentialNotFound
+}
+
+type credentialsContextKey struct{}
+
+// ContextWithCredentials returns a context carrying per-request credentials.
+// Tools always consult these first, which lets a multi-tenant service pass a
+// different key on every call.
+func ContextWithCredentials(ctx context.Context, creds map[string]string) context.Context {
+	merged := make(map[string]string)
+	for k, v := range CredentialsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range creds {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, credentialsContextKey{}, merged)
+}
+
+// CredentialsFromContext returns the credentials carried by the context, if any
+func CredentialsFromContext(ctx context.Context) map[string]string {
+	creds, _ := ctx.Value(credentialsContextKey{}).(map[string]string)
+	return creds
+}
+
+// ContextCredentialProvider reads credentials attached with ContextWithCredentials
+type ContextCredentialProvider struct{}
+
+// Credential implements CredentialProvider
+func (ContextCredentialProvider) Credential(ctx context.Context, name string) (string, error) {
+	if value := CredentialsFromContext(ctx)[name]; value != "" {
+		return value, nil
+	}
+	return "", ErrCredentialNotFound
+}
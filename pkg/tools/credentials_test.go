@@ -0,0 +1,183 @@
+// ABOUTME: Unit tests for credential providers
+// ABOUTME: Tests env, file, command, chain and context credential lookup order
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
+)
+
+func TestEnvCredentialProvider(t *testing.T) {
+	t.Setenv("TENANT_NEWS_API_KEY", "tenant-key")
+
+	provider := EnvCredentialProvider{Prefix: "TENANT_"}
+	value, err := provider.Credential(context.Background(), CredentialNewsAPI)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value != "tenant-key" {
+		t.Errorf("Expected 'tenant-key', got '%s'", value)
+	}
+
+	if _, err := provider.Credential(context.Background(), "MISSING_KEY"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Expected ErrCredentialNotFound, got %v", err)
+	}
+}
+
+func TestFileCredentialProvider(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	content := `# API keys
+NEWS_API_KEY=plain-key
+export BRAVE_SEARCH_API_KEY="quoted key"
+CORE_API_KEY=core-key # trailing comment
+PUBMED_API_KEY=
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write credentials file: %v", err)
+	}
+
+	provider := FileCredentialProvider{Path: path}
+	ctx := context.Background()
+
+	tests := map[string]string{
+		CredentialNewsAPI:     "plain-key",
+		CredentialBraveSearch: "quoted key",
+		CredentialCORE:        "core-key",
+	}
+	for name, want := range tests {
+		got, err := provider.Credential(ctx, name)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Expected %s=%q, got %q", name, want, got)
+		}
+	}
+
+	if _, err := provider.Credential(ctx, CredentialPubMed); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Expected ErrCredentialNotFound for empty value, got %v", err)
+	}
+}
+
+func TestFileCredentialProvider_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	provider := FileCredentialProvider{Path: filepath.Join(t.TempDir(), "missing.env")}
+	_, err := provider.Credential(context.Background(), CredentialNewsAPI)
+	if err == nil || errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Expected read error, got %v", err)
+	}
+}
+
+func TestCommandCredentialProvider(t *testing.T) {
+	t.Parallel()
+
+	provider := CommandCredentialProvider{Command: []string{"sh", "-c", `echo "secret-for-$0"`}}
+	value, err := provider.Credential(context.Background(), CredentialCORE)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value != "secret-for-CORE_API_KEY" {
+		t.Errorf("Expected 'secret-for-CORE_API_KEY', got '%s'", value)
+	}
+
+	empty := CommandCredentialProvider{Command: []string{"true"}}
+	if _, err := empty.Credential(context.Background(), CredentialCORE); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Expected ErrCredentialNotFound for empty output, got %v", err)
+	}
+
+	failing := CommandCredentialProvider{Command: []string{"false"}}
+	if _, err := failing.Credential(context.Background(), CredentialCORE); err == nil || errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Expected command error, got %v", err)
+	}
+}
+
+func TestChainCredentialProvider(t *testing.T) {
+	t.Parallel()
+
+	missing := CredentialProviderFunc(func(ctx context.Context, name string) (string, error) {
+		return "", ErrCredentialNotFound
+	})
+	found := CredentialProviderFunc(func(ctx context.Context, name string) (string, error) {
+		return "from-second", nil
+	})
+	broken := CredentialProviderFunc(func(ctx context.Context, name string) (string, error) {
+		return "", errors.New("vault unavailable")
+	})
+
+	value, err := ChainCredentialProvider{missing, found}.Credential(context.Background(), "KEY")
+	if err != nil || value != "from-second" {
+		t.Errorf("Expected 'from-second', got %q, %v", value, err)
+	}
+
+	if _, err := (ChainCredentialProvider{missing, broken, found}).Credential(context.Background(), "KEY"); err == nil {
+		t.Error("Expected chain to stop at a provider error")
+	}
+
+	if _, err := (ChainCredentialProvider{missing}).Credential(context.Background(), "KEY"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Expected ErrCredentialNotFound, got %v", err)
+	}
+}
+
+func TestContextWithCredentials_Merges(t *testing.T) {
+	t.Parallel()
+
+	ctx := ContextWithCredentials(context.Background(), map[string]string{CredentialNewsAPI: "news"})
+	ctx = ContextWithCredentials(ctx, map[string]string{CredentialCORE: "core"})
+
+	creds := CredentialsFromContext(ctx)
+	if creds[CredentialNewsAPI] != "news" || creds[CredentialCORE] != "core" {
+		t.Errorf("Expected merged credentials, got %v", creds)
+	}
+}
+
+func TestToolConfigCredential_Precedence(t *testing.T) {
+	t.Parallel()
+
+	provider := CredentialProviderFunc(func(ctx context.Context, name string) (string, error) {
+		return "from-provider", nil
+	})
+	ctx := context.Background()
+	requestCtx := ContextWithCredentials(ctx, map[string]string{CredentialNewsAPI: "from-context"})
+
+	cfg := newToolConfig(WithCredentialProvider(provider), WithAPIKey("from-option"))
+	if got, _ := cfg.credential(requestCtx, CredentialNewsAPI, cfg.apiKey); got != "from-context" {
+		t.Errorf("Expected context key to win, got %q", got)
+	}
+	if got, _ := cfg.credential(ctx, CredentialNewsAPI, cfg.apiKey); got != "from-option" {
+		t.Errorf("Expected configured key, got %q", got)
+	}
+
+	cfg = newToolConfig(WithCredentialProvider(provider))
+	if got, _ := cfg.credential(ctx, CredentialNewsAPI, cfg.apiKey); got != "from-provider" {
+		t.Errorf("Expected provider key, got %q", got)
+	}
+}
+
+func TestAPIKeysNotInSchemas(t *testing.T) {
+	t.Parallel()
+
+	credentialParams := []string{"api_key", "core_api_key"}
+	for _, tool := range []domain.Tool{NewSearchNewsAPITool(), NewSearchWebBraveTool(), NewResearchPaperAPITool()} {
+		schema := tool.ParameterSchema()
+		for _, param := range credentialParams {
+			if _, ok := schema.Properties[param]; ok {
+				t.Errorf("Schema of %s exposes credential parameter %q", tool.Name(), param)
+			}
+		}
+		for _, required := range schema.Required {
+			if required == "api_key" {
+				t.Errorf("Schema of %s requires api_key", tool.Name())
+			}
+		}
+	}
+}
@@ -50,6 +50,7 @@ type FetchRSSFeedResult struct {
 	Description string     `json:"description"`
 	Link        string     `json:"link"`
 	Items       []FeedItem `json:"items"`
+	Truncated   bool       `json:"truncated,omitempty"` // The feed exceeded the size limit; later items are missing
 	FetchedAt   string     `json:"fetched_at"`
 }
 
@@ -86,16 +87,17 @@ var FetchRSSFeedParamSchema = &sdomain.Schema{
 }
 
 // NewFetchRSSFeedTool creates a new RSS feed fetching tool
-func NewFetchRSSFeedTool() domain.Tool {
+func NewFetchRSSFeedTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
 	return tools.NewTool(
 		"fetch_rss_feed",
 		"Fetches and parses an RSS feed from the given URL",
-		fetchRSSFeedHandler,
+		cfg.fetchRSSFeedHandler,
 		FetchRSSFeedParamSchema,
 	)
 }
 
-func fetchRSSFeedHandler(ctx context.Context, params FetchRSSFeedParams) (*FetchRSSFeedResult, error) {
+func (c *toolConfig) fetchRSSFeedHandler(ctx context.Context, params FetchRSSFeedParams) (*FetchRSSFeedResult, error) {
 	// Set default timeout
 	timeout := 30
 	if params.Timeout > 0 {
@@ -103,9 +105,7 @@ func fetchRSSFeedHandler(ctx context.Context, params FetchRSSFeedParams) (*Fetch
 	}
 
 	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
-	}
+	client := c.client(time.Duration(timeout) * time.Second)
 
 	// Create request with context
 	req, err := http.NewRequestWithContext(ctx, "GET", params.URL, nil)
@@ -128,15 +128,18 @@ func fetchRSSFeedHandler(ctx context.Context, params FetchRSSFeedParams) (*Fetch
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+	if err := c.checkContentType(resp); err != nil {
+		return nil, err
 	}
 
-	// Parse RSS feed
-	var feed RSSFeed
-	if err := xml.Unmarshal(body, &feed); err != nil {
+	// Parse the feed as it streams in, transcoding to UTF-8
+	body := newCappedReader(resp.Body, c.responseLimit(DefaultMaxResponseSize))
+	decoder, err := newXMLDecoder(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing RSS feed: %w", err)
+	}
+	feed, err := readRSSFeed(decoder, params.Limit)
+	if err != nil && !(body.truncated && feed != nil) {
 		return nil, fmt.Errorf("parsing RSS feed: %w", err)
 	}
 
@@ -146,6 +149,7 @@ func fetchRSSFeedHandler(ctx context.Context, params FetchRSSFeedParams) (*Fetch
 		Description: feed.Channel.Description,
 		Link:        feed.Channel.Link,
 		Items:       make([]FeedItem, 0, len(feed.Channel.Items)),
+		Truncated:   body.truncated,
 		FetchedAt:   time.Now().UTC().Format(time.RFC3339),
 	}
 
@@ -170,6 +174,78 @@ func fetchRSSFeedHandler(ctx context.Context, params FetchRSSFeedParams) (*Fetch
 	return result, nil
 }
 
+// readRSSFeed decodes an RSS document one item at a time. It stops once limit
+// items are read, so the rest of a long feed is never downloaded. When the
+// document breaks off, the feed read so far is returned with the error.
+func readRSSFeed(decoder *xml.Decoder, limit int) (*RSSFeed, error) {
+	var feed *RSSFeed
+	depth := 0
+	inChannel := false
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			if feed == nil {
+				return nil, fmt.Errorf("no <rss> element found")
+			}
+			return feed, nil
+		}
+		if err != nil {
+			return feed, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case depth == 1:
+				if t.Name.Local != "rss" {
+					return nil, fmt.Errorf("expected element type <rss> but have <%s>", t.Name.Local)
+				}
+				feed = &RSSFeed{XMLName: t.Name}
+			case depth == 2 && t.Name.Local == "channel":
+				inChannel = true
+			case depth == 3 && inChannel && t.Name.Local == "item":
+				var item RSSItem
+				if err := decoder.DecodeElement(&item, &t); err != nil {
+					return feed, err
+				}
+				depth-- // DecodeElement consumed the end tag
+				feed.Channel.Items = append(feed.Channel.Items, item)
+				if limit > 0 && len(feed.Channel.Items) >= limit {
+					return feed, nil
+				}
+			case depth == 3 && inChannel && channelField(&feed.Channel, t.Name) != nil:
+				if err := decoder.DecodeElement(channelField(&feed.Channel, t.Name), &t); err != nil {
+					return feed, err
+				}
+				depth--
+			}
+		case xml.EndElement:
+			if depth == 2 && t.Name.Local == "channel" {
+				inChannel = false
+			}
+			depth--
+		}
+	}
+}
+
+// channelField returns the RSSChannel field an element of the channel fills,
+// or nil. Namespaced elements such as atom:link are not channel fields.
+func channelField(channel *RSSChannel, name xml.Name) *string {
+	if name.Space != "" {
+		return nil
+	}
+	switch name.Local {
+	case "title":
+		return &channel.Title
+	case "link":
+		return &channel.Link
+	case "description":
+		return &channel.Description
+	}
+	return nil
+}
+
 // Helper function for float64 pointer
 func float64Ptr(f float64) *float64 {
 	return &f
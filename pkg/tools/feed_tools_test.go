@@ -76,7 +76,7 @@ func TestFetchRSSFeedHandler_Success(t *testing.T) {
 		Timeout: 30,
 	}
 
-	result, err := fetchRSSFeedHandler(ctx, params)
+	result, err := newToolConfig().fetchRSSFeedHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -123,7 +123,7 @@ func TestFetchRSSFeedHandler_WithLimit(t *testing.T) {
 		Limit: 2,
 	}
 
-	result, err := fetchRSSFeedHandler(ctx, params)
+	result, err := newToolConfig().fetchRSSFeedHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -139,7 +139,7 @@ func TestFetchRSSFeedHandler_InvalidURL(t *testing.T) {
 		URL: "not-a-valid-url",
 	}
 
-	_, err := fetchRSSFeedHandler(ctx, params)
+	_, err := newToolConfig().fetchRSSFeedHandler(ctx, params)
 	if err == nil {
 		t.Error("Expected error for invalid URL, got nil")
 	}
@@ -156,7 +156,7 @@ func TestFetchRSSFeedHandler_ServerError(t *testing.T) {
 		URL: server.URL,
 	}
 
-	_, err := fetchRSSFeedHandler(ctx, params)
+	_, err := newToolConfig().fetchRSSFeedHandler(ctx, params)
 	if err == nil {
 		t.Error("Expected error for server error, got nil")
 	}
@@ -174,7 +174,7 @@ func TestFetchRSSFeedHandler_InvalidXML(t *testing.T) {
 		URL: server.URL,
 	}
 
-	_, err := fetchRSSFeedHandler(ctx, params)
+	_, err := newToolConfig().fetchRSSFeedHandler(ctx, params)
 	if err == nil {
 		t.Error("Expected error for invalid XML, got nil")
 	}
@@ -194,7 +194,7 @@ func TestFetchRSSFeedHandler_Timeout(t *testing.T) {
 		Timeout: 1, // 1 second timeout
 	}
 
-	_, err := fetchRSSFeedHandler(ctx, params)
+	_, err := newToolConfig().fetchRSSFeedHandler(ctx, params)
 	if err == nil {
 		t.Error("Expected timeout error, got nil")
 	}
@@ -224,7 +224,7 @@ func TestFetchRSSFeedHandler_EmptyFeed(t *testing.T) {
 		URL: server.URL,
 	}
 
-	result, err := fetchRSSFeedHandler(ctx, params)
+	result, err := newToolConfig().fetchRSSFeedHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -0,0 +1,194 @@
+// ABOUTME: DOM helpers shared by the HTML-processing web tools
+// ABOUTME: Wraps golang.org/x/net/html with attribute lookup, traversal and readable text rendering
+
+package tools
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockElements start a new line when rendered as text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true,
+	"dd": true, "details": true, "dialog": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "summary": true, "table": true,
+	"tr": true, "ul": true,
+}
+
+// hiddenElements never contribute visible text
+var hiddenElements = map[string]bool{
+	"head": true, "noscript": true, "script": true, "style": true,
+	"svg": true, "template": true, "iframe": true, "object": true,
+}
+
+// parseHTML parses an HTML document. The parser is lenient and only fails
+// on read errors.
+func parseHTML(content string) (*html.Node, error) {
+	return html.Parse(strings.NewReader(content))
+}
+
+// isTag reports whether n is an element with one of the given tag names
+func isTag(n *html.Node, tags ...string) bool {
+	if n == nil || n.Type != html.ElementNode {
+		return false
+	}
+	for _, tag := range tags {
+		if n.Data == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// domAttr returns the value of an attribute, or "" if it is not set
+func domAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// domHasAttr reports whether n has an attribute, whatever its value
+func domHasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && strings.EqualFold(a.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// domFindAll returns every node under root (inclusive) matching match, in document order
+func domFindAll(root *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if match(n) {
+			found = append(found, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return found
+}
+
+// domFind returns the first node under root (inclusive) matching match
+func domFind(root *html.Node, match func(*html.Node) bool) *html.Node {
+	if match(root) {
+		return root
+	}
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if n := domFind(c, match); n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+// domText returns the concatenated text of n and its descendants, skipping
+// hidden elements, with whitespace collapsed
+func domText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		case html.ElementNode:
+			if hiddenElements[n.Data] {
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return normalizeSpace(b.String())
+}
+
+// normalizeSpace collapses runs of whitespace into single spaces
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// domBlockText renders n as readable plain text: block elements become
+// separate lines, blank lines separate paragraphs, and <pre> keeps its layout
+func domBlockText(n *html.Node) string {
+	var lines []string
+	var line strings.Builder
+
+	flush := func() {
+		if text := normalizeSpace(line.String()); text != "" {
+			lines = append(lines, text)
+		}
+		line.Reset()
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			line.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if hiddenElements[n.Data] {
+				return
+			}
+			switch n.Data {
+			case "br":
+				flush()
+				return
+			case "pre":
+				flush()
+				if text := strings.Trim(domRawText(n), "\n"); text != "" {
+					lines = append(lines, text)
+				}
+				return
+			case "td", "th":
+				line.WriteString(" ")
+			}
+		}
+
+		block := n.Type == html.ElementNode && blockElements[n.Data]
+		if block {
+			flush()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			flush()
+		}
+	}
+	walk(n)
+	flush()
+
+	return strings.Join(lines, "\n\n")
+}
+
+// domRawText returns the text of n and its descendants without collapsing whitespace
+func domRawText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
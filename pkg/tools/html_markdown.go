@@ -0,0 +1,501 @@
+// ABOUTME: HTML to CommonMark conversion for fetch_webpage
+// ABOUTME: Renders headings, emphasis, lists, absolute links, code blocks and GFM tables
+
+package tools
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// markdownInlineTags are rendered inline even when they wrap block content
+var markdownInlineTags = map[string]bool{
+	"a": true, "b": true, "strong": true, "em": true, "i": true, "code": true,
+	"del": true, "s": true, "strike": true, "img": true, "br": true,
+}
+
+var (
+	orderedMarkerRegex = regexp.MustCompile(`^(\d+)([.)]) `)
+	backtickRunRegex   = regexp.MustCompile("`+")
+)
+
+// htmlToMarkdown converts n and its descendants to CommonMark with GFM
+// tables and strikethrough. Relative links and images are resolved against
+// base when it is set.
+func htmlToMarkdown(n *html.Node, base *url.URL) string {
+	c := &markdownConverter{base: base}
+	return strings.Join(c.blocks(n), "\n\n")
+}
+
+type markdownConverter struct {
+	base *url.URL
+}
+
+// blocks renders the children of n as a list of Markdown blocks
+func (c *markdownConverter) blocks(n *html.Node) []string {
+	var out []string
+	var inline strings.Builder
+
+	flush := func() {
+		if p := c.paragraph(inline.String()); p != "" {
+			out = append(out, p)
+		}
+		inline.Reset()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && hiddenElements[child.Data] {
+			continue
+		}
+		if !c.isBlock(child) {
+			inline.WriteString(c.inline(child))
+			continue
+		}
+
+		flush()
+		if block := c.block(child); len(block) > 0 {
+			out = append(out, block...)
+		}
+	}
+	flush()
+
+	return out
+}
+
+// isBlock reports whether n renders as one or more blocks
+func (c *markdownConverter) isBlock(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return n.Type == html.DocumentNode
+	}
+	if blockElements[n.Data] || isTag(n, "td", "th", "tbody", "thead", "tfoot", "html") {
+		return true
+	}
+	if markdownInlineTags[n.Data] {
+		return false
+	}
+	// Unknown and generic containers (span, custom elements) wrapping blocks
+	return domFind(n, func(d *html.Node) bool { return d != n && d.Type == html.ElementNode && blockElements[d.Data] }) != nil
+}
+
+// block renders a block-level element
+func (c *markdownConverter) block(n *html.Node) []string {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(n.Data[1] - '0')
+		text := strings.ReplaceAll(c.paragraph(c.inline(n)), "  \n", " ")
+		if text == "" {
+			return nil
+		}
+		return []string{strings.Repeat("#", level) + " " + text}
+	case "p":
+		if p := c.paragraph(c.inline(n)); p != "" {
+			return []string{p}
+		}
+		return nil
+	case "ul", "ol":
+		if list := c.list(n); list != "" {
+			return []string{list}
+		}
+		return nil
+	case "blockquote":
+		inner := strings.Join(c.blocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		return []string{prefixLines(inner, "> ", ">")}
+	case "pre":
+		return []string{c.codeBlock(n)}
+	case "hr":
+		return []string{"---"}
+	case "table":
+		if table := c.table(n); table != "" {
+			return []string{table}
+		}
+		return nil
+	default:
+		return c.blocks(n)
+	}
+}
+
+// list renders a <ul> or <ol>
+func (c *markdownConverter) list(n *html.Node) string {
+	ordered := n.Data == "ol"
+	number := 1
+	if start, err := strconv.Atoi(domAttr(n, "start")); err == nil && ordered {
+		number = start
+	}
+
+	var items []string
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if !isTag(li, "li") {
+			continue
+		}
+
+		marker := "- "
+		if ordered {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+
+		var body strings.Builder
+		for i, block := range c.blocks(li) {
+			if i > 0 {
+				if strings.HasPrefix(block, "- ") || orderedMarkerRegex.MatchString(block) {
+					body.WriteString("\n")
+				} else {
+					body.WriteString("\n\n")
+				}
+			}
+			body.WriteString(block)
+		}
+
+		if body.Len() == 0 {
+			items = append(items, strings.TrimSpace(marker))
+			continue
+		}
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.TrimPrefix(prefixLines(body.String(), indent, ""), indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+// codeBlock renders <pre> as a fenced code block, taking the language from a
+// "language-*" or "lang-*" class on the <pre> or its <code> child
+func (c *markdownConverter) codeBlock(n *html.Node) string {
+	code := strings.TrimRight(domRawText(n), "\n")
+	code = strings.TrimPrefix(code, "\n")
+
+	lang := codeLanguage(n)
+	if child := domFind(n, func(d *html.Node) bool { return isTag(d, "code") }); lang == "" && child != nil {
+		lang = codeLanguage(child)
+	}
+
+	fence := "```"
+	for _, run := range backtickRunRegex.FindAllString(code, -1) {
+		if len(run) >= len(fence) {
+			fence = strings.Repeat("`", len(run)+1)
+		}
+	}
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+func codeLanguage(n *html.Node) string {
+	for _, class := range strings.Fields(domAttr(n, "class")) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if strings.HasPrefix(class, prefix) {
+				return strings.TrimPrefix(class, prefix)
+			}
+		}
+	}
+	return ""
+}
+
+// table renders a GFM table. The first row is the header row; cells
+// spanning several columns are followed by empty cells.
+func (c *markdownConverter) table(n *html.Node) string {
+	var rows [][]string
+	for _, tr := range domFindAll(n, func(d *html.Node) bool { return isTag(d, "tr") }) {
+		// Skip rows of nested tables
+		if owner := closestAncestor(tr, "table"); owner != n {
+			continue
+		}
+		var row []string
+		for cell := tr.FirstChild; cell != nil; cell = cell.NextSibling {
+			if !isTag(cell, "td", "th") {
+				continue
+			}
+			text := strings.ReplaceAll(c.paragraph(c.inline(cell)), "  \n", " ")
+			text = strings.ReplaceAll(strings.ReplaceAll(text, "\n", " "), "|", `\|`)
+			row = append(row, text)
+			if span, err := strconv.Atoi(domAttr(cell, "colspan")); err == nil {
+				for i := 1; i < span && i < 100; i++ {
+					row = append(row, "")
+				}
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	var lines []string
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", width))
+		}
+	}
+
+	var out []string
+	if caption := domFind(n, func(d *html.Node) bool { return isTag(d, "caption") }); caption != nil {
+		if text := c.paragraph(c.inline(caption)); text != "" {
+			out = append(out, text, "")
+		}
+	}
+	return strings.Join(append(out, lines...), "\n")
+}
+
+// inline renders n as inline Markdown. Line breaks are kept as "\n"; other
+// whitespace is collapsed by paragraph.
+func (c *markdownConverter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return escapeMarkdown(collapseWhitespace(n.Data))
+	case html.ElementNode:
+		if hiddenElements[n.Data] {
+			return ""
+		}
+	default:
+		if n.Type != html.DocumentNode {
+			return ""
+		}
+	}
+
+	switch n.Data {
+	case "br":
+		return "\n"
+	case "img":
+		src := c.resolve(domAttr(n, "src"))
+		if src == "" {
+			return ""
+		}
+		return "![" + escapeMarkdown(domAttr(n, "alt")) + "](" + markdownURL(src) + ")"
+	case "code", "kbd", "samp":
+		if closestAncestor(n, "pre") != nil {
+			return domRawText(n)
+		}
+		return codeSpan(collapseWhitespace(domRawText(n)))
+	}
+
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && blockElements[child.Data] {
+			b.WriteString(" ")
+		}
+		b.WriteString(c.inline(child))
+	}
+	inner := b.String()
+
+	switch n.Data {
+	case "strong", "b":
+		return wrapInline(inner, "**")
+	case "em", "i":
+		return wrapInline(inner, "*")
+	case "del", "s", "strike":
+		return wrapInline(inner, "~~")
+	case "a":
+		return c.link(n, inner)
+	}
+	return inner
+}
+
+// link renders an anchor with its href resolved to an absolute URL
+func (c *markdownConverter) link(n *html.Node, text string) string {
+	href := strings.TrimSpace(domAttr(n, "href"))
+	if href == "" || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return text
+	}
+	href = c.resolve(href)
+
+	label := strings.TrimSpace(text)
+	if label == "" {
+		label = escapeMarkdown(href)
+	}
+
+	dest := markdownURL(href)
+	if title := domAttr(n, "title"); title != "" {
+		dest += ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+	}
+
+	leading, trailing := surroundingSpace(text)
+	return leading + "[" + label + "](" + dest + ")" + trailing
+}
+
+func (c *markdownConverter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || c.base == nil {
+		return ref
+	}
+	u, err := resolveURL(c.base, ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// paragraph normalizes collected inline Markdown into a paragraph: spaces are
+// collapsed, line breaks become hard breaks and leading characters that would
+// start a different block are escaped
+func (c *markdownConverter) paragraph(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = normalizeSpace(line); line != "" {
+			lines = append(lines, escapeBlockStart(line))
+		}
+	}
+	return strings.Join(lines, "  \n")
+}
+
+// escapeBlockStart escapes characters at the start of a line that would
+// otherwise begin a heading, quote, list or thematic break
+func escapeBlockStart(line string) string {
+	switch {
+	case strings.HasPrefix(line, "#"), strings.HasPrefix(line, ">"),
+		strings.HasPrefix(line, "- "), strings.HasPrefix(line, "+ "),
+		strings.HasPrefix(line, "="), line == "-" || strings.HasPrefix(line, "---"):
+		return `\` + line
+	}
+	if m := orderedMarkerRegex.FindStringSubmatch(line); m != nil {
+		return m[1] + `\` + line[len(m[1]):]
+	}
+	return line
+}
+
+// escapeMarkdown escapes characters that have inline meaning in Markdown.
+// Underscores inside words are left alone since they never mark emphasis.
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\', '*', '`', '[', ']', '<':
+			b.WriteByte('\\')
+		case '_':
+			if !isWordByte(s, i-1) || !isWordByte(s, i+1) {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isWordByte(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// collapseWhitespace turns every run of whitespace into a single space,
+// keeping leading and trailing spaces so adjacent inline elements stay apart
+func collapseWhitespace(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s == "" {
+			return ""
+		}
+		return " "
+	}
+	out := strings.Join(fields, " ")
+	leading, trailing := surroundingSpace(s)
+	if leading != "" {
+		out = " " + out
+	}
+	if trailing != "" {
+		out += " "
+	}
+	return out
+}
+
+// surroundingSpace returns a single space for leading and trailing
+// whitespace of s
+func surroundingSpace(s string) (string, string) {
+	var leading, trailing string
+	if strings.TrimLeft(s, " \t\r\n\f") != s {
+		leading = " "
+	}
+	if strings.TrimRight(s, " \t\r\n\f") != s {
+		trailing = " "
+	}
+	return leading, trailing
+}
+
+// wrapInline wraps the trimmed inner text in a delimiter, keeping the
+// surrounding whitespace outside it
+func wrapInline(inner, delim string) string {
+	text := strings.TrimSpace(inner)
+	if text == "" {
+		return inner
+	}
+	leading, trailing := surroundingSpace(inner)
+	return leading + delim + text + delim + trailing
+}
+
+// codeSpan wraps text in enough backticks to contain any backtick runs
+func codeSpan(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	fence := "`"
+	for _, run := range backtickRunRegex.FindAllString(text, -1) {
+		if len(run) >= len(fence) {
+			fence = strings.Repeat("`", len(run)+1)
+		}
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + text + fence
+}
+
+// markdownURL wraps link destinations containing spaces or parentheses in
+// angle brackets
+func markdownURL(u string) string {
+	if strings.ContainsAny(u, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(u) + ">"
+	}
+	return u
+}
+
+// prefixLines prefixes every line of s; empty lines get emptyPrefix
+func prefixLines(s, prefix, emptyPrefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// documentBaseURL applies the document's <base href>, if any, to the URL it
+// was fetched from
+func documentBaseURL(doc *html.Node, fetched *url.URL) *url.URL {
+	base := domFind(doc, func(n *html.Node) bool { return isTag(n, "base") && domAttr(n, "href") != "" })
+	if base == nil || fetched == nil {
+		return fetched
+	}
+	if u, err := resolveURL(fetched, domAttr(base, "href")); err == nil {
+		return u
+	}
+	return fetched
+}
+
+// closestAncestor returns the nearest ancestor of n with the given tag
+func closestAncestor(n *html.Node, tag string) *html.Node {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if isTag(p, tag) {
+			return p
+		}
+	}
+	return nil
+}
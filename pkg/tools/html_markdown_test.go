@@ -0,0 +1,163 @@
+// ABOUTME: Unit tests for HTML to Markdown conversion
+// ABOUTME: Tests headings, emphasis, lists, links, code, tables and fetch_webpage markdown output
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	t.Parallel()
+
+	base, _ := url.Parse("https://example.com/docs/page.html")
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "headings and paragraphs",
+			html: "<h1>Title</h1><p>First  paragraph\nwraps.</p><h3>Sub</h3><p>Second</p>",
+			want: "# Title\n\nFirst paragraph wraps.\n\n### Sub\n\nSecond",
+		},
+		{
+			name: "emphasis",
+			html: "<p>Some <strong>bold</strong>, <em>italic </em>and <del>old</del> text</p>",
+			want: "Some **bold**, *italic* and ~~old~~ text",
+		},
+		{
+			name: "links resolved to absolute URLs",
+			html: `<p>See <a href="../guide.html" title="Guide">the guide</a> and <a href="https://other.org/">other</a>.</p>`,
+			want: `See [the guide](https://example.com/guide.html "Guide") and [other](https://other.org/).`,
+		},
+		{
+			name: "images",
+			html: `<p><img src="/img/logo.png" alt="Logo"></p>`,
+			want: "![Logo](https://example.com/img/logo.png)",
+		},
+		{
+			name: "unordered and nested lists",
+			html: "<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li></ul>",
+			want: "- One\n- Two\n  - Nested",
+		},
+		{
+			name: "ordered list with start",
+			html: `<ol start="3"><li>Three</li><li>Four</li></ol>`,
+			want: "3. Three\n4. Four",
+		},
+		{
+			name: "inline code and code block",
+			html: "<p>Run <code>go test</code>:</p><pre><code class=\"language-go\">func main() {\n\tfmt.Println(\"hi\")\n}</code></pre>",
+			want: "Run `go test`:\n\n```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```",
+		},
+		{
+			name: "blockquote",
+			html: "<blockquote><p>Quoted</p><p>Twice</p></blockquote>",
+			want: "> Quoted\n>\n> Twice",
+		},
+		{
+			name: "gfm table",
+			html: "<table><thead><tr><th>Name</th><th>Value</th></tr></thead><tbody><tr><td>a|b</td><td><b>1</b></td></tr><tr><td colspan=\"2\">wide</td></tr></tbody></table>",
+			want: "| Name | Value |\n| --- | --- |\n| a\\|b | **1** |\n| wide |  |",
+		},
+		{
+			name: "escaping",
+			html: "<p># not a heading, 2*3 = [x] and snake_case</p>",
+			want: `\# not a heading, 2\*3 = \[x\] and snake_case`,
+		},
+		{
+			name: "line breaks and hidden elements",
+			html: "<p>Line one<br>Line two</p><script>alert(1)</script>",
+			want: "Line one  \nLine two",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseHTML(tt.html)
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := htmlToMarkdown(doc, base); got != tt.want {
+				t.Errorf("Unexpected markdown:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchWebPageHandler_Markdown(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, sampleHTML)
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{
+		URL:          server.URL,
+		OutputFormat: "markdown",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"# Welcome to the Test Page",
+		"[a link](https://example.com)",
+		"[relative link](" + server.URL + "/relative/path)",
+		"![Local Image](" + server.URL + "/images/local.png)",
+	} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", want, result.Content)
+		}
+	}
+	if result.Title != "Test Page" {
+		t.Errorf("Expected title 'Test Page', got %q", result.Title)
+	}
+}
+
+func TestFetchWebPageHandler_ArticleMarkdown(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, sampleArticleHTML)
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{
+		URL:          server.URL,
+		ExtractMode:  "article",
+		OutputFormat: "markdown",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(result.Content, "# Rivers Return to the Valley") {
+		t.Errorf("Expected article heading in markdown, got:\n%s", result.Content)
+	}
+	if strings.Contains(result.Content, "Trending") {
+		t.Errorf("Expected sidebar to be removed, got:\n%s", result.Content)
+	}
+	if result.Article == nil {
+		t.Error("Expected article info")
+	}
+}
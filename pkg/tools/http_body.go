@@ -0,0 +1,169 @@
+// ABOUTME: Response size limits and content-type checks for tool requests
+// ABOUTME: Caps how much of a body is read, reports truncation, refuses binary content and streams XML elements
+
+package tools
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxResponseSize caps how much of a response body a tool reads
+// unless configured with WithMaxResponseSize
+const DefaultMaxResponseSize int64 = 10 * 1024 * 1024
+
+// maxErrorBodySize caps how much of an error response is quoted in an error
+const maxErrorBodySize = 4096
+
+// DefaultContentTypes are the media types the web tools read unless
+// configured with WithAllowedContentTypes. "type/*" matches any subtype and
+// "*+suffix" any structured syntax suffix.
+var DefaultContentTypes = []string{
+	"text/*",
+	"application/xhtml+xml",
+	"application/xml",
+	"application/json",
+	"application/javascript",
+	"application/x-javascript",
+	"*+xml",
+	"*+json",
+}
+
+// UnsupportedContentTypeError is returned when a response has a media type
+// the tool is not allowed to read, such as an image or archive
+type UnsupportedContentTypeError struct {
+	URL         string
+	ContentType string
+}
+
+func (e *UnsupportedContentTypeError) Error() string {
+	return fmt.Sprintf("refusing %s content from %s", e.ContentType, e.URL)
+}
+
+// ResponseTooLargeError is returned when a response that must be read whole,
+// such as an API's JSON document, exceeds the size limit
+type ResponseTooLargeError struct {
+	URL   string
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response from %s exceeds %d bytes", e.URL, e.Limit)
+}
+
+// cappedReader reads at most limit bytes and then reports io.EOF, noting
+// whether the underlying reader had more to give
+type cappedReader struct {
+	r         io.Reader
+	remaining int64
+	truncated bool
+}
+
+func newCappedReader(r io.Reader, limit int64) *cappedReader {
+	return &cappedReader{r: r, remaining: limit}
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		if !c.truncated {
+			var probe [1]byte
+			if n, _ := io.ReadFull(c.r, probe[:]); n > 0 {
+				c.truncated = true
+			}
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	return n, err
+}
+
+// readBody reads at most limit bytes of r, reporting whether the rest was cut
+func readBody(r io.Reader, limit int64) ([]byte, bool, error) {
+	capped := newCappedReader(r, limit)
+	body, err := io.ReadAll(capped)
+	return body, capped.truncated, err
+}
+
+// readWholeBody reads a response that is useless when cut short, failing
+// with a *ResponseTooLargeError when it exceeds limit
+func readWholeBody(resp *http.Response, limit int64) ([]byte, error) {
+	body, truncated, err := readBody(resp.Body, limit)
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		return nil, &ResponseTooLargeError{URL: resp.Request.URL.String(), Limit: limit}
+	}
+	return body, nil
+}
+
+// contentTypeAllowed reports whether the media type of a Content-Type header
+// matches one of the patterns. A missing or malformed header is allowed, as
+// servers often omit it for text.
+func contentTypeAllowed(contentType string, patterns []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return true
+	}
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == mediaType:
+			return true
+		case strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*")):
+			return true
+		case strings.HasPrefix(pattern, "*+") && strings.HasSuffix(mediaType, pattern[1:]):
+			return true
+		}
+	}
+	return false
+}
+
+// responseLimit returns the configured maximum body size or the given default
+func (c *toolConfig) responseLimit(defaultLimit int64) int64 {
+	if c.maxResponseSize > 0 {
+		return c.maxResponseSize
+	}
+	return defaultLimit
+}
+
+// checkContentType returns an *UnsupportedContentTypeError when the response
+// has a media type outside the configured allowlist
+func (c *toolConfig) checkContentType(resp *http.Response) error {
+	patterns := c.contentTypes
+	if patterns == nil {
+		patterns = DefaultContentTypes
+	}
+	if contentType := resp.Header.Get("Content-Type"); !contentTypeAllowed(contentType, patterns) {
+		return &UnsupportedContentTypeError{URL: resp.Request.URL.String(), ContentType: contentType}
+	}
+	return nil
+}
+
+// streamXMLElements calls fn for every element with the given local name,
+// wherever it appears, so large documents are never held in memory whole.
+// fn must consume the element, e.g. with DecodeElement.
+func streamXMLElements(decoder *xml.Decoder, local string, fn func(start *xml.StartElement) error) error {
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == local {
+			if err := fn(&start); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// ABOUTME: Unit tests for response size limits and content-type checks
+// ABOUTME: Tests capped reads, truncation flags, binary handling and streaming feed and PubMed parsing
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		body      string
+		limit     int64
+		want      string
+		truncated bool
+	}{
+		{body: "hello", limit: 10, want: "hello"},
+		{body: "hello", limit: 5, want: "hello"},
+		{body: "hello world", limit: 5, want: "hello", truncated: true},
+		{body: "", limit: 0, want: ""},
+	}
+	for _, tt := range tests {
+		got, truncated, err := readBody(strings.NewReader(tt.body), tt.limit)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(got) != tt.want || truncated != tt.truncated {
+			t.Errorf("readBody(%q, %d) = %q, %v; want %q, %v", tt.body, tt.limit, got, truncated, tt.want, tt.truncated)
+		}
+	}
+}
+
+func TestContentTypeAllowed(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]bool{
+		"text/html; charset=utf-8":  true,
+		"text/plain":                true,
+		"application/rss+xml":       true,
+		"application/ld+json":       true,
+		"application/xhtml+xml":     true,
+		"APPLICATION/JSON":          true,
+		"":                          true,
+		"application/pdf":           false,
+		"image/png":                 false,
+		"application/octet-stream":  false,
+		"application/zip":           false,
+		"video/mp4; codecs=avc1":    false,
+		"application/vnd.ms-excel":  false,
+		"application/x-gzip; q=0.9": false,
+	}
+	for contentType, want := range tests {
+		if got := contentTypeAllowed(contentType, DefaultContentTypes); got != want {
+			t.Errorf("contentTypeAllowed(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+	if !contentTypeAllowed("application/pdf", []string{"application/pdf"}) {
+		t.Error("Expected exact pattern to match")
+	}
+}
+
+func TestFetchWebPageHandler_ResponseLimits(t *testing.T) {
+	t.Parallel()
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.NotFound(w, r)
+		case "/logo.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(png)
+		case "/long":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<html><head><title>Long</title></head><body>")
+			for i := range 2000 {
+				fmt.Fprintf(w, "<p>Paragraph %d</p>", i)
+			}
+			fmt.Fprint(w, "</body></html>")
+		}
+	}))
+	defer server.Close()
+
+	t.Run("binary refused", func(t *testing.T) {
+		_, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/logo.png"})
+		var typeErr *UnsupportedContentTypeError
+		if !errors.As(err, &typeErr) || typeErr.ContentType != "image/png" {
+			t.Errorf("Expected *UnsupportedContentTypeError, got %v", err)
+		}
+	})
+
+	t.Run("binary allowed", func(t *testing.T) {
+		result, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{
+			URL:         server.URL + "/logo.png",
+			AllowBinary: true,
+			ExtractText: true,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(result.Content)
+		if result.Encoding != "base64" || err != nil || !bytes.Equal(decoded, png) {
+			t.Errorf("Expected base64 PNG content, got encoding %q content %q", result.Encoding, result.Content)
+		}
+	})
+
+	t.Run("allowed content types", func(t *testing.T) {
+		cfg := newToolConfig(WithAllowedContentTypes("image/*"))
+		if _, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/logo.png"}); err != nil {
+			t.Errorf("Expected image/* to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		cfg := newToolConfig(WithMaxResponseSize(1024))
+		result, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/long", ExtractText: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.Truncated || result.Title != "Long" || !strings.Contains(result.Content, "Paragraph 3") || strings.Contains(result.Content, "Paragraph 1999") {
+			t.Errorf("Expected truncated page with its start, got truncated=%v title=%q content=%q", result.Truncated, result.Title, result.Content)
+		}
+
+		links, err := cfg.extractLinksHandler(context.Background(), ExtractLinksParams{URL: server.URL + "/long"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !links.Truncated {
+			t.Error("Expected extract_links result to be flagged as truncated")
+		}
+
+		full, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/long", ExtractText: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if full.Truncated || !strings.Contains(full.Content, "Paragraph 1999") {
+			t.Errorf("Expected complete page under the default limit, got truncated=%v", full.Truncated)
+		}
+	})
+}
+
+// endlessFeed writes a never-ending RSS feed, one item at a time
+type endlessFeed struct {
+	n       int
+	pending []byte
+}
+
+func (f *endlessFeed) Read(p []byte) (int, error) {
+	if len(f.pending) == 0 {
+		if f.n == 0 {
+			f.pending = []byte(`<?xml version="1.0" encoding="ISO-8859-1"?><rss version="2.0"><channel><title>Caf` + "\xe9" + `</title><atom:link xmlns:atom="http://www.w3.org/2005/Atom" href="https://example.com/feed" rel="self"/><link>https://example.com/</link>`)
+		}
+		f.pending = fmt.Appendf(f.pending, "<item><title>Item %d</title><link>https://example.com/%d</link></item>", f.n, f.n)
+		f.n++
+	}
+	n := copy(p, f.pending)
+	f.pending = f.pending[n:]
+	return n, nil
+}
+
+func TestFetchRSSFeedHandler_Streaming(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = io.Copy(w, io.LimitReader(&endlessFeed{}, 50<<20))
+	}))
+	defer server.Close()
+
+	t.Run("limit stops reading", func(t *testing.T) {
+		result, err := newToolConfig().fetchRSSFeedHandler(context.Background(), FetchRSSFeedParams{URL: server.URL, Limit: 3})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Items) != 3 || result.Items[2].Title != "Item 2" || result.Truncated {
+			t.Errorf("Expected first 3 items without truncation, got %d (truncated=%v)", len(result.Items), result.Truncated)
+		}
+		if result.Title != "Café" || result.Link != "https://example.com/" {
+			t.Errorf("Unexpected channel fields: title %q link %q", result.Title, result.Link)
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		cfg := newToolConfig(WithMaxResponseSize(4096))
+		result, err := cfg.fetchRSSFeedHandler(context.Background(), FetchRSSFeedParams{URL: server.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.Truncated || len(result.Items) == 0 || len(result.Items) > 100 {
+			t.Errorf("Expected a truncated feed with the items before the cut, got %d (truncated=%v)", len(result.Items), result.Truncated)
+		}
+	})
+
+	t.Run("not rss", func(t *testing.T) {
+		atom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `<feed xmlns="http://www.w3.org/2005/Atom"></feed>`)
+		}))
+		defer atom.Close()
+
+		_, err := newToolConfig().fetchRSSFeedHandler(context.Background(), FetchRSSFeedParams{URL: atom.URL})
+		if err == nil || !strings.Contains(err.Error(), "expected element type <rss> but have <feed>") {
+			t.Errorf("Expected root element error, got %v", err)
+		}
+	})
+}
+
+func TestResearchPaperAPIHandler_ResponseLimit(t *testing.T) {
+	t.Parallel()
+
+	const article = `<PubmedArticle><MedlineCitation><PMID>%d</PMID><Article><ArticleTitle>Study %d</ArticleTitle></Article></MedlineCitation></PubmedArticle>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "esearch.fcgi") {
+			fmt.Fprint(w, `{"esearchresult": {"idlist": ["1", "2"]}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprint(w, `<?xml version="1.0"?><PubmedArticleSet>`)
+		for i := range 200 {
+			fmt.Fprintf(w, article, i, i)
+		}
+		fmt.Fprint(w, `</PubmedArticleSet>`)
+	}))
+	defer server.Close()
+
+	cfg := newToolConfig(
+		WithProviderBaseURL("pubmed", server.URL+"/"),
+		WithMaxResponseSize(2048),
+	)
+	result, err := cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{
+		Query:     "study",
+		Providers: []string{"pubmed"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Providers) != 1 {
+		t.Fatalf("Expected one provider, got %+v", result.Providers)
+	}
+	info := result.Providers[0]
+	if !info.Truncated || info.Error != "" || info.ResultCount == 0 || info.ResultCount >= 200 {
+		t.Errorf("Expected truncated PubMed results, got %+v", info)
+	}
+}
@@ -0,0 +1,335 @@
+// ABOUTME: Optional on-disk HTTP response cache for web and API tools
+// ABOUTME: Caches responses by method, URL and relevant headers, honoring Cache-Control or per-tool TTLs
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache status values reported in tool results
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// cacheKeyHeaders are the request headers that change the response and
+// therefore become part of the cache key. Values are hashed, never stored.
+var cacheKeyHeaders = []string{
+	"Accept",
+	"Accept-Language",
+	"Authorization",
+	"Cookie",
+	"X-Api-Key",
+	"X-Subscription-Token",
+}
+
+// CachedResponse is a stored HTTP response
+type CachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+}
+
+// ResponseCache stores HTTP responses by key
+type ResponseCache interface {
+	// Get returns the entry for key if present and not expired
+	Get(key string) (*CachedResponse, bool)
+	// Set stores an entry under key
+	Set(key string, entry *CachedResponse) error
+}
+
+// FileCache is a filesystem-backed ResponseCache. Each entry is a JSON file
+// named after the SHA-256 of its key.
+type FileCache struct {
+	Dir        string
+	DefaultTTL time.Duration // Used when neither the tool nor the response sets a lifetime
+}
+
+// NewFileCache creates a file cache in dir, creating the directory if needed
+func NewFileCache(dir string, defaultTTL time.Duration) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	return &FileCache{Dir: dir, DefaultTTL: defaultTTL}, nil
+}
+
+// Get implements ResponseCache. Expired entries are removed.
+func (c *FileCache) Get(key string) (*CachedResponse, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Set implements ResponseCache. Entries are written atomically.
+func (c *FileCache) Set(key string, entry *CachedResponse) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating cache file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache file: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// path shards entries into subdirectories by the first two hex digits
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.Dir, name[:2], name+".json")
+}
+
+// defaultTTL returns the cache's default lifetime, if it has one
+func defaultTTL(cache ResponseCache) time.Duration {
+	if fc, ok := cache.(*FileCache); ok {
+		return fc.DefaultTTL
+	}
+	return 0
+}
+
+// maxCachedBodySize is the largest response body the cache stores
+const maxCachedBodySize = 32 * 1024 * 1024
+
+// cachingTransport serves responses from a ResponseCache and stores
+// successful responses in it. Only GET and HEAD requests are cached, and
+// requests sent with "Cache-Control: no-cache" neither read nor fill it.
+type cachingTransport struct {
+	next  http.RoundTripper
+	cache ResponseCache
+	ttl   time.Duration // Per-tool override; ignores response cache headers when set
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if (req.Method != "GET" && req.Method != "HEAD") || strings.Contains(req.Header.Get("Cache-Control"), "no-cache") {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil {
+			recordCacheStatus(req.Context(), CacheMiss)
+		}
+		return resp, err
+	}
+
+	key, err := cacheKey(req)
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := t.cache.Get(key); ok {
+		recordCacheStatus(req.Context(), CacheHit)
+		return entry.response(req), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	recordCacheStatus(req.Context(), CacheMiss)
+
+	lifetime := t.lifetime(resp)
+	if resp.StatusCode != http.StatusOK || lifetime <= 0 || resp.ContentLength > maxCachedBodySize {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedBodySize {
+		// Too large to keep; pass the body on so the caller's own limit applies
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	now := time.Now()
+	// A failed write only costs a future cache miss
+	_ = t.cache.Set(key, &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		StoredAt:   now,
+		ExpiresAt:  now.Add(lifetime),
+	})
+
+	return resp, nil
+}
+
+// lifetime returns how long a response may be cached. Responses that set
+// cookies or are marked private belong to one session and are never stored,
+// as the cache may be shared, whatever the tool's TTL.
+func (t *cachingTransport) lifetime(resp *http.Response) time.Duration {
+	cacheControl := strings.ToLower(resp.Header.Get("Cache-Control"))
+	if strings.Contains(cacheControl, "no-store") || strings.Contains(cacheControl, "private") {
+		return 0
+	}
+	if len(resp.Header.Values("Set-Cookie")) > 0 {
+		return 0
+	}
+	if t.ttl > 0 {
+		return t.ttl
+	}
+	if strings.Contains(cacheControl, "no-cache") {
+		return 0
+	}
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name == "max-age" {
+			secs, err := strconv.Atoi(value)
+			if err != nil {
+				return 0
+			}
+			return time.Duration(secs) * time.Second
+		}
+	}
+	if expires := resp.Header.Get("Expires"); expires != "" {
+		when, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		return time.Until(when)
+	}
+	return defaultTTL(t.cache)
+}
+
+// response rebuilds an http.Response from a cache entry
+func (e *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheKey builds the cache key from the method, URL, relevant headers and,
+// for requests with a body, a hash of the body
+func cacheKey(req *http.Request) (string, error) {
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteString(" ")
+	b.WriteString(req.URL.String())
+
+	for _, name := range cacheKeyHeaders {
+		if value := req.Header.Get(name); value != "" {
+			sum := sha256.Sum256([]byte(value))
+			fmt.Fprintf(&b, "\n%s: %x", name, sum[:8])
+		}
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return "", errors.New("cannot cache request with non-replayable body")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "\nbody: %x", h.Sum(nil))
+	}
+
+	return b.String(), nil
+}
+
+// cacheRecorder collects the cache status of every request made with a context
+type cacheRecorder struct {
+	mu     sync.Mutex
+	hits   int
+	misses int
+}
+
+type cacheRecorderKey struct{}
+
+// withCacheRecorder returns a context whose requests report their cache status
+func withCacheRecorder(ctx context.Context) (context.Context, *cacheRecorder) {
+	rec := &cacheRecorder{}
+	return context.WithValue(ctx, cacheRecorderKey{}, rec), rec
+}
+
+func recordCacheStatus(ctx context.Context, status string) {
+	rec, ok := ctx.Value(cacheRecorderKey{}).(*cacheRecorder)
+	if !ok {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if status == CacheHit {
+		rec.hits++
+	} else {
+		rec.misses++
+	}
+}
+
+// status returns "hit" when every request was served from cache, "miss" when
+// any request went to the network, and "" when no cache was involved
+func (r *cacheRecorder) status() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case r.misses > 0:
+		return CacheMiss
+	case r.hits > 0:
+		return CacheHit
+	default:
+		return ""
+	}
+}
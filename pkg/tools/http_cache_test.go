@@ -0,0 +1,324 @@
+// ABOUTME: Unit tests for the on-disk HTTP response cache
+// ABOUTME: Tests hits and misses, Cache-Control handling, TTL overrides and cache keys
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestFileCache(t *testing.T) *FileCache {
+	t.Helper()
+	cache, err := NewFileCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	return cache
+}
+
+func TestFetchWebPageHandler_Cache(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, sampleHTML)
+	}))
+	defer server.Close()
+
+	cfg := newToolConfig(WithCache(newTestFileCache(t), 0))
+	params := FetchWebPageParams{URL: server.URL, ExtractText: true, FollowRedirects: true}
+
+	first, err := cfg.fetchWebPageHandler(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.Cache != CacheMiss {
+		t.Errorf("Expected first fetch to be a miss, got %q", first.Cache)
+	}
+
+	second, err := cfg.fetchWebPageHandler(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if second.Cache != CacheHit {
+		t.Errorf("Expected second fetch to be a hit, got %q", second.Cache)
+	}
+	if second.Title != first.Title || second.Content != first.Content {
+		t.Error("Expected cached result to match the original")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("Expected 1 request to the server, got %d", n)
+	}
+}
+
+func TestFetchWebPageHandler_NoCacheConfigured(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, sampleHTML)
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Cache != "" {
+		t.Errorf("Expected no cache status, got %q", result.Cache)
+	}
+}
+
+func TestCachingTransport_CacheControl(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		cacheControl string
+		ttl          time.Duration
+		wantRequests int32
+	}{
+		{"max-age", "max-age=300", 0, 1},
+		{"no-store", "no-store", 0, 2},
+		{"no-cache", "no-cache", 0, 2},
+		{"max-age zero", "max-age=0", 0, 2},
+		{"ttl overrides no-cache", "no-cache", time.Minute, 1},
+		{"ttl never overrides no-store", "no-store", time.Minute, 2},
+		{"private", "private, max-age=300", 0, 2},
+		{"ttl never overrides private", "private", time.Minute, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				w.Header().Set("Cache-Control", tt.cacheControl)
+				fmt.Fprint(w, "body")
+			}))
+			defer server.Close()
+
+			client := newToolConfig(WithCache(newTestFileCache(t), tt.ttl)).client(5 * time.Second)
+			for i := 0; i < 2; i++ {
+				resp, err := client.Get(server.URL)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				resp.Body.Close()
+			}
+
+			if n := atomic.LoadInt32(&requests); n != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, n)
+			}
+		})
+	}
+}
+
+func TestCachingTransport_SetCookieNotReplayed(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the first visitor gets a session
+		if atomic.AddInt32(&requests, 1) == 1 {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "first-visitor"})
+		}
+		w.Header().Set("Cache-Control", "max-age=300")
+		fmt.Fprint(w, "body")
+	}))
+	defer server.Close()
+
+	client := newToolConfig(WithCache(newTestFileCache(t), time.Minute)).client(5 * time.Second)
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if i > 0 && resp.Header.Get("Set-Cookie") != "" {
+			t.Errorf("Request %d: expected no Set-Cookie, got %q", i+1, resp.Header.Get("Set-Cookie"))
+		}
+	}
+
+	// The response with the cookie was not stored; the next one was
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("Expected 2 requests, got %d", n)
+	}
+}
+
+func TestCachingTransport_ErrorsNotCached(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client := newToolConfig(WithCache(newTestFileCache(t), time.Minute)).client(5 * time.Second)
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("Expected 2 requests, got %d", n)
+	}
+}
+
+func TestCachingTransport_UncachedRequests(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Cache-Control", "max-age=300")
+		fmt.Fprint(w, "body")
+	}))
+	defer server.Close()
+
+	client := newToolConfig(WithCache(newTestFileCache(t), time.Minute)).client(5 * time.Second)
+	do := func(method string, header http.Header) {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL, strings.NewReader("q=a"))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header = header
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// POST responses are neither stored nor served from cache
+	do("POST", http.Header{})
+	do("POST", http.Header{})
+	// A no-cache request does not store its response for later requests
+	do("GET", http.Header{"Cache-Control": {"no-cache"}})
+	do("GET", http.Header{})
+	do("GET", http.Header{})
+
+	if n := atomic.LoadInt32(&requests); n != 4 {
+		t.Errorf("Expected 4 requests, got %d", n)
+	}
+}
+
+func TestFileCache_Expiry(t *testing.T) {
+	t.Parallel()
+
+	cache := newTestFileCache(t)
+	entry := &CachedResponse{
+		StatusCode: http.StatusOK,
+		Body:       []byte("stale"),
+		StoredAt:   time.Now().Add(-2 * time.Hour),
+		ExpiresAt:  time.Now().Add(-time.Hour),
+	}
+	if err := cache.Set("key", entry); err != nil {
+		t.Fatalf("Failed to store entry: %v", err)
+	}
+
+	if _, ok := cache.Get("key"); ok {
+		t.Error("Expected expired entry to be a miss")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	newReq := func(method, url, body string, headers map[string]string) *http.Request {
+		var req *http.Request
+		if body == "" {
+			req = httptest.NewRequest(method, url, nil)
+		} else {
+			req, _ = http.NewRequest(method, url, strings.NewReader(body))
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+	key := func(req *http.Request) string {
+		k, err := cacheKey(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return k
+	}
+
+	base := key(newReq("GET", "https://example.com/a", "", nil))
+
+	if key(newReq("GET", "https://example.com/a", "", map[string]string{"User-Agent": "other"})) != base {
+		t.Error("Expected User-Agent to be ignored")
+	}
+	if key(newReq("GET", "https://example.com/a", "", map[string]string{"Accept-Language": "de"})) == base {
+		t.Error("Expected Accept-Language to change the key")
+	}
+	if key(newReq("HEAD", "https://example.com/a", "", nil)) == base {
+		t.Error("Expected method to change the key")
+	}
+	if key(newReq("POST", "https://example.com/a", `{"q":"a"}`, nil)) == key(newReq("POST", "https://example.com/a", `{"q":"b"}`, nil)) {
+		t.Error("Expected request body to change the key")
+	}
+
+	if key(newReq("GET", "https://example.com/a", "", map[string]string{"x-api-key": "one"})) == key(newReq("GET", "https://example.com/a", "", map[string]string{"x-api-key": "two"})) {
+		t.Error("Expected x-api-key to change the key")
+	}
+
+	token := key(newReq("GET", "https://example.com/a", "", map[string]string{"X-Subscription-Token": "secret"}))
+	if strings.Contains(token, "secret") {
+		t.Error("Expected header values to be hashed in the key")
+	}
+}
+
+func TestResearchPaperAPIHandler_ProviderCache(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<entry>
+		<id>http://arxiv.org/abs/2101.00001v1</id>
+		<title>Cached Paper</title>
+		<published>2024-01-15T00:00:00Z</published>
+	</entry>
+</feed>`))
+	}))
+	defer server.Close()
+
+	cfg := newToolConfig(
+		WithProviderBaseURL("arxiv", server.URL+"/api/query"),
+		WithCache(newTestFileCache(t), time.Hour),
+	)
+	params := ResearchPaperAPIParams{Query: "test", Providers: []string{"arxiv"}}
+
+	for _, want := range []string{CacheMiss, CacheHit} {
+		result, err := cfg.researchPaperAPIHandler(context.Background(), params)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Providers) != 1 || result.Providers[0].Cache != want {
+			t.Errorf("Expected provider cache status %q, got %+v", want, result.Providers)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// ABOUTME: Shared HTTP transport used by every tool for outbound requests
+// ABOUTME: Provides retries with backoff, Retry-After handling, per-host rate limits and connection reuse
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RetryPolicy configures how failed requests are retried
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt (0 disables retries)
+	BaseDelay  time.Duration // Initial backoff delay, doubled on every retry
+	MaxDelay   time.Duration // Upper bound for a single wait, including Retry-After
+	RetryOn    []int         // Status codes that trigger a retry
+}
+
+// DefaultRetryPolicy returns the retry policy used by the shared transport
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+		RetryOn: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// RateLimit describes a token bucket: Requests tokens are added every Interval,
+// up to Burst tokens. A zero Burst defaults to Requests.
+type RateLimit struct {
+	Requests int
+	Interval time.Duration
+	Burst    int
+}
+
+// TransportOption configures a Transport
+type TransportOption func(*Transport)
+
+// WithRetryPolicy sets the retry policy of the transport
+func WithRetryPolicy(policy RetryPolicy) TransportOption {
+	return func(t *Transport) {
+		t.retry = policy
+	}
+}
+
+// WithHostRateLimit limits the request rate to a single host
+func WithHostRateLimit(host string, limit RateLimit) TransportOption {
+	return func(t *Transport) {
+		t.limits[strings.ToLower(host)] = limit
+	}
+}
+
+// WithBaseTransport sets the round tripper that performs the actual requests
+func WithBaseTransport(base http.RoundTripper) TransportOption {
+	return func(t *Transport) {
+		t.base = base
+	}
+}
+
+// Transport is an http.RoundTripper that adds retries and per-host rate limiting
+// on top of a pooled base transport. It is safe for concurrent use.
+type Transport struct {
+	retry RetryPolicy
+
+	mu      sync.Mutex
+	base    http.RoundTripper
+	limits  map[string]RateLimit
+	buckets map[string]*tokenBucket
+}
+
+// NewTransport creates a transport with the default retry policy and no rate limits
+func NewTransport(opts ...TransportOption) *Transport {
+	// The zero configuration reads no files and cannot fail
+	base, _ := NetworkConfig{}.NewBaseTransport()
+
+	t := &Transport{
+		base:    base,
+		retry:   DefaultRetryPolicy(),
+		limits:  make(map[string]RateLimit),
+		buckets: make(map[string]*tokenBucket),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// SetHostRateLimit replaces the rate limit for a host on a live transport
+func (t *Transport) SetHostRateLimit(host string, limit RateLimit) {
+	host = strings.ToLower(host)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits[host] = limit
+	delete(t.buckets, host)
+}
+
+// SetBaseTransport replaces the round tripper of a live transport, e.g. to
+// apply a new NetworkConfig. Idle connections of the previous one are closed.
+func (t *Transport) SetBaseTransport(base http.RoundTripper) {
+	t.mu.Lock()
+	old := t.base
+	t.base = base
+	t.mu.Unlock()
+
+	if closer, ok := old.(interface{ CloseIdleConnections() }); ok && old != base {
+		closer.CloseIdleConnections()
+	}
+}
+
+// RoundTrip executes the request, waiting for the host's rate limit and
+// retrying according to the retry policy
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	bucket := t.bucketFor(req.URL.Hostname())
+
+	t.mu.Lock()
+	base := t.base
+	t.mu.Unlock()
+
+	// http.Client carries its timeout in the request context as well as the
+	// legacy Cancel channel; relying on the context alone keeps timeout errors
+	// identical to those of a bare http.Transport
+	if req.Cancel != nil {
+		req = req.Clone(ctx)
+		req.Cancel = nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		if bucket != nil {
+			if err := bucket.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptReq := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := base.RoundTrip(attemptReq)
+		if ctx.Err() != nil || attempt >= t.retry.MaxRetries || !t.canRetry(req) {
+			return resp, err
+		}
+
+		var delay time.Duration
+		if err != nil {
+			// Network errors are only retried for idempotent requests
+			if !isIdempotent(req.Method) {
+				return resp, err
+			}
+			if !isTransientError(err) {
+				return resp, err
+			}
+			delay = t.backoff(attempt)
+		} else {
+			if !t.retryableStatus(resp.StatusCode) {
+				return resp, nil
+			}
+			after, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+			// A gateway error may come after the server acted on the request,
+			// so other requests, such as form posts, are only resent when the
+			// server refused them and said when to come back
+			if !isIdempotent(req.Method) && !(hasRetryAfter && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable)) {
+				return resp, nil
+			}
+			delay = t.backoff(attempt)
+			if hasRetryAfter {
+				if after > t.retry.MaxDelay {
+					// The server asked for a longer pause than we are willing to wait
+					return resp, nil
+				}
+				delay = after
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isIdempotent reports whether a request may be sent twice without harm
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// canRetry reports whether the request body can be replayed
+func (t *Transport) canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func (t *Transport) retryableStatus(code int) bool {
+	for _, c := range t.retry.RetryOn {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientError reports whether a network error may succeed on retry
+func isTransientError(err error) bool {
+	var policyErr *URLPolicyError
+	if errors.As(err, &policyErr) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff returns the exponential backoff delay for an attempt with up to 20% jitter
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.retry.BaseDelay << attempt
+	if delay <= 0 || delay > t.retry.MaxDelay {
+		delay = t.retry.MaxDelay
+	}
+	if delay > 0 {
+		delay += time.Duration(rand.Int64N(int64(delay)/5 + 1))
+	}
+	return delay
+}
+
+// bucketFor returns the token bucket for a host, or nil if the host is not limited
+func (t *Transport) bucketFor(host string) *tokenBucket {
+	host = strings.ToLower(host)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if b, ok := t.buckets[host]; ok {
+		return b
+	}
+	limit, ok := t.limits[host]
+	if !ok || limit.Requests <= 0 || limit.Interval <= 0 {
+		return nil
+	}
+	b := newTokenBucket(limit)
+	t.buckets[host] = b
+	return b
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		delay := time.Until(when)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// tokenBucket is a simple token bucket rate limiter
+type tokenBucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	capacity float64
+	rate     float64 // tokens per second
+	last     time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = limit.Requests
+	}
+	return &tokenBucket{
+		tokens:   float64(burst),
+		capacity: float64(burst),
+		rate:     float64(limit.Requests) / limit.Interval.Seconds(),
+		last:     time.Now(),
+	}
+}
+
+// wait blocks until a token is available or the context is done
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// defaultTransport is shared by all tools so connections are reused across calls.
+// arXiv asks clients to make no more than one request every three seconds,
+// Semantic Scholar no more than one per second.
+var defaultTransport = NewTransport(
+	WithHostRateLimit("export.arxiv.org", RateLimit{Requests: 1, Interval: 3 * time.Second}),
+	WithHostRateLimit("api.semanticscholar.org", RateLimit{Requests: 1, Interval: time.Second}),
+)
+
+// DefaultTransport returns the transport shared by all tools
+func DefaultTransport() *Transport {
+	return defaultTransport
+}
+
+// newHTTPClient returns a client with the given timeout backed by the shared transport
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: defaultTransport,
+	}
+}
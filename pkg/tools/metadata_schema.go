@@ -0,0 +1,560 @@
+// ABOUTME: Structured metadata extraction for extract_metadata
+// ABOUTME: Maps JSON-LD, schema.org microdata, Highwire citation_* and Dublin Core tags to typed fields
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ArticleMetadata describes an article, news story or blog post
+type ArticleMetadata struct {
+	Type          string   `json:"type"` // schema.org type, e.g. "NewsArticle"
+	Headline      string   `json:"headline,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	Authors       []string `json:"authors,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateModified  string   `json:"date_modified,omitempty"`
+	Publisher     string   `json:"publisher,omitempty"`
+	Section       string   `json:"section,omitempty"`
+	Keywords      []string `json:"keywords,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	URL           string   `json:"url,omitempty"`
+}
+
+// ScholarlyMetadata describes a paper landing page
+type ScholarlyMetadata struct {
+	Title           string   `json:"title,omitempty"`
+	Authors         []string `json:"authors,omitempty"`
+	PublicationDate string   `json:"publication_date,omitempty"`
+	Journal         string   `json:"journal,omitempty"`
+	Conference      string   `json:"conference,omitempty"`
+	Publisher       string   `json:"publisher,omitempty"`
+	Volume          string   `json:"volume,omitempty"`
+	Issue           string   `json:"issue,omitempty"`
+	FirstPage       string   `json:"first_page,omitempty"`
+	LastPage        string   `json:"last_page,omitempty"`
+	DOI             string   `json:"doi,omitempty"`
+	ISSN            string   `json:"issn,omitempty"`
+	Abstract        string   `json:"abstract,omitempty"`
+	Keywords        []string `json:"keywords,omitempty"`
+	PDFURL          string   `json:"pdf_url,omitempty"`
+}
+
+// OrganizationMetadata describes the organization behind a page
+type OrganizationMetadata struct {
+	Type   string   `json:"type"` // schema.org type, e.g. "NewsMediaOrganization"
+	Name   string   `json:"name,omitempty"`
+	URL    string   `json:"url,omitempty"`
+	Logo   string   `json:"logo,omitempty"`
+	SameAs []string `json:"same_as,omitempty"`
+}
+
+// structuredMetadata collects the typed metadata found on a page
+type structuredMetadata struct {
+	Article      *ArticleMetadata
+	Scholarly    *ScholarlyMetadata
+	Organization *OrganizationMetadata
+	Types        []string // Every schema.org type seen, in document order
+}
+
+var articleTypes = map[string]bool{
+	"Article": true, "NewsArticle": true, "BlogPosting": true, "Report": true,
+	"TechArticle": true, "AnalysisNewsArticle": true, "OpinionNewsArticle": true,
+	"ReportageNewsArticle": true, "ReviewNewsArticle": true, "BackgroundNewsArticle": true,
+	"LiveBlogPosting": true, "SocialMediaPosting": true, "ScholarlyArticle": true,
+}
+
+var organizationTypes = map[string]bool{
+	"Organization": true, "NewsMediaOrganization": true, "Corporation": true,
+	"EducationalOrganization": true, "GovernmentOrganization": true, "NGO": true,
+	"ResearchOrganization": true, "CollegeOrUniversity": true, "LocalBusiness": true,
+}
+
+// extractStructuredMetadata reads JSON-LD blocks, microdata items, Highwire
+// citation_* tags and Dublin Core tags. JSON-LD wins over microdata, which
+// wins over meta tags; later sources only fill fields that are still empty.
+func extractStructuredMetadata(doc *html.Node, base *url.URL) structuredMetadata {
+	var md structuredMetadata
+
+	items := jsonLDItems(doc)
+	items = append(items, microdataItems(doc)...)
+	for _, item := range items {
+		md.addItem(item)
+	}
+
+	metas := metaValues(doc)
+	md.addHighwire(metas)
+	md.addDublinCore(metas)
+
+	if base != nil {
+		resolve := func(ref *string) {
+			if *ref == "" {
+				return
+			}
+			if u, err := resolveURL(base, *ref); err == nil {
+				*ref = u.String()
+			}
+		}
+		if md.Article != nil {
+			resolve(&md.Article.Image)
+			resolve(&md.Article.URL)
+		}
+		if md.Scholarly != nil {
+			resolve(&md.Scholarly.PDFURL)
+		}
+		if md.Organization != nil {
+			resolve(&md.Organization.URL)
+			resolve(&md.Organization.Logo)
+		}
+	}
+	return md
+}
+
+func (md *structuredMetadata) addItem(item map[string]any) {
+	for _, t := range schemaTypes(item) {
+		md.Types = append(md.Types, t)
+
+		switch {
+		case articleTypes[t]:
+			if md.Article == nil {
+				md.Article = &ArticleMetadata{Type: t}
+			}
+			a := md.Article
+			setIfEmpty(&a.Headline, schemaString(item["headline"]), schemaString(item["name"]))
+			setIfEmpty(&a.Description, schemaString(item["description"]))
+			if len(a.Authors) == 0 {
+				a.Authors = schemaNames(item["author"])
+			}
+			setIfEmpty(&a.DatePublished, schemaString(item["datePublished"]))
+			setIfEmpty(&a.DateModified, schemaString(item["dateModified"]))
+			setIfEmpty(&a.Publisher, schemaName(item["publisher"]))
+			setIfEmpty(&a.Section, schemaString(item["articleSection"]))
+			if len(a.Keywords) == 0 {
+				a.Keywords = schemaKeywords(item["keywords"])
+			}
+			setIfEmpty(&a.Image, schemaURL(item["image"]))
+			setIfEmpty(&a.URL, schemaString(item["url"]), schemaString(item["mainEntityOfPage"]))
+
+			if t == "ScholarlyArticle" {
+				if md.Scholarly == nil {
+					md.Scholarly = &ScholarlyMetadata{}
+				}
+				s := md.Scholarly
+				setIfEmpty(&s.Title, a.Headline)
+				if len(s.Authors) == 0 {
+					s.Authors = a.Authors
+				}
+				setIfEmpty(&s.PublicationDate, a.DatePublished)
+				setIfEmpty(&s.Publisher, a.Publisher)
+				setIfEmpty(&s.Abstract, schemaString(item["abstract"]), a.Description)
+				setIfEmpty(&s.Journal, schemaName(item["isPartOf"]))
+				setIfEmpty(&s.DOI, doiFromIdentifier(schemaString(item["identifier"])), doiFromIdentifier(schemaString(item["sameAs"])))
+			}
+
+			// The publisher doubles as the page's organization
+			if publisher, ok := item["publisher"].(map[string]any); ok && md.Organization == nil {
+				md.addOrganization(publisher, "Organization")
+			}
+		case organizationTypes[t]:
+			md.addOrganization(item, t)
+		}
+	}
+}
+
+func (md *structuredMetadata) addOrganization(item map[string]any, t string) {
+	if md.Organization == nil {
+		md.Organization = &OrganizationMetadata{Type: t}
+	}
+	o := md.Organization
+	setIfEmpty(&o.Name, schemaString(item["name"]))
+	setIfEmpty(&o.URL, schemaString(item["url"]))
+	setIfEmpty(&o.Logo, schemaURL(item["logo"]))
+	if len(o.SameAs) == 0 {
+		o.SameAs = schemaStrings(item["sameAs"])
+	}
+}
+
+// addHighwire maps Highwire Press citation_* tags, used by Google Scholar,
+// to scholarly metadata
+func (md *structuredMetadata) addHighwire(metas map[string][]string) {
+	first := func(key string) string {
+		if v := metas[key]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	if first("citation_title") == "" && first("citation_doi") == "" {
+		return
+	}
+
+	if md.Scholarly == nil {
+		md.Scholarly = &ScholarlyMetadata{}
+	}
+	s := md.Scholarly
+	setIfEmpty(&s.Title, first("citation_title"))
+	if len(s.Authors) == 0 {
+		s.Authors = metas["citation_author"]
+	}
+	setIfEmpty(&s.PublicationDate, first("citation_publication_date"), first("citation_date"), first("citation_online_date"))
+	setIfEmpty(&s.Journal, first("citation_journal_title"))
+	setIfEmpty(&s.Conference, first("citation_conference_title"))
+	setIfEmpty(&s.Publisher, first("citation_publisher"), first("citation_dissertation_institution"), first("citation_technical_report_institution"))
+	setIfEmpty(&s.Volume, first("citation_volume"))
+	setIfEmpty(&s.Issue, first("citation_issue"))
+	setIfEmpty(&s.FirstPage, first("citation_firstpage"))
+	setIfEmpty(&s.LastPage, first("citation_lastpage"))
+	setIfEmpty(&s.DOI, doiFromIdentifier(first("citation_doi")))
+	setIfEmpty(&s.ISSN, first("citation_issn"))
+	setIfEmpty(&s.Abstract, first("citation_abstract"))
+	setIfEmpty(&s.PDFURL, first("citation_pdf_url"))
+	if len(s.Keywords) == 0 {
+		for _, kw := range metas["citation_keywords"] {
+			s.Keywords = append(s.Keywords, splitKeywords(kw)...)
+		}
+	}
+}
+
+// addDublinCore maps DC.* and DCTERMS.* tags. They fill the scholarly record
+// when there is one and otherwise describe the page as an article.
+func (md *structuredMetadata) addDublinCore(metas map[string][]string) {
+	values := func(name string) []string {
+		return append(append([]string{}, metas["dc."+name]...), metas["dcterms."+name]...)
+	}
+	first := func(names ...string) string {
+		for _, name := range names {
+			if v := values(name); len(v) > 0 {
+				return v[0]
+			}
+		}
+		return ""
+	}
+
+	title := first("title")
+	if md.Scholarly != nil {
+		s := md.Scholarly
+		setIfEmpty(&s.Title, title)
+		if len(s.Authors) == 0 {
+			s.Authors = values("creator")
+		}
+		setIfEmpty(&s.PublicationDate, first("date", "issued", "created"))
+		setIfEmpty(&s.Publisher, first("publisher"))
+		setIfEmpty(&s.Abstract, first("abstract", "description"))
+		for _, id := range values("identifier") {
+			setIfEmpty(&s.DOI, doiFromIdentifier(id))
+		}
+		return
+	}
+
+	if title == "" && len(values("creator")) == 0 {
+		return
+	}
+	if md.Article == nil {
+		md.Article = &ArticleMetadata{Type: "CreativeWork"}
+	}
+	a := md.Article
+	setIfEmpty(&a.Headline, title)
+	if len(a.Authors) == 0 {
+		a.Authors = values("creator")
+	}
+	setIfEmpty(&a.DatePublished, first("date", "issued", "created"))
+	setIfEmpty(&a.DateModified, first("modified"))
+	setIfEmpty(&a.Publisher, first("publisher"))
+	setIfEmpty(&a.Description, first("description", "abstract"))
+	if len(a.Keywords) == 0 {
+		for _, subject := range values("subject") {
+			a.Keywords = append(a.Keywords, splitKeywords(subject)...)
+		}
+	}
+}
+
+// metaValues returns the content of every named <meta> tag keyed by the
+// lower-cased name, keeping repeated tags such as citation_author
+func metaValues(doc *html.Node) map[string][]string {
+	values := make(map[string][]string)
+	for _, m := range domFindAll(doc, func(n *html.Node) bool { return isTag(n, "meta") }) {
+		name := strings.ToLower(strings.TrimSpace(domAttr(m, "name")))
+		content := strings.TrimSpace(domAttr(m, "content"))
+		if name != "" && content != "" {
+			values[name] = append(values[name], content)
+		}
+	}
+	return values
+}
+
+// jsonLDItems returns every object in the page's JSON-LD blocks, flattening
+// arrays and @graph containers. Malformed blocks are skipped.
+func jsonLDItems(doc *html.Node) []map[string]any {
+	var items []map[string]any
+	var collect func(v any)
+	collect = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, e := range v {
+				collect(e)
+			}
+		case map[string]any:
+			if graph, ok := v["@graph"]; ok {
+				collect(graph)
+			}
+			if _, ok := v["@type"]; ok {
+				items = append(items, v)
+			}
+		}
+	}
+
+	scripts := domFindAll(doc, func(n *html.Node) bool {
+		return isTag(n, "script") && strings.EqualFold(strings.TrimSpace(domAttr(n, "type")), "application/ld+json")
+	})
+	for _, script := range scripts {
+		var data any
+		if err := json.Unmarshal([]byte(domRawText(script)), &data); err != nil {
+			continue
+		}
+		collect(data)
+	}
+	return items
+}
+
+// microdataItems converts top-level itemscope elements into JSON-LD style
+// objects with "@type" and one key per itemprop
+func microdataItems(doc *html.Node) []map[string]any {
+	var items []map[string]any
+	for _, n := range domFindAll(doc, func(n *html.Node) bool { return isItemScope(n) && domAttr(n, "itemprop") == "" }) {
+		items = append(items, microdataItem(n))
+	}
+	return items
+}
+
+func isItemScope(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	for _, a := range n.Attr {
+		if a.Key == "itemscope" {
+			return true
+		}
+	}
+	return false
+}
+
+func microdataItem(scope *html.Node) map[string]any {
+	item := make(map[string]any)
+	var types []any
+	for _, t := range strings.Fields(domAttr(scope, "itemtype")) {
+		types = append(types, t)
+	}
+	item["@type"] = types
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if props := strings.Fields(domAttr(c, "itemprop")); len(props) > 0 {
+				var value any
+				if isItemScope(c) {
+					value = microdataItem(c)
+				} else {
+					value = microdataValue(c)
+				}
+				for _, prop := range props {
+					if existing, ok := item[prop]; ok {
+						if list, ok := existing.([]any); ok {
+							item[prop] = append(list, value)
+						} else {
+							item[prop] = []any{existing, value}
+						}
+					} else {
+						item[prop] = value
+					}
+				}
+			}
+			// Properties of nested items belong to those items
+			if !isItemScope(c) {
+				walk(c)
+			}
+		}
+	}
+	walk(scope)
+	return item
+}
+
+// microdataValue returns an itemprop value following the HTML microdata rules
+func microdataValue(n *html.Node) string {
+	switch n.Data {
+	case "meta":
+		return domAttr(n, "content")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return domAttr(n, "src")
+	case "a", "area", "link":
+		return domAttr(n, "href")
+	case "object":
+		return domAttr(n, "data")
+	case "data", "meter":
+		return domAttr(n, "value")
+	case "time":
+		if v := domAttr(n, "datetime"); v != "" {
+			return v
+		}
+	}
+	if v := domAttr(n, "content"); v != "" {
+		return v
+	}
+	return domText(n)
+}
+
+// schemaTypes returns the short schema.org type names of an item
+func schemaTypes(item map[string]any) []string {
+	var types []string
+	for _, t := range schemaStrings(item["@type"]) {
+		t = strings.TrimSuffix(t, "/")
+		if i := strings.LastIndexAny(t, "/#:"); i >= 0 {
+			t = t[i+1:]
+		}
+		if t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// schemaString returns the first string found in a JSON-LD value
+func schemaString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return fmt.Sprint(v)
+	case []any:
+		for _, e := range v {
+			if s := schemaString(e); s != "" {
+				return s
+			}
+		}
+	case map[string]any:
+		for _, key := range []string{"@value", "value", "@id", "url", "name"} {
+			if s := schemaString(v[key]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func schemaStrings(v any) []string {
+	var out []string
+	switch v := v.(type) {
+	case []any:
+		for _, e := range v {
+			if s := schemaString(e); s != "" {
+				out = append(out, s)
+			}
+		}
+	default:
+		if s := schemaString(v); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// schemaName returns the name of a person or organization value
+func schemaName(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return schemaString(v["name"])
+	case []any:
+		for _, e := range v {
+			if s := schemaName(e); s != "" {
+				return s
+			}
+		}
+		return ""
+	default:
+		return schemaString(v)
+	}
+}
+
+func schemaNames(v any) []string {
+	var out []string
+	if list, ok := v.([]any); ok {
+		for _, e := range list {
+			if s := schemaName(e); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	if s := schemaName(v); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+// schemaURL returns the URL of an image or logo value
+func schemaURL(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, key := range []string{"url", "contentUrl", "@id"} {
+			if s := schemaString(v[key]); s != "" {
+				return s
+			}
+		}
+		return ""
+	case []any:
+		for _, e := range v {
+			if s := schemaURL(e); s != "" {
+				return s
+			}
+		}
+		return ""
+	default:
+		return schemaString(v)
+	}
+}
+
+func schemaKeywords(v any) []string {
+	if s, ok := v.(string); ok {
+		return splitKeywords(s)
+	}
+	return schemaStrings(v)
+}
+
+func splitKeywords(s string) []string {
+	var out []string
+	for _, kw := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if kw = strings.TrimSpace(kw); kw != "" {
+			out = append(out, kw)
+		}
+	}
+	return out
+}
+
+// doiFromIdentifier extracts a DOI from "10.x/y", "doi:10.x/y" or a doi.org URL
+func doiFromIdentifier(id string) string {
+	id = strings.TrimSpace(id)
+	if i := strings.Index(id, "10."); i >= 0 && strings.Contains(id[i:], "/") {
+		return id[i:]
+	}
+	return ""
+}
+
+// setIfEmpty sets *field to the first non-empty candidate unless it already has a value
+func setIfEmpty(field *string, candidates ...string) {
+	if *field != "" {
+		return
+	}
+	for _, c := range candidates {
+		if c != "" {
+			*field = c
+			return
+		}
+	}
+}
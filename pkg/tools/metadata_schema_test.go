@@ -0,0 +1,211 @@
+// ABOUTME: Unit tests for structured metadata extraction
+// ABOUTME: Tests JSON-LD, microdata, Highwire citation tags and Dublin Core mapping
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func parseStructured(t *testing.T, page string) structuredMetadata {
+	t.Helper()
+	doc, err := parseHTML(page)
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	base, _ := url.Parse("https://news.example.com/2024/story.html")
+	return extractStructuredMetadata(doc, base)
+}
+
+func TestExtractStructuredMetadata_JSONLD(t *testing.T) {
+	t.Parallel()
+
+	page := `<html><head>
+	<script type="application/ld+json">{ not valid json </script>
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@graph": [
+			{
+				"@type": "NewsArticle",
+				"headline": "Rivers Return",
+				"author": [{"@type": "Person", "name": "Jane Rivera"}, {"@type": "Person", "name": "Sam Lee"}],
+				"datePublished": "2024-03-02T08:00:00Z",
+				"dateModified": "2024-03-03T09:00:00Z",
+				"publisher": {"@type": "NewsMediaOrganization", "name": "Valley News", "logo": {"@type": "ImageObject", "url": "/logo.png"}},
+				"image": ["/img/lead.jpg"],
+				"articleSection": "Environment",
+				"keywords": "drought, rivers"
+			},
+			{"@type": "BreadcrumbList", "itemListElement": []}
+		]
+	}
+	</script>
+	</head><body></body></html>`
+
+	md := parseStructured(t, page)
+
+	if md.Article == nil {
+		t.Fatal("Expected article metadata")
+	}
+	want := ArticleMetadata{
+		Type:          "NewsArticle",
+		Headline:      "Rivers Return",
+		Authors:       []string{"Jane Rivera", "Sam Lee"},
+		DatePublished: "2024-03-02T08:00:00Z",
+		DateModified:  "2024-03-03T09:00:00Z",
+		Publisher:     "Valley News",
+		Section:       "Environment",
+		Keywords:      []string{"drought", "rivers"},
+		Image:         "https://news.example.com/img/lead.jpg",
+	}
+	if !reflect.DeepEqual(*md.Article, want) {
+		t.Errorf("Unexpected article metadata:\ngot:  %+v\nwant: %+v", *md.Article, want)
+	}
+
+	if md.Organization == nil || md.Organization.Name != "Valley News" || md.Organization.Logo != "https://news.example.com/logo.png" {
+		t.Errorf("Expected publisher as organization, got %+v", md.Organization)
+	}
+	if !reflect.DeepEqual(md.Types, []string{"NewsArticle", "BreadcrumbList"}) {
+		t.Errorf("Unexpected schema types: %v", md.Types)
+	}
+}
+
+func TestExtractStructuredMetadata_Highwire(t *testing.T) {
+	t.Parallel()
+
+	page := `<html><head>
+	<meta name="citation_title" content="Deep Learning for Rivers">
+	<meta name="citation_author" content="Rivera, Jane">
+	<meta name="citation_author" content="Lee, Sam">
+	<meta name="citation_publication_date" content="2023/05/01">
+	<meta name="citation_journal_title" content="Journal of Hydrology">
+	<meta name="citation_volume" content="12">
+	<meta name="citation_issue" content="3">
+	<meta name="citation_firstpage" content="100">
+	<meta name="citation_lastpage" content="120">
+	<meta name="citation_doi" content="doi:10.1234/jh.2023.001">
+	<meta name="citation_pdf_url" content="/pdf/paper.pdf">
+	<meta name="DC.publisher" content="Hydro Press">
+	</head><body></body></html>`
+
+	md := parseStructured(t, page)
+
+	if md.Scholarly == nil {
+		t.Fatal("Expected scholarly metadata")
+	}
+	want := ScholarlyMetadata{
+		Title:           "Deep Learning for Rivers",
+		Authors:         []string{"Rivera, Jane", "Lee, Sam"},
+		PublicationDate: "2023/05/01",
+		Journal:         "Journal of Hydrology",
+		Publisher:       "Hydro Press",
+		Volume:          "12",
+		Issue:           "3",
+		FirstPage:       "100",
+		LastPage:        "120",
+		DOI:             "10.1234/jh.2023.001",
+		PDFURL:          "https://news.example.com/pdf/paper.pdf",
+	}
+	if !reflect.DeepEqual(*md.Scholarly, want) {
+		t.Errorf("Unexpected scholarly metadata:\ngot:  %+v\nwant: %+v", *md.Scholarly, want)
+	}
+	if md.Article != nil {
+		t.Errorf("Expected Dublin Core to fill the scholarly record, got article %+v", md.Article)
+	}
+}
+
+func TestExtractStructuredMetadata_Microdata(t *testing.T) {
+	t.Parallel()
+
+	page := `<html><body>
+	<article itemscope itemtype="https://schema.org/BlogPosting">
+		<h1 itemprop="headline">Notes on Gardening</h1>
+		<span itemprop="author" itemscope itemtype="https://schema.org/Person"><span itemprop="name">Alex Kim</span></span>
+		<time itemprop="datePublished" datetime="2022-07-04">July 4</time>
+		<img itemprop="image" src="/garden.jpg">
+		<div itemprop="publisher" itemscope itemtype="https://schema.org/Organization">
+			<meta itemprop="name" content="Garden Weekly">
+		</div>
+	</article>
+	</body></html>`
+
+	md := parseStructured(t, page)
+
+	if md.Article == nil {
+		t.Fatal("Expected article metadata")
+	}
+	if md.Article.Type != "BlogPosting" || md.Article.Headline != "Notes on Gardening" {
+		t.Errorf("Unexpected article type/headline: %+v", md.Article)
+	}
+	if !reflect.DeepEqual(md.Article.Authors, []string{"Alex Kim"}) {
+		t.Errorf("Expected author from nested item, got %v", md.Article.Authors)
+	}
+	if md.Article.DatePublished != "2022-07-04" {
+		t.Errorf("Expected date from datetime, got %q", md.Article.DatePublished)
+	}
+	if md.Article.Image != "https://news.example.com/garden.jpg" {
+		t.Errorf("Expected absolute image URL, got %q", md.Article.Image)
+	}
+	if md.Article.Publisher != "Garden Weekly" || md.Organization == nil || md.Organization.Name != "Garden Weekly" {
+		t.Errorf("Expected publisher organization, got %q / %+v", md.Article.Publisher, md.Organization)
+	}
+}
+
+func TestExtractStructuredMetadata_DublinCore(t *testing.T) {
+	t.Parallel()
+
+	page := `<html><head>
+	<meta name="DC.title" content="Annual Report 2023">
+	<meta name="DC.creator" content="Finance Team">
+	<meta name="DCTERMS.issued" content="2024-01-15">
+	<meta name="DC.subject" content="finance; reporting">
+	</head><body></body></html>`
+
+	md := parseStructured(t, page)
+
+	if md.Article == nil {
+		t.Fatal("Expected article metadata from Dublin Core")
+	}
+	if md.Article.Headline != "Annual Report 2023" || md.Article.DatePublished != "2024-01-15" {
+		t.Errorf("Unexpected article metadata: %+v", md.Article)
+	}
+	if !reflect.DeepEqual(md.Article.Keywords, []string{"finance", "reporting"}) {
+		t.Errorf("Expected subjects as keywords, got %v", md.Article.Keywords)
+	}
+}
+
+func TestExtractMetadataHandler_StructuredData(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Paper</title>
+		<meta name="citation_title" content="A Study">
+		<meta name="citation_doi" content="10.5555/abc">
+		<script type="application/ld+json">{"@type": "Organization", "name": "Example University", "sameAs": ["https://twitter.com/exu"]}</script>
+		</head><body></body></html>`)
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().extractMetadataHandler(context.Background(), ExtractMetadataParams{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.ScholarlyArticle == nil || result.ScholarlyArticle.DOI != "10.5555/abc" {
+		t.Errorf("Expected scholarly article with DOI, got %+v", result.ScholarlyArticle)
+	}
+	if result.Organization == nil || result.Organization.Name != "Example University" {
+		t.Errorf("Expected organization, got %+v", result.Organization)
+	}
+	if result.Meta["citation_title"] != "A Study" {
+		t.Errorf("Expected raw meta tags to be kept, got %v", result.Meta)
+	}
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Catalog of LLM context windows loaded from a models.json inventory
+// ABOUTME: Derives default token budgets for tool output from a model's context window
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	mdomain "github.com/lexlapax/go-llms/pkg/util/llmutil/modelinfo/domain"
+)
+
+// contextShare is the fraction of a model's input context one tool result
+// may take up by default, leaving room for the prompt and other results
+const contextShare = 4
+
+// ModelCatalog looks up model metadata such as context windows by name
+type ModelCatalog struct {
+	models map[string]mdomain.Model
+}
+
+// LoadModelCatalog reads a models.json file, either a plain list of models
+// or a go-llms model inventory
+func LoadModelCatalog(path string) (*ModelCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading model catalog: %w", err)
+	}
+	return ParseModelCatalog(data)
+}
+
+// ParseModelCatalog parses the contents of a models.json file
+func ParseModelCatalog(data []byte) (*ModelCatalog, error) {
+	var models []mdomain.Model
+	if err := json.Unmarshal(data, &models); err != nil {
+		var inventory mdomain.ModelInventory
+		if err := json.Unmarshal(data, &inventory); err != nil {
+			return nil, fmt.Errorf("parsing model catalog: %w", err)
+		}
+		models = inventory.Models
+	}
+
+	catalog := &ModelCatalog{models: make(map[string]mdomain.Model, len(models))}
+	for _, m := range models {
+		catalog.models[strings.ToLower(m.Name)] = m
+	}
+	return catalog, nil
+}
+
+// Model returns the metadata of a model by name, ignoring case and an
+// optional "provider/" prefix
+func (c *ModelCatalog) Model(name string) (mdomain.Model, bool) {
+	name = strings.ToLower(name)
+	if m, ok := c.models[name]; ok {
+		return m, true
+	}
+	if _, bare, found := strings.Cut(name, "/"); found {
+		m, ok := c.models[bare]
+		return m, ok
+	}
+	return mdomain.Model{}, false
+}
+
+// ContextWindow returns the context window of a model in tokens, or 0 when
+// the model or its context window is unknown
+func (c *ModelCatalog) ContextWindow(name string) int {
+	m, _ := c.Model(name)
+	return m.ContextWindow
+}
+
+// TokenBudget returns how many tokens a single tool result should take up
+// for a model: a quarter of the context window left after the model's
+// maximum output. It is 0 when the context window is unknown.
+func (c *ModelCatalog) TokenBudget(name string) int {
+	m, ok := c.Model(name)
+	if !ok || m.ContextWindow <= 0 {
+		return 0
+	}
+	input := m.ContextWindow
+	if m.MaxOutputTokens > 0 && m.MaxOutputTokens < input {
+		input -= m.MaxOutputTokens
+	}
+	return input / contextShare
+}
@@ -0,0 +1,187 @@
+// ABOUTME: Network configuration for outbound tool traffic
+// ABOUTME: Builds base transports with proxies, no-proxy lists, extra root CAs, client certificates and a TLS floor
+
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NetworkConfig describes how tools reach the network. The zero value uses
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables, the system
+// root CAs and TLS 1.2 or later.
+type NetworkConfig struct {
+	// ProxyURL is the proxy for all requests, e.g. "http://proxy.corp:3128"
+	// or "socks5://127.0.0.1:1080". Credentials may be given in the URL.
+	// When set, the proxy environment variables are ignored.
+	ProxyURL string
+	// NoProxy lists hosts reached directly: host names, domains (matching
+	// their subdomains), IP addresses, CIDR ranges, any of them with a
+	// ":port", or "*" for all. Requests to localhost are never proxied.
+	NoProxy []string
+
+	CAFiles []string // PEM files with root CAs trusted in addition to the system ones
+	CAPEM   []byte   // PEM-encoded root CAs trusted in addition to the system ones
+
+	// ClientCertFile and ClientKeyFile hold a PEM certificate and key
+	// presented to servers that ask for one (mutual TLS)
+	ClientCertFile string
+	ClientKeyFile  string
+
+	TLSMinVersion uint16 // Minimum TLS version, e.g. tls.VersionTLS13 (default: TLS 1.2)
+}
+
+// NewBaseTransport builds a pooled transport that performs the requests of
+// the shared Transport (see WithBaseTransport and ConfigureNetwork)
+func (cfg NetworkConfig) NewBaseTransport() (*http.Transport, error) {
+	proxies := httpproxy.FromEnvironment()
+	if cfg.ProxyURL != "" {
+		u, err := url.Parse(cfg.ProxyURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", cfg.ProxyURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+		}
+		proxies = &httpproxy.Config{
+			HTTPProxy:  cfg.ProxyURL,
+			HTTPSProxy: cfg.ProxyURL,
+			NoProxy:    strings.Join(cfg.NoProxy, ","),
+		}
+	} else if cfg.NoProxy != nil {
+		proxies.NoProxy = strings.Join(cfg.NoProxy, ",")
+	}
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	proxyFunc := proxies.ProxyFunc()
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.MaxIdleConnsPerHost = 10
+	base.TLSClientConfig = tlsConfig
+	base.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	base.DialContext = proxyAwareDial(&net.Dialer{
+		Timeout:        30 * time.Second,
+		KeepAlive:      30 * time.Second,
+		ControlContext: guardDial,
+	}, proxyAddrs(proxies.HTTPProxy, proxies.HTTPSProxy))
+	return base, nil
+}
+
+// ConfigureNetwork applies cfg to the transport shared by all tools. Tools
+// given their own client with WithHTTPClient are not affected.
+func ConfigureNetwork(cfg NetworkConfig) error {
+	base, err := cfg.NewBaseTransport()
+	if err != nil {
+		return err
+	}
+	defaultTransport.SetBaseTransport(base)
+	return nil
+}
+
+// tlsConfig builds the TLS client configuration
+func (cfg NetworkConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSMinVersion != 0 {
+		if cfg.TLSMinVersion < tls.VersionTLS10 || cfg.TLSMinVersion > tls.VersionTLS13 {
+			return nil, fmt.Errorf("unsupported TLS version 0x%04x", cfg.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = cfg.TLSMinVersion
+	}
+
+	if len(cfg.CAFiles) > 0 || len(cfg.CAPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, path := range cfg.CAFiles {
+			pem, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA file %s", path)
+			}
+		}
+		if len(cfg.CAPEM) > 0 && !pool.AppendCertsFromPEM(cfg.CAPEM) {
+			return nil, errors.New("no certificates found in CA PEM")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		if cfg.ClientCertFile == "" || cfg.ClientKeyFile == "" {
+			return nil, errors.New("client certificate and key must be given together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+type proxyDialKey struct{}
+
+// proxyAwareDial marks connections to a configured proxy so guardDial lets
+// them through: a corporate proxy usually has a private address, while the
+// URL policy has already checked the target host before the request was
+// handed to the proxy.
+func proxyAwareDial(dialer *net.Dialer, proxies map[string]bool) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if proxies[address] {
+			ctx = context.WithValue(ctx, proxyDialKey{}, true)
+		}
+		return dialer.DialContext(ctx, network, address)
+	}
+}
+
+// proxyAddrs returns the host:port addresses the transport dials to reach
+// the given proxies
+func proxyAddrs(proxies ...string) map[string]bool {
+	addrs := make(map[string]bool)
+	for _, p := range proxies {
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "://") {
+			p = "http://" + p
+		}
+		u, err := url.Parse(p)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		port := u.Port()
+		if port == "" {
+			switch u.Scheme {
+			case "https":
+				port = "443"
+			case "socks5", "socks5h":
+				port = "1080"
+			default:
+				port = "80"
+			}
+		}
+		addrs[net.JoinHostPort(u.Hostname(), port)] = true
+	}
+	return addrs
+}
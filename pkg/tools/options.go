@@ -0,0 +1,282 @@
+// ABOUTME: Constructor options shared by tools that make outbound requests
+// ABOUTME: Lets each tool instance carry its own endpoints, HTTP client and API keys
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ToolOption configures a tool instance at construction time
+type ToolOption func(*toolConfig)
+
+// toolConfig holds the per-instance configuration of a tool. Handlers are
+// methods on toolConfig so differently configured tools can coexist.
+type toolConfig struct {
+	baseURL      string
+	httpClient   *http.Client
+	apiKey       string
+	providerURLs map[string]string
+	providerKeys map[string]string
+	credentials  CredentialProvider
+	cache        ResponseCache
+	cacheTTL     time.Duration
+	robots       *RobotsChecker
+	ignoreRobots bool
+	urlPolicy    *URLPolicy
+	guarded      http.RoundTripper // Transport of httpClient checked against urlPolicy when connecting
+
+	maxResponseSize int64
+	contentTypes    []string
+	tokenBudget     int
+	sessions        *SessionStore
+
+	researchRegistry *ResearchProviderRegistry
+}
+
+// WithBaseURL overrides the API endpoint used by the tool
+func WithBaseURL(baseURL string) ToolOption {
+	return func(c *toolConfig) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the tool. Per-call timeouts
+// still apply; the client's transport, jar and redirect policy are kept.
+func WithHTTPClient(client *http.Client) ToolOption {
+	return func(c *toolConfig) {
+		c.httpClient = client
+	}
+}
+
+// WithAPIKey sets the API key used by the tool
+func WithAPIKey(apiKey string) ToolOption {
+	return func(c *toolConfig) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithCredentialProvider sets where the tool looks up API keys that are not
+// carried by the request context or set with WithAPIKey. The default reads
+// environment variables.
+func WithCredentialProvider(provider CredentialProvider) ToolOption {
+	return func(c *toolConfig) {
+		c.credentials = provider
+	}
+}
+
+// WithProviderBaseURL overrides the endpoint of a single research provider
+// (e.g. "arxiv", "pubmed", "core")
+func WithProviderBaseURL(provider, baseURL string) ToolOption {
+	return func(c *toolConfig) {
+		c.providerURLs[provider] = baseURL
+	}
+}
+
+// WithProviderAPIKey sets the API key of a single research provider
+func WithProviderAPIKey(provider, apiKey string) ToolOption {
+	return func(c *toolConfig) {
+		c.providerKeys[provider] = apiKey
+	}
+}
+
+// WithCache serves repeated requests from cache. Responses are stored for
+// ttl when it is positive, otherwise for as long as their Cache-Control or
+// Expires headers allow. "no-store" and "private" responses and responses
+// that set cookies are never stored.
+func WithCache(cache ResponseCache, ttl time.Duration) ToolOption {
+	return func(c *toolConfig) {
+		c.cache = cache
+		c.cacheTTL = ttl
+	}
+}
+
+// WithRobotsChecker sets the robots.txt checker used by web tools, e.g. to
+// share cached rules and crawl delays between a group of tools
+func WithRobotsChecker(checker *RobotsChecker) ToolOption {
+	return func(c *toolConfig) {
+		c.robots = checker
+	}
+}
+
+// WithIgnoreRobots disables robots.txt checks and Crawl-delay waits. Only use
+// it for sites you own or have permission to crawl.
+func WithIgnoreRobots() ToolOption {
+	return func(c *toolConfig) {
+		c.ignoreRobots = true
+	}
+}
+
+// WithURLPolicy restricts the URLs the tool may fetch, including redirect
+// targets. Requests the policy forbids fail with a *URLPolicyError. Use it
+// whenever URLs come from a model or other untrusted input. The addresses
+// connected to are checked again by the shared transport and by an
+// *http.Transport set with WithHTTPClient; other custom round trippers are
+// only checked before each request.
+func WithURLPolicy(policy *URLPolicy) ToolOption {
+	return func(c *toolConfig) {
+		c.urlPolicy = policy
+	}
+}
+
+// WithMaxResponseSize caps how many bytes of a response body the tool reads
+// (default: DefaultMaxResponseSize). Pages and feeds beyond the limit are
+// cut short and flagged as truncated; API responses fail with a
+// *ResponseTooLargeError.
+func WithMaxResponseSize(limit int64) ToolOption {
+	return func(c *toolConfig) {
+		c.maxResponseSize = limit
+	}
+}
+
+// WithAllowedContentTypes replaces the media types web tools read (default:
+// DefaultContentTypes). Patterns may be exact ("application/pdf"), a whole
+// type ("text/*") or a structured syntax suffix ("*+xml").
+func WithAllowedContentTypes(patterns ...string) ToolOption {
+	return func(c *toolConfig) {
+		c.contentTypes = patterns
+	}
+}
+
+// WithTokenBudget sets the default max_tokens of tools that return page
+// content, so a long page comes back in chunks instead of flooding the
+// model's context. WithModelBudget derives it from a model's context window.
+func WithTokenBudget(maxTokens int) ToolOption {
+	return func(c *toolConfig) {
+		c.tokenBudget = maxTokens
+	}
+}
+
+// WithModelBudget sets the default max_tokens like WithTokenBudget, deriving
+// it from the context window the catalog lists for model. Unknown models and
+// a nil catalog leave the budget unset.
+func WithModelBudget(catalog *ModelCatalog, model string) ToolOption {
+	return func(c *toolConfig) {
+		if catalog == nil {
+			return
+		}
+		if budget := catalog.TokenBudget(model); budget > 0 {
+			c.tokenBudget = budget
+		}
+	}
+}
+
+// WithSessions keeps cookies and default headers in the given store for calls
+// whose context carries no store of its own (see ContextWithSessions)
+func WithSessions(store *SessionStore) ToolOption {
+	return func(c *toolConfig) {
+		c.sessions = store
+	}
+}
+
+// WithResearchProviders sets the providers research_paper_api searches
+// (default: DefaultResearchProviders). The tool's schema lists the providers
+// registered when it is created.
+func WithResearchProviders(registry *ResearchProviderRegistry) ToolOption {
+	return func(c *toolConfig) {
+		c.researchRegistry = registry
+	}
+}
+
+func newToolConfig(opts ...ToolOption) *toolConfig {
+	c := &toolConfig{
+		providerURLs: make(map[string]string),
+		providerKeys: make(map[string]string),
+		credentials:  EnvCredentialProvider{},
+		robots:       defaultRobotsChecker,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.urlPolicy != nil && c.httpClient != nil {
+		c.guarded = guardTransport(c.httpClient.Transport)
+	}
+	return c
+}
+
+// client returns an HTTP client with the given timeout. Callers may change
+// the returned client, e.g. its redirect policy, without affecting others.
+func (c *toolConfig) client(timeout time.Duration) *http.Client {
+	var client http.Client
+	if c.httpClient == nil {
+		client = *newHTTPClient(timeout)
+	} else {
+		client = *c.httpClient
+		client.Timeout = timeout
+		if c.guarded != nil {
+			client.Transport = c.guarded
+		}
+	}
+
+	if c.cache != nil {
+		next := client.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		client.Transport = &cachingTransport{next: next, cache: c.cache, ttl: c.cacheTTL}
+	}
+
+	// The policy wraps the cache so cached responses for forbidden URLs are
+	// not served either
+	if c.urlPolicy != nil {
+		next := client.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		client.Transport = &policyTransport{next: next, policy: c.urlPolicy}
+	}
+	return &client
+}
+
+// checkRobots returns a *RobotsDisallowedError if robots.txt forbids
+// fetching u, unless robots checks are disabled for this instance
+func (c *toolConfig) checkRobots(ctx context.Context, client *http.Client, u *url.URL) error {
+	if c.ignoreRobots || c.robots == nil {
+		return nil
+	}
+	return c.robots.Check(ctx, client, u)
+}
+
+// endpoint returns the configured base URL or the given default
+func (c *toolConfig) endpoint(defaultURL string) string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return defaultURL
+}
+
+// providerEndpoint returns the configured URL of a provider or the given default
+func (c *toolConfig) providerEndpoint(provider, defaultURL string) string {
+	if u := c.providerURLs[provider]; u != "" {
+		return u
+	}
+	return defaultURL
+}
+
+// credential resolves an API key. Per-request keys carried by the context
+// win, then the key configured on this instance, then the credential provider.
+// A missing credential yields an empty string and no error.
+func (c *toolConfig) credential(ctx context.Context, name, configured string) (string, error) {
+	if value, err := (ContextCredentialProvider{}).Credential(ctx, name); err == nil {
+		return value, nil
+	}
+	if configured != "" {
+		return configured, nil
+	}
+	if c.credentials == nil {
+		return "", nil
+	}
+	value, err := c.credentials.Credential(ctx, name)
+	if errors.Is(err, ErrCredentialNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", name, err)
+	}
+	return value, nil
+}
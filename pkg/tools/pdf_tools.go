@@ -0,0 +1,545 @@
+// ABOUTME: PDF text extraction tool for reading full papers and reports
+// ABOUTME: Downloads a PDF and returns text by page in reading order with detected section headings
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
+	"github.com/lexlapax/go-llms/pkg/agent/tools"
+	sdomain "github.com/lexlapax/go-llms/pkg/schema/domain"
+)
+
+// maxPDFSize is the default download limit for PDFs, which must be read
+// whole and run larger than web pages
+const maxPDFSize = 50 * 1024 * 1024
+
+// Fetch PDF Text Tool
+
+type FetchPDFTextParams struct {
+	URL       string `json:"url" description:"The URL of the PDF to read"`
+	Pages     string `json:"pages,omitempty" description:"Pages to extract, e.g. 1-3,5,8- (default: all)"`
+	MaxTokens int    `json:"max_tokens,omitempty" description:"Stop adding pages once this many estimated tokens are reached"`
+	MaxChars  int    `json:"max_chars,omitempty" description:"Stop adding pages once this many characters are reached"`
+	Chunk     int    `json:"chunk,omitempty" description:"Which chunk of a first page longer than the limit to return (default: 1)"`
+	Timeout   int    `json:"timeout,omitempty" description:"Timeout in seconds (default: 60)"`
+}
+
+type FetchPDFTextResult struct {
+	URL       string       `json:"url"`
+	Title     string       `json:"title,omitempty"`  // From the document information dictionary
+	Author    string       `json:"author,omitempty"` // From the document information dictionary
+	PageCount int          `json:"page_count"`       // Pages in the whole document
+	Pages     []PDFPage    `json:"pages"`
+	Sections  []PDFSection `json:"sections,omitempty"`
+	NextPage  int          `json:"next_page,omitempty"`  // Set when the limit stopped extraction; pass it in pages to read on
+	NextChunk int          `json:"next_chunk,omitempty"` // Set when the rest of a cut page remains; pass it in chunk with next_page
+	FetchedAt string       `json:"fetched_at"`
+}
+
+type PDFPage struct {
+	Number    int        `json:"number"`
+	Text      string     `json:"text"`
+	Truncated bool       `json:"truncated,omitempty"` // The page alone exceeded the limit and was cut short
+	Chunking  *ChunkInfo `json:"chunking,omitempty"`  // Which part of the cut page the text holds
+}
+
+type PDFSection struct {
+	Heading string `json:"heading"`        // As printed, e.g. "3 Materials and Methods"
+	Type    string `json:"type,omitempty"` // Normalized kind, e.g. "abstract", "methods", "results"
+	Page    int    `json:"page"`
+}
+
+var FetchPDFTextParamSchema = &sdomain.Schema{
+	Type:        "object",
+	Description: "Parameters for extracting text from a PDF",
+	Properties: map[string]sdomain.Property{
+		"url": {
+			Type:        "string",
+			Description: "The URL of the PDF to read, such as the pdf_url of a research paper",
+		},
+		"pages": {
+			Type:        "string",
+			Description: "Pages to extract as a comma-separated list of numbers and ranges, e.g. '1-3,5,8-' (default: all)",
+		},
+		"max_tokens": {
+			Type:        "integer",
+			Description: "Stop adding pages once this many estimated tokens are reached; the result's next_page and next_chunk tell where to continue",
+			Minimum:     float64Ptr(1),
+		},
+		"max_chars": {
+			Type:        "integer",
+			Description: "Stop adding pages once this many characters are reached; the result's next_page and next_chunk tell where to continue",
+			Minimum:     float64Ptr(1),
+		},
+		"chunk": {
+			Type:        "integer",
+			Description: "When the first requested page alone exceeds max_tokens or max_chars, which chunk of it to return; see chunking.total_chunks on the page (default: 1)",
+			Minimum:     float64Ptr(1),
+		},
+		"timeout": {
+			Type:        "integer",
+			Description: "Timeout in seconds (default: 60)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(300),
+		},
+	},
+	Required: []string{"url"},
+}
+
+// NewFetchPDFTextTool creates a tool that extracts text from PDFs
+func NewFetchPDFTextTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
+	return tools.NewTool(
+		"fetch_pdf_text",
+		"Downloads a PDF, such as a research paper, and extracts its text by page with section headings",
+		cfg.fetchPDFTextHandler,
+		FetchPDFTextParamSchema,
+	)
+}
+
+func (c *toolConfig) fetchPDFTextHandler(ctx context.Context, params FetchPDFTextParams) (*FetchPDFTextResult, error) {
+	if params.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	window, err := c.contentWindow(params.MaxTokens, params.MaxChars, params.Chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := 60
+	if params.Timeout > 0 {
+		timeout = params.Timeout
+	}
+
+	client := c.client(time.Duration(timeout) * time.Second)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return c.checkRobots(req.Context(), client, req.URL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", params.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if err := c.checkRobots(ctx, client, req.URL); err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "go-flock/1.0 PDFReader")
+	req.Header.Set("Accept", "application/pdf")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching PDF: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// A PDF cut short cannot be parsed, so it must be read whole
+	body, err := readWholeBody(resp, c.responseLimit(maxPDFSize))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(body, []byte("%PDF-")) {
+		return nil, &UnsupportedContentTypeError{URL: resp.Request.URL.String(), ContentType: resp.Header.Get("Content-Type")}
+	}
+
+	doc, err := parsePDF(body)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := parsePageRanges(params.Pages, doc.pageCount)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FetchPDFTextResult{
+		URL:       resp.Request.URL.String(),
+		Title:     doc.title,
+		Author:    doc.author,
+		PageCount: doc.pageCount,
+		Pages:     make([]PDFPage, 0, len(pages)),
+		FetchedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	var used textSize
+	for i, n := range pages {
+		lines, err := doc.pageLines(n)
+		if err != nil {
+			return nil, err
+		}
+		page := PDFPage{Number: n, Text: joinPDFLines(lines)}
+
+		size := measureText(page.Text)
+		if len(result.Pages) > 0 && !window.limit.fits(used.add(size)) {
+			result.NextPage = n
+			break
+		}
+		if len(result.Pages) == 0 && (window.chunk > 1 || !window.limit.fits(size)) {
+			// Return a chunk of the first page rather than nothing
+			if page.Text, page.Chunking, err = window.apply(page.Text); err != nil {
+				return nil, err
+			}
+			page.Truncated = page.Chunking.TotalChunks > 1
+			if page.Chunking.Chunk < page.Chunking.TotalChunks {
+				// Read on from the rest of this page
+				result.NextPage = n
+				result.NextChunk = page.Chunking.Chunk + 1
+			} else if i+1 < len(pages) {
+				result.NextPage = pages[i+1]
+			}
+		}
+		used = used.add(size)
+
+		for _, line := range lines {
+			if line.heading != "" {
+				result.Sections = append(result.Sections, PDFSection{Heading: line.text, Type: line.heading, Page: n})
+			}
+		}
+		result.Pages = append(result.Pages, page)
+		if page.Chunking != nil {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// parsePageRanges parses a list such as "1-3,5,8-" into sorted, distinct
+// page numbers within 1..count. An empty list selects every page.
+func parsePageRanges(spec string, count int) ([]int, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = "1-"
+	}
+
+	selected := make(map[int]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid page range %q", part)
+		}
+		end := start
+		if isRange {
+			end = count
+			if last = strings.TrimSpace(last); last != "" {
+				if end, err = strconv.Atoi(last); err != nil || end < start {
+					return nil, fmt.Errorf("invalid page range %q", part)
+				}
+			}
+		}
+		for n := start; n <= min(end, count); n++ {
+			selected[n] = true
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no pages in %q (the document has %d pages)", spec, count)
+	}
+	pages := make([]int, 0, len(selected))
+	for n := range selected {
+		pages = append(pages, n)
+	}
+	slices.Sort(pages)
+	return pages, nil
+}
+
+// pdfDocument wraps a parsed PDF. The parser panics on malformed input, so
+// every call into it recovers.
+type pdfDocument struct {
+	reader    *pdf.Reader
+	pageCount int
+	title     string
+	author    string
+}
+
+func parsePDF(body []byte) (doc *pdfDocument, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parsing PDF: %v", r)
+		}
+	}()
+
+	// The parser only accepts PDF 1.x headers, but PDF 2.0 files are
+	// otherwise structured the same
+	if bytes.HasPrefix(body, []byte("%PDF-2.")) {
+		body = slices.Clone(body)
+		copy(body[5:], "1.7")
+	}
+
+	reader, err := pdf.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, fmt.Errorf("parsing PDF: %w", err)
+	}
+	info := reader.Trailer().Key("Info")
+	return &pdfDocument{
+		reader:    reader,
+		pageCount: reader.NumPage(),
+		title:     strings.TrimSpace(info.Key("Title").Text()),
+		author:    strings.TrimSpace(info.Key("Author").Text()),
+	}, nil
+}
+
+// maxPDFTreeDepth bounds walks up the page tree, which a malformed PDF can
+// make circular
+const maxPDFTreeDepth = 64
+
+// inheritedPageKey returns a page attribute that may be inherited from an
+// ancestor in the page tree, or a null value when no node sets it. ok is
+// false when the Parent chain runs deeper than maxPDFTreeDepth.
+func inheritedPageKey(page pdf.Value, key string) (value pdf.Value, ok bool) {
+	v := page
+	for range maxPDFTreeDepth {
+		if v.IsNull() {
+			return v, true
+		}
+		if value = v.Key(key); !value.IsNull() {
+			return value, true
+		}
+		v = v.Key("Parent")
+	}
+	return pdf.Value{}, v.IsNull()
+}
+
+// pdfLine is a line of text in reading order
+type pdfLine struct {
+	text    string
+	heading string // Section type when the line is a section heading
+}
+
+// pdfSegment is a run of glyphs on one baseline without wide gaps
+type pdfSegment struct {
+	x0, x1 float64
+	text   strings.Builder
+}
+
+// pageLines extracts the text of page n as lines in reading order. Glyphs
+// are grouped into lines by baseline and into segments at wide gaps; on
+// two-column pages the left column is read before the right one between
+// full-width lines such as titles.
+func (d *pdfDocument) pageLines(n int) (lines []pdfLine, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("reading PDF page %d: %v", n, r)
+		}
+	}()
+
+	page := d.reader.Page(n)
+	if page.V.IsNull() {
+		return nil, nil
+	}
+	// The parser looks up Resources through the Parent chain without a
+	// limit, so a circular page tree must be caught before reading content
+	if _, ok := inheritedPageKey(page.V, "Resources"); !ok {
+		return nil, fmt.Errorf("reading PDF page %d: page tree is circular or too deep", n)
+	}
+	glyphs := page.Content().Text
+	width := 612.0 // US Letter
+	if box, _ := inheritedPageKey(page.V, "MediaBox"); box.Len() == 4 {
+		width = box.Index(2).Float64() - box.Index(0).Float64()
+	}
+
+	rows := groupGlyphRows(glyphs)
+	twoColumn := isTwoColumn(rows, width/2)
+
+	var left, right []*pdfSegment
+	flush := func() {
+		for _, seg := range append(left, right...) {
+			lines = append(lines, newPDFLine(seg))
+		}
+		left, right = nil, nil
+	}
+	for _, row := range rows {
+		for _, seg := range row {
+			switch {
+			case !twoColumn:
+				lines = append(lines, newPDFLine(seg))
+			case seg.x0 < width/2 && seg.x1 > width/2:
+				// A full-width line ends the column block above it
+				flush()
+				lines = append(lines, newPDFLine(seg))
+			case seg.x1 <= width/2:
+				left = append(left, seg)
+			default:
+				right = append(right, seg)
+			}
+		}
+	}
+	flush()
+
+	markHeadings(lines)
+	return lines, nil
+}
+
+// groupGlyphRows groups glyphs into rows of segments, top to bottom and left
+// to right
+func groupGlyphRows(glyphs []pdf.Text) [][]*pdfSegment {
+	glyphs = slices.DeleteFunc(slices.Clone(glyphs), func(g pdf.Text) bool { return g.S == "" })
+	// Baselines within a fraction of the font size are the same line
+	sort.SliceStable(glyphs, func(i, j int) bool { return glyphs[i].Y > glyphs[j].Y })
+
+	var rows [][]pdf.Text
+	for _, g := range glyphs {
+		if last := len(rows) - 1; last >= 0 {
+			first := rows[last][0]
+			if math.Abs(first.Y-g.Y) <= math.Max(first.FontSize, 1)*0.4 {
+				rows[last] = append(rows[last], g)
+				continue
+			}
+		}
+		rows = append(rows, []pdf.Text{g})
+	}
+
+	segmented := make([][]*pdfSegment, 0, len(rows))
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].X < row[j].X })
+
+		var segs []*pdfSegment
+		var seg *pdfSegment
+		for _, g := range row {
+			size := math.Max(g.FontSize, 1)
+			w := g.W
+			if w <= 0 {
+				// Fonts without widths; assume an average glyph
+				w = size * 0.5
+			}
+			gap := 0.0
+			if seg != nil {
+				gap = g.X - seg.x1
+			}
+			if seg == nil || gap > size*2 {
+				seg = &pdfSegment{x0: g.X}
+				segs = append(segs, seg)
+			} else if gap > size*0.15 && !strings.HasSuffix(seg.text.String(), " ") && g.S != " " {
+				// Word spacing done by positioning rather than a space glyph
+				seg.text.WriteByte(' ')
+			}
+			seg.text.WriteString(g.S)
+			seg.x1 = math.Max(seg.x1, g.X+w)
+		}
+		segmented = append(segmented, segs)
+	}
+	return segmented
+}
+
+// isTwoColumn reports whether most text sits clear of the middle of the
+// page on both sides, as in a two-column paper
+func isTwoColumn(rows [][]*pdfSegment, middle float64) bool {
+	var leftCount, rightCount, crossing int
+	for _, row := range rows {
+		for _, seg := range row {
+			switch {
+			case seg.x1 <= middle:
+				leftCount++
+			case seg.x0 >= middle:
+				rightCount++
+			default:
+				crossing++
+			}
+		}
+	}
+	total := leftCount + rightCount + crossing
+	return total > 0 && leftCount >= total/4 && rightCount >= total/4 && crossing*5 < total
+}
+
+func newPDFLine(seg *pdfSegment) pdfLine {
+	return pdfLine{text: normalizeSpace(seg.text.String())}
+}
+
+// sectionHeadings maps the usual section titles of papers to their type
+var sectionHeadings = map[string]string{
+	"abstract":               "abstract",
+	"summary":                "abstract",
+	"introduction":           "introduction",
+	"background":             "background",
+	"related work":           "related_work",
+	"literature review":      "related_work",
+	"methods":                "methods",
+	"method":                 "methods",
+	"methodology":            "methods",
+	"materials and methods":  "methods",
+	"methods and materials":  "methods",
+	"experimental setup":     "methods",
+	"experiments":            "experiments",
+	"evaluation":             "experiments",
+	"results":                "results",
+	"results and discussion": "results",
+	"findings":               "results",
+	"discussion":             "discussion",
+	"conclusion":             "conclusion",
+	"conclusions":            "conclusion",
+	"limitations":            "limitations",
+	"acknowledgments":        "acknowledgments",
+	"acknowledgements":       "acknowledgments",
+	"references":             "references",
+	"bibliography":           "references",
+	"appendix":               "appendix",
+}
+
+// headingPrefix matches section numbering such as "3", "3.2.", "IV." or "A"
+var headingPrefix = regexp.MustCompile(`^(?:\d+(?:\.\d+)*\.?|[IVX]+\.|[A-Z]\.?)\s+`)
+
+// inlineAbstract matches an abstract that starts on its heading line, e.g.
+// "Abstract—We propose ..."
+var inlineAbstract = regexp.MustCompile(`(?i)^abstract\s*[:.—–-]\s*\S`)
+
+// markHeadings sets the section type of lines that are section headings:
+// a usual section title, optionally numbered, alone on a short line
+func markHeadings(lines []pdfLine) {
+	for i := range lines {
+		text := lines[i].text
+		if inlineAbstract.MatchString(text) {
+			lines[i].heading = "abstract"
+			continue
+		}
+		if len(strings.Fields(text)) > 6 {
+			continue
+		}
+		title := strings.ToLower(strings.TrimRight(headingPrefix.ReplaceAllString(text, ""), " .:"))
+		if kind, ok := sectionHeadings[title]; ok {
+			lines[i].heading = kind
+		}
+	}
+}
+
+// joinPDFLines joins lines into page text, setting headings apart with a
+// blank line
+func joinPDFLines(lines []pdfLine) string {
+	var b strings.Builder
+	for i, line := range lines {
+		if line.text == "" {
+			continue
+		}
+		if i > 0 {
+			if line.heading != "" {
+				b.WriteString("\n\n")
+			} else {
+				b.WriteByte('\n')
+			}
+		}
+		b.WriteString(line.text)
+	}
+	return strings.TrimSpace(b.String())
+}
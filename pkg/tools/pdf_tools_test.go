@@ -0,0 +1,281 @@
+// ABOUTME: Unit tests for the fetch_pdf_text tool
+// ABOUTME: Tests reading order, two-column layout, section headings, page ranges and token budgets on generated PDFs
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pdfText is a string drawn at a position on a test page
+type pdfText struct {
+	x, y, size float64
+	s          string
+}
+
+// buildTestPDF writes a minimal PDF with one Helvetica font and the given
+// pages of text
+func buildTestPDF(title string, pages [][]pdfText) []byte {
+	var objects []string
+	widths := strings.TrimSpace(strings.Repeat("500 ", 126-32+1))
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // Pages, filled in below
+		fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /FirstChar 32 /LastChar 126 /Widths [%s] >>", widths),
+		fmt.Sprintf("<< /Title (%s) /Author (Test Author) >>", title),
+	)
+
+	var kids []string
+	for _, page := range pages {
+		var content strings.Builder
+		for _, t := range page {
+			s := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(t.s)
+			fmt.Fprintf(&content, "BT /F1 %g Tf %g %g Td (%s) Tj ET\n", t.size, t.x, t.y, s)
+		}
+		contentID := len(objects) + 2
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)+1))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", contentID),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 612 792] >>", strings.Join(kids, " "), len(pages))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+func testPaperPDF() []byte {
+	return buildTestPDF("River Flow Forecasting", [][]pdfText{
+		{
+			{x: 150, y: 740, size: 18, s: "River Flow Forecasting"},
+			{x: 72, y: 700, size: 12, s: "Abstract"},
+			{x: 72, y: 680, size: 10, s: "We forecast river flow"},
+			// Word spacing by position rather than a space character
+			{x: 188, y: 680, size: 10, s: "with neural networks."},
+			{x: 72, y: 640, size: 12, s: "1 Introduction"},
+			{x: 72, y: 620, size: 10, s: "Floods are costly."},
+		},
+		{
+			{x: 150, y: 740, size: 14, s: "Full Width Heading Line Across"},
+			// Left and right columns share baselines
+			{x: 72, y: 700, size: 12, s: "2 Methods"},
+			{x: 320, y: 700, size: 12, s: "3 Results"},
+			{x: 72, y: 680, size: 10, s: "We trained a model."},
+			{x: 320, y: 680, size: 10, s: "Errors fell by half."},
+			{x: 72, y: 660, size: 10, s: "Data came from gauges."},
+			{x: 320, y: 660, size: 10, s: "Peaks were captured."},
+		},
+		{
+			{x: 72, y: 700, size: 12, s: "References"},
+			{x: 72, y: 680, size: 10, s: "[1] A. Author. Rivers. 2020."},
+		},
+	})
+}
+
+func newPDFServer(t *testing.T, body []byte, contentType string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchPDFTextHandler(t *testing.T) {
+	t.Parallel()
+
+	server := newPDFServer(t, testPaperPDF(), "application/pdf")
+	result, err := newToolConfig().fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: server.URL + "/paper.pdf"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Title != "River Flow Forecasting" || result.Author != "Test Author" || result.PageCount != 3 || len(result.Pages) != 3 {
+		t.Fatalf("Unexpected document info: title %q author %q pages %d/%d", result.Title, result.Author, len(result.Pages), result.PageCount)
+	}
+
+	wantFirst := "River Flow Forecasting\n\nAbstract\nWe forecast river flow with neural networks.\n\n1 Introduction\nFloods are costly."
+	if got := result.Pages[0].Text; got != wantFirst {
+		t.Errorf("Unexpected page 1 text:\n%s\nwant:\n%s", got, wantFirst)
+	}
+
+	wantSecond := "Full Width Heading Line Across\n\n2 Methods\nWe trained a model.\nData came from gauges.\n\n3 Results\nErrors fell by half.\nPeaks were captured."
+	if got := result.Pages[1].Text; got != wantSecond {
+		t.Errorf("Expected the left column before the right one, got:\n%s\nwant:\n%s", got, wantSecond)
+	}
+
+	wantSections := []PDFSection{
+		{Heading: "Abstract", Type: "abstract", Page: 1},
+		{Heading: "1 Introduction", Type: "introduction", Page: 1},
+		{Heading: "2 Methods", Type: "methods", Page: 2},
+		{Heading: "3 Results", Type: "results", Page: 2},
+		{Heading: "References", Type: "references", Page: 3},
+	}
+	if !reflect.DeepEqual(result.Sections, wantSections) {
+		t.Errorf("Unexpected sections:\n%+v\nwant:\n%+v", result.Sections, wantSections)
+	}
+}
+
+func TestFetchPDFTextHandler_PagesAndBudget(t *testing.T) {
+	t.Parallel()
+
+	server := newPDFServer(t, testPaperPDF(), "application/octet-stream")
+	cfg := newToolConfig()
+
+	result, err := cfg.fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: server.URL, Pages: "2-"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Pages) != 2 || result.Pages[0].Number != 2 || result.Pages[1].Number != 3 || result.Sections[0].Type != "methods" {
+		t.Errorf("Expected pages 2 and 3 only, got %+v", result.Pages)
+	}
+
+	result, err = cfg.fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: server.URL, MaxTokens: 30})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Pages) != 1 || result.NextPage != 2 || result.Pages[0].Truncated {
+		t.Errorf("Expected one page and next_page 2, got %d pages and next_page %d", len(result.Pages), result.NextPage)
+	}
+
+	result, err = cfg.fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: server.URL, MaxTokens: 5})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Pages) != 1 || !result.Pages[0].Truncated || EstimateTokens(result.Pages[0].Text) > 5 || result.NextPage != 1 || result.NextChunk != 2 {
+		t.Errorf("Expected the start of page 1 only, got %+v (next_page %d, next_chunk %d)", result.Pages, result.NextPage, result.NextChunk)
+	}
+
+	// Following next_page and next_chunk reads every page in full
+	var read []string
+	params := FetchPDFTextParams{URL: server.URL, MaxTokens: 5}
+	for calls := 0; calls < 100; calls++ {
+		result, err := cfg.fetchPDFTextHandler(context.Background(), params)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, page := range result.Pages {
+			read = append(read, page.Text)
+		}
+		if result.NextPage == 0 {
+			break
+		}
+		params.Pages = fmt.Sprintf("%d-", result.NextPage)
+		params.Chunk = result.NextChunk
+	}
+	full, err := cfg.fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var want []string
+	for _, page := range full.Pages {
+		want = append(want, page.Text)
+	}
+	if strings.Join(strings.Fields(strings.Join(read, " ")), " ") != strings.Join(strings.Fields(strings.Join(want, " ")), " ") {
+		t.Errorf("Expected the chunks to add up to the whole document, got:\n%q", read)
+	}
+
+	first, err := cfg.fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: server.URL, MaxChars: 60})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := cfg.fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: server.URL, MaxChars: 60, Chunk: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	page := second.Pages[0]
+	if page.Number != 1 || page.Chunking == nil || page.Chunking.Chunk != 2 || len([]rune(page.Text)) > 60 || page.Text == first.Pages[0].Text {
+		t.Errorf("Expected the second chunk of page 1, got %+v", page)
+	}
+	if _, err := cfg.fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: server.URL, MaxChars: 60, Chunk: 99}); err == nil {
+		t.Error("Expected error for a chunk past the end")
+	}
+
+	if _, err := cfg.fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: server.URL, Pages: "9-12"}); err == nil {
+		t.Error("Expected error for pages past the end")
+	}
+}
+
+func TestFetchPDFTextHandler_Errors(t *testing.T) {
+	t.Parallel()
+
+	html := newPDFServer(t, []byte("<html><body>Sign in to download</body></html>"), "text/html")
+	_, err := newToolConfig().fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: html.URL})
+	var typeErr *UnsupportedContentTypeError
+	if !errors.As(err, &typeErr) || typeErr.ContentType != "text/html" {
+		t.Errorf("Expected *UnsupportedContentTypeError for an HTML page, got %v", err)
+	}
+
+	broken := newPDFServer(t, []byte("%PDF-1.4\nnot really a pdf"), "application/pdf")
+	if _, err := newToolConfig().fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: broken.URL}); err == nil || !strings.Contains(err.Error(), "parsing PDF") {
+		t.Errorf("Expected parse error for a malformed PDF, got %v", err)
+	}
+
+	// A page tree whose Pages node is its own Parent, with nothing for the
+	// page to inherit; replacements keep lengths so the xref stays valid
+	circular := string(testPaperPDF())
+	circular = strings.Replace(circular, "/MediaBox [0 0 612 792]", fmt.Sprintf("%-23s", "/Parent 2 0 R"), 1)
+	resources := "/Resources << /Font << /F1 3 0 R >> >>"
+	circular = strings.ReplaceAll(circular, resources, strings.Repeat(" ", len(resources)))
+	cyclic := newPDFServer(t, []byte(circular), "application/pdf")
+	if _, err := newToolConfig().fetchPDFTextHandler(context.Background(), FetchPDFTextParams{URL: cyclic.URL}); err == nil || !strings.Contains(err.Error(), "circular") {
+		t.Errorf("Expected an error for a circular page tree, got %v", err)
+	}
+
+	if _, err := newToolConfig().fetchPDFTextHandler(context.Background(), FetchPDFTextParams{}); err == nil {
+		t.Error("Expected error for empty url")
+	}
+}
+
+func TestParsePageRanges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec string
+		want []int
+	}{
+		{spec: "", want: []int{1, 2, 3, 4, 5}},
+		{spec: "2", want: []int{2}},
+		{spec: "4-", want: []int{4, 5}},
+		{spec: "1-2, 5, 2", want: []int{1, 2, 5}},
+		{spec: "3-99", want: []int{3, 4, 5}},
+	}
+	for _, tt := range tests {
+		got, err := parsePageRanges(tt.spec, 5)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePageRanges(%q) = %v, %v; want %v", tt.spec, got, err, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"0", "a-b", "3-1", "-2", "7"} {
+		if _, err := parsePageRanges(spec, 5); err == nil {
+			t.Errorf("parsePageRanges(%q): expected error", spec)
+		}
+	}
+}
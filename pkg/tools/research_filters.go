@@ -0,0 +1,232 @@
+// ABOUTME: Search filters of the research_paper_api tool
+// ABOUTME: Validates date ranges, parses publication dates and post-filters papers on filters a provider did not apply itself
+
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Filters of research_paper_api, as reported in ProviderInfo
+const (
+	ResearchFilterDate       = "date"        // start_date and end_date
+	ResearchFilterAuthors    = "authors"     // authors
+	ResearchFilterCategories = "categories"  // categories
+	ResearchFilterOpenAccess = "open_access" // open_access
+)
+
+// requestedFilters returns the filters set in params
+func requestedFilters(params ResearchPaperAPIParams) []string {
+	var filters []string
+	if params.StartDate != "" || params.EndDate != "" {
+		filters = append(filters, ResearchFilterDate)
+	}
+	if len(params.Authors) > 0 {
+		filters = append(filters, ResearchFilterAuthors)
+	}
+	if len(params.Categories) > 0 {
+		filters = append(filters, ResearchFilterCategories)
+	}
+	if params.OpenAccess {
+		filters = append(filters, ResearchFilterOpenAccess)
+	}
+	return filters
+}
+
+// dateRange returns the inclusive range of days given by start_date and
+// end_date. Open ends are zero.
+func dateRange(params ResearchPaperAPIParams) (start, end time.Time, err error) {
+	if params.StartDate != "" {
+		if start, err = time.Parse("2006-01-02", params.StartDate); err != nil {
+			return start, end, fmt.Errorf("invalid start_date %q: use YYYY-MM-DD", params.StartDate)
+		}
+	}
+	if params.EndDate != "" {
+		if end, err = time.Parse("2006-01-02", params.EndDate); err != nil {
+			return start, end, fmt.Errorf("invalid end_date %q: use YYYY-MM-DD", params.EndDate)
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return start, end, fmt.Errorf("end_date %s is before start_date %s", params.EndDate, params.StartDate)
+	}
+	return start, end, nil
+}
+
+// publishedDateFormats are the date layouts providers use, from the most
+// to the least precise
+var publishedDateFormats = []struct {
+	layout string
+	span   func(time.Time) time.Time // Start of the period after the date
+}{
+	{time.RFC3339, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2006-01-02T15:04:05", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2006/01/02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2006 Jan 2", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"Jan 2, 2006", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"January 2, 2006", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2 Jan 2006", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2 January 2006", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"2006 Jan", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"2006 January", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"Jan 2006", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"January 2006", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+}
+
+// parsePublishedDate parses a ResearchPaper.PublishedDate into the period it
+// covers: a day, or a whole month or year when that is all the provider
+// gives. Ranges such as PubMed's "2019 Jan-Feb" are dated by their first
+// month. ok is false for dates in no known format.
+func parsePublishedDate(value string) (from, until time.Time, ok bool) {
+	value = strings.TrimSpace(value)
+	candidates := []string{value}
+	if len(value) > 8 {
+		candidates = append(candidates, value[:8]) // "2019 Jan" of "2019 Jan-Feb"
+	}
+	if len(value) > 4 && !unicode.IsDigit(rune(value[4])) {
+		candidates = append(candidates, value[:4]) // "2018" of "2018-2019"
+	}
+
+	for _, candidate := range candidates {
+		for _, f := range publishedDateFormats {
+			if t, err := time.Parse(f.layout, candidate); err == nil {
+				day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+				return day, f.span(day), true
+			}
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// filterPapers drops papers that fail a requested filter the provider did not
+// apply natively, and returns the papers kept and the filters applied. Papers
+// whose date, authors or fields are unknown are kept; open access must be
+// evident from the paper.
+func filterPapers(papers []ResearchPaper, params ResearchPaperAPIParams, native []string) ([]ResearchPaper, []string) {
+	var filters []string
+	for _, f := range requestedFilters(params) {
+		if !slices.Contains(native, f) {
+			filters = append(filters, f)
+		}
+	}
+	if len(filters) == 0 {
+		return papers, nil
+	}
+
+	start, end, _ := dateRange(params) // Validated by the handler
+	kept := papers[:0:0]
+	for _, paper := range papers {
+		if paperMatches(paper, params, filters, start, end) {
+			kept = append(kept, paper)
+		}
+	}
+	return kept, filters
+}
+
+func paperMatches(paper ResearchPaper, params ResearchPaperAPIParams, filters []string, start, end time.Time) bool {
+	for _, f := range filters {
+		switch f {
+		case ResearchFilterDate:
+			from, until, ok := parsePublishedDate(paper.PublishedDate)
+			if ok && ((!start.IsZero() && !until.After(start)) || (!end.IsZero() && from.After(end))) {
+				return false
+			}
+		case ResearchFilterAuthors:
+			if len(paper.Authors) > 0 && !slices.ContainsFunc(params.Authors, func(name string) bool {
+				return slices.ContainsFunc(paper.Authors, func(author string) bool { return authorMatches(author, name) })
+			}) {
+				return false
+			}
+		case ResearchFilterCategories:
+			if len(paper.FieldsOfStudy) > 0 && !slices.ContainsFunc(params.Categories, func(cat string) bool {
+				return slices.ContainsFunc(paper.FieldsOfStudy, func(field string) bool { return categoryMatches(cat, field) })
+			}) {
+				return false
+			}
+		case ResearchFilterOpenAccess:
+			if !paper.OpenAccess && paper.PDFURL == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// authorName splits a name given as "First Middle Last" or "Last, First"
+// into the surname and the given names
+func authorName(name string) (surname string, given []string) {
+	if last, first, ok := strings.Cut(name, ","); ok {
+		return strings.TrimSpace(last), strings.Fields(first)
+	}
+	parts := strings.Fields(name)
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return parts[len(parts)-1], parts[:len(parts)-1]
+}
+
+// authorMatches reports whether a paper's author is the requested person:
+// surnames must be equal and, when both names have given names, so must
+// their first initials
+func authorMatches(author, requested string) bool {
+	aSurname, aGiven := authorName(author)
+	rSurname, rGiven := authorName(requested)
+	if aSurname == "" || !strings.EqualFold(aSurname, rSurname) {
+		return false
+	}
+	if len(aGiven) == 0 || len(rGiven) == 0 {
+		return true
+	}
+	return initial(aGiven[0]) == initial(rGiven[0])
+}
+
+// initial returns the upper-case first letter of a given name
+func initial(name string) string {
+	r, _ := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r))
+}
+
+// categoryFieldNames maps the category codes of research_paper_api to the
+// fields of study Semantic Scholar and OpenAlex use
+var categoryFieldNames = map[string][]string{
+	"cs":       {"Computer Science"},
+	"math":     {"Mathematics"},
+	"stat":     {"Mathematics"},
+	"physics":  {"Physics"},
+	"astro-ph": {"Physics"},
+	"cond-mat": {"Physics", "Materials Science"},
+	"q-bio":    {"Biology"},
+	"biology":  {"Biology"},
+	"q-fin":    {"Economics", "Business"},
+	"econ":     {"Economics"},
+	"eess":     {"Engineering"},
+	"medicine": {"Medicine"},
+	"clinical": {"Medicine"},
+	"health":   {"Medicine"},
+}
+
+// categoryFields returns the fields of study a category stands for. Unknown
+// categories are taken to be field names themselves, e.g. "Law".
+func categoryFields(category string) []string {
+	if fields, ok := categoryFieldNames[strings.ToLower(category)]; ok {
+		return fields
+	}
+	return []string{category}
+}
+
+// categoryMatches reports whether a paper's field of study belongs to a
+// category. OpenAlex fields are broader names such as "Physics and
+// Astronomy", so a field matches when it contains the category's field name.
+func categoryMatches(category, field string) bool {
+	field = strings.ToLower(field)
+	return slices.ContainsFunc(categoryFields(category), func(name string) bool {
+		return strings.Contains(field, strings.ToLower(name))
+	})
+}
@@ -0,0 +1,275 @@
+// ABOUTME: Unit tests for research_paper_api search filters
+// ABOUTME: Tests native filter syntax per provider, date parsing, post-filtering and the filters reported per provider
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProviderSearchQueries(t *testing.T) {
+	t.Parallel()
+
+	params := ResearchPaperAPIParams{
+		Query:      "neural networks",
+		StartDate:  "2023-01-01",
+		EndDate:    "2023-06-30",
+		Authors:    []string{"Geoffrey E. Hinton", "LeCun, Yann"},
+		Categories: []string{"cs", "cs.LG", "hep-th", "medicine"},
+		OpenAccess: true,
+	}
+	tests := []struct {
+		name   string
+		build  func(*ProviderEnv, ResearchPaperAPIParams) string
+		want   string
+		native []string
+	}{
+		{
+			"arxiv", arxivSearchQuery,
+			`(neural networks) AND submittedDate:[202301010000 TO 202306302359] AND (au:"Geoffrey E. Hinton" OR au:"LeCun, Yann") AND (cat:cs.* OR cat:cs.LG OR cat:hep-th)`,
+			[]string{"date", "authors", "categories", "open_access"},
+		},
+		{
+			"pubmed", pubMedSearchTerm,
+			`(neural networks) AND ("2023/01/01"[dp] : "2023/06/30"[dp]) AND ("Hinton GE"[au] OR "LeCun Y"[au]) AND free full text[sb]`,
+			[]string{"date", "authors", "open_access"},
+		},
+		{
+			"core", coreSearchQuery,
+			`(neural networks) AND yearPublished>=2023 AND yearPublished<=2023 AND (authors:"Geoffrey E. Hinton" OR authors:"LeCun, Yann")`,
+			[]string{"authors"},
+		},
+	}
+	for _, tt := range tests {
+		env := &ProviderEnv{}
+		if got := tt.build(env, params); got != tt.want {
+			t.Errorf("%s query:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+		if got := env.nativeFilters(); !reflect.DeepEqual(got, tt.native) {
+			t.Errorf("%s marked %v, want %v", tt.name, got, tt.native)
+		}
+	}
+
+	// Without filters the query is sent as is
+	env := &ProviderEnv{}
+	if got := arxivSearchQuery(env, ResearchPaperAPIParams{Query: "all:electron", Categories: []string{"medicine"}}); got != "all:electron" {
+		t.Errorf("Expected the plain query, got %q", got)
+	}
+	if got := env.nativeFilters(); len(got) != 0 {
+		t.Errorf("Expected no native filters for a category arXiv lacks, got %v", got)
+	}
+	if got := pubMedSearchTerm(&ProviderEnv{}, ResearchPaperAPIParams{Query: "asthma", EndDate: "2020-12-31"}); got != `(asthma) AND ("1800"[dp] : "2020/12/31"[dp])` {
+		t.Errorf("Unexpected open-ended PubMed term %q", got)
+	}
+}
+
+func TestParsePublishedDate(t *testing.T) {
+	t.Parallel()
+
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		value       string
+		from, until time.Time
+	}{
+		{"2017-06-12", day(2017, 6, 12), day(2017, 6, 13)},
+		{"2017-06-12T17:57:34Z", day(2017, 6, 12), day(2017, 6, 13)},
+		{"2017-06", day(2017, 6, 1), day(2017, 7, 1)},
+		{"2017 Jun", day(2017, 6, 1), day(2017, 7, 1)},
+		{" 2021 ", day(2021, 1, 1), day(2022, 1, 1)},
+	}
+	for _, tt := range tests {
+		from, until, ok := parsePublishedDate(tt.value)
+		if !ok || !from.Equal(tt.from) || !until.Equal(tt.until) {
+			t.Errorf("parsePublishedDate(%q) = %v, %v, %v", tt.value, from, until, ok)
+		}
+	}
+	for _, value := range []string{"", "Spring 2020", "n.d.", "12-2020"} {
+		if _, _, ok := parsePublishedDate(value); ok {
+			t.Errorf("Expected %q not to parse", value)
+		}
+	}
+}
+
+func TestFilterPapers(t *testing.T) {
+	t.Parallel()
+
+	papers := []ResearchPaper{
+		{Title: "in range", PublishedDate: "2023-03-01", Authors: []string{"G. Hinton"}, FieldsOfStudy: []string{"Computer Science"}, OpenAccess: true},
+		{Title: "too early", PublishedDate: "2022-12-31", Authors: []string{"Geoffrey Hinton"}, PDFURL: "https://example.org/a.pdf"},
+		{Title: "year overlaps", PublishedDate: "2023", PDFURL: "https://example.org/b.pdf"},
+		{Title: "undated", Authors: []string{"Yann LeCun"}, OpenAccess: true},
+		{Title: "other author", PublishedDate: "2023-02-01", Authors: []string{"Jane Hinton-Smith", "Carl Hinton"}, OpenAccess: true},
+		{Title: "other field", PublishedDate: "2023-02-01", FieldsOfStudy: []string{"History"}, OpenAccess: true},
+		{Title: "closed", PublishedDate: "2023-02-01"},
+	}
+	params := ResearchPaperAPIParams{
+		StartDate:  "2023-01-01",
+		EndDate:    "2023-12-31",
+		Authors:    []string{"Geoffrey Hinton", "LeCun, Y."},
+		Categories: []string{"cs"},
+		OpenAccess: true,
+	}
+
+	kept, filters := filterPapers(papers, params, nil)
+	var titles []string
+	for _, p := range kept {
+		titles = append(titles, p.Title)
+	}
+	if want := []string{"in range", "year overlaps", "undated"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("Kept %v, want %v", titles, want)
+	}
+	if want := []string{"date", "authors", "categories", "open_access"}; !reflect.DeepEqual(filters, want) {
+		t.Errorf("Applied %v, want %v", filters, want)
+	}
+
+	// Filters the provider applied are not applied again
+	kept, filters = filterPapers(papers, params, []string{"date", "authors", "categories"})
+	if len(kept) != 6 || !reflect.DeepEqual(filters, []string{"open_access"}) {
+		t.Errorf("Expected only the open access filter, kept %d with %v", len(kept), filters)
+	}
+	if kept, filters = filterPapers(papers, ResearchPaperAPIParams{}, nil); len(kept) != len(papers) || filters != nil {
+		t.Errorf("Expected no filtering without filters, kept %d with %v", len(kept), filters)
+	}
+}
+
+func TestAuthorAndCategoryMatching(t *testing.T) {
+	t.Parallel()
+
+	authors := []struct {
+		author, requested string
+		want              bool
+	}{
+		{"Geoffrey E. Hinton", "Geoffrey Hinton", true},
+		{"Hinton, G.", "geoffrey hinton", true},
+		{"Hinton", "Geoffrey Hinton", true},
+		{"Émile Borel", "émile BOREL", true},
+		{"Carl Hinton", "Geoffrey Hinton", false},
+		{"Geoffrey Hinton", "Geoffrey Hint", false},
+		{"", "Hinton", false},
+	}
+	for _, tt := range authors {
+		if got := authorMatches(tt.author, tt.requested); got != tt.want {
+			t.Errorf("authorMatches(%q, %q) = %v", tt.author, tt.requested, got)
+		}
+	}
+
+	categories := []struct {
+		category, field string
+		want            bool
+	}{
+		{"cs", "Computer Science", true},
+		{"astro-ph", "Physics and Astronomy", true},
+		{"q-bio", "Biochemistry, Genetics and Molecular Biology", true},
+		{"Law", "law", true},
+		{"medicine", "Computer Science", false},
+	}
+	for _, tt := range categories {
+		if got := categoryMatches(tt.category, tt.field); got != tt.want {
+			t.Errorf("categoryMatches(%q, %q) = %v", tt.category, tt.field, got)
+		}
+	}
+}
+
+func TestResearchPaperAPIHandler_Filters(t *testing.T) {
+	t.Parallel()
+
+	var arxivQuery string
+	arxivServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arxivQuery = r.URL.Query().Get("search_query")
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<entry>
+		<id>http://arxiv.org/abs/1706.03762v7</id>
+		<title>Attention Is All You Need</title>
+		<author><name>Ashish Vaswani</name></author>
+		<published>2017-06-12T17:57:34Z</published>
+	</entry>
+</feed>`)
+	}))
+	defer arxivServer.Close()
+
+	var s2Query string
+	s2Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s2Query = r.URL.RawQuery
+		fmt.Fprint(w, semanticScholarResponse)
+	}))
+	defer s2Server.Close()
+
+	cfg := newToolConfig(
+		WithProviderBaseURL("arxiv", arxivServer.URL),
+		WithProviderBaseURL("semanticscholar", s2Server.URL),
+	)
+	result, err := cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{
+		Query:     "attention",
+		StartDate: "2017-01-01",
+		Authors:   []string{"Noam Shazeer"},
+		Providers: []string{"arxiv", "semanticscholar"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(arxivQuery, `submittedDate:[201701010000 TO `) || !strings.Contains(arxivQuery, `au:"Noam Shazeer"`) {
+		t.Errorf("Expected native arXiv filters, got %q", arxivQuery)
+	}
+	if !strings.Contains(s2Query, "publicationDateOrYear=2017-01-01%3A") {
+		t.Errorf("Expected a native date filter, got %q", s2Query)
+	}
+
+	infos := make(map[string]ProviderInfo)
+	for _, info := range result.Providers {
+		infos[info.Name] = info
+	}
+	if info := infos["arxiv"]; !reflect.DeepEqual(info.NativeFilters, []string{"date", "authors"}) || info.PostFilters != nil || info.ResultCount != 1 {
+		t.Errorf("Unexpected arXiv info %+v", info)
+	}
+	// Semantic Scholar cannot filter authors: the paper by Shazeer is kept,
+	// the one without authors is kept as unknown
+	if info := infos["semanticscholar"]; !reflect.DeepEqual(info.NativeFilters, []string{"date"}) || !reflect.DeepEqual(info.PostFilters, []string{"authors"}) || info.ResultCount != 2 || info.FilteredOut != 0 {
+		t.Errorf("Unexpected Semantic Scholar info %+v", info)
+	}
+
+	result, err = cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{
+		Query:     "attention",
+		Authors:   []string{"Ashish Vaswani"},
+		Providers: []string{"semanticscholar"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info := result.Providers[0]; info.ResultCount != 2 || info.FilteredOut != 0 {
+		t.Errorf("Unexpected info %+v", info)
+	}
+	result, err = cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{
+		Query:     "attention",
+		Authors:   []string{"Yoshua Bengio"},
+		Providers: []string{"semanticscholar"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info := result.Providers[0]; info.ResultCount != 1 || info.FilteredOut != 1 || result.Papers[0].Title != "A Workshop Note" {
+		t.Errorf("Expected the paper by other authors filtered out, got %+v", result)
+	}
+}
+
+func TestResearchPaperAPIHandler_InvalidDates(t *testing.T) {
+	t.Parallel()
+
+	cfg := newToolConfig()
+	for _, params := range []ResearchPaperAPIParams{
+		{Query: "x", StartDate: "2023-13-01"},
+		{Query: "x", EndDate: "01/02/2023"},
+		{Query: "x", StartDate: "2023-02-01", EndDate: "2023-01-01"},
+	} {
+		if _, err := cfg.researchPaperAPIHandler(context.Background(), params); err == nil {
+			t.Errorf("Expected an error for %+v", params)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// ABOUTME: OpenAlex provider for the research_paper_api tool
+// ABOUTME: Searches the OpenAlex works index for papers with citation counts, venues, fields and open access PDFs
+
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultOpenAlexURL is the works endpoint of the OpenAlex API (override
+// with WithProviderBaseURL("openalex", ...))
+const defaultOpenAlexURL = "https://api.openalex.org/works"
+
+// openAlexProvider searches OpenAlex, an open index of scholarly works across
+// all subjects. An API key is optional.
+type openAlexProvider struct{}
+
+func (openAlexProvider) Name() string { return "openalex" }
+
+func (openAlexProvider) Capabilities() ResearchCapabilities {
+	return ResearchCapabilities{Source: "OpenAlex", DateFilter: true, OpenAccessFilter: true}
+}
+
+// openAlexFilter returns the filter parameter for the filters of params.
+// Authors and fields are filtered on by ID in OpenAlex, so the tool
+// post-filters those by name.
+func openAlexFilter(env *ProviderEnv, params ResearchPaperAPIParams) string {
+	var filters []string
+	if params.StartDate != "" {
+		filters = append(filters, "from_publication_date:"+params.StartDate)
+	}
+	if params.EndDate != "" {
+		filters = append(filters, "to_publication_date:"+params.EndDate)
+	}
+	if len(filters) > 0 {
+		env.MarkNative(ResearchFilterDate)
+	}
+	if params.OpenAccess {
+		filters = append(filters, "is_oa:true")
+		env.MarkNative(ResearchFilterOpenAccess)
+	}
+	return strings.Join(filters, ",")
+}
+
+func (openAlexProvider) Search(ctx context.Context, env *ProviderEnv, params ResearchPaperAPIParams) ([]ResearchPaper, error) {
+	query := url.Values{}
+	query.Set("search", params.Query)
+	query.Set("per_page", strconv.Itoa(min(params.MaxResults, 200)))
+	if filter := openAlexFilter(env, params); filter != "" {
+		query.Set("filter", filter)
+	}
+
+	apiKey, err := env.APIKey(ctx, CredentialOpenAlex)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != "" {
+		query.Set("api_key", apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", env.Endpoint(defaultOpenAlexURL)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	client := env.Client(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var worksResp struct {
+		Results []openAlexWork `json:"results"`
+	}
+	body, err := readWholeBody(resp, env.ResponseLimit(DefaultMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if err := json.Unmarshal(body, &worksResp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	papers := make([]ResearchPaper, 0, len(worksResp.Results))
+	for _, work := range worksResp.Results {
+		papers = append(papers, work.toResearchPaper())
+	}
+	return papers, nil
+}
+
+// openAlexLocation is where a work is hosted
+type openAlexLocation struct {
+	LandingPageURL string `json:"landing_page_url"`
+	PDFURL         string `json:"pdf_url"`
+	Source         *struct {
+		DisplayName string `json:"display_name"`
+		Type        string `json:"type"`
+	} `json:"source"`
+}
+
+// openAlexWork is a work as returned by the OpenAlex API
+type openAlexWork struct {
+	ID              string `json:"id"`
+	DOI             string `json:"doi"`
+	Title           string `json:"title"`
+	DisplayName     string `json:"display_name"`
+	PublicationDate string `json:"publication_date"`
+	IDs             struct {
+		PMID string `json:"pmid"`
+	} `json:"ids"`
+	Authorships []struct {
+		Author struct {
+			DisplayName string `json:"display_name"`
+		} `json:"author"`
+	} `json:"authorships"`
+	PrimaryLocation *openAlexLocation `json:"primary_location"`
+	BestOALocation  *openAlexLocation `json:"best_oa_location"`
+	OpenAccess      struct {
+		IsOA bool `json:"is_oa"`
+	} `json:"open_access"`
+	CitedByCount          *int             `json:"cited_by_count"`
+	AbstractInvertedIndex map[string][]int `json:"abstract_inverted_index"`
+	RelevanceScore        float64          `json:"relevance_score"`
+	Topics                []struct {
+		Field struct {
+			DisplayName string `json:"display_name"`
+		} `json:"field"`
+	} `json:"topics"`
+}
+
+func (w openAlexWork) toResearchPaper() ResearchPaper {
+	title := w.Title
+	if title == "" {
+		title = w.DisplayName
+	}
+
+	authors := make([]string, len(w.Authorships))
+	for i, a := range w.Authorships {
+		authors[i] = a.Author.DisplayName
+	}
+
+	var fields []string
+	for _, topic := range w.Topics {
+		if f := topic.Field.DisplayName; f != "" && !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+
+	paper := ResearchPaper{
+		Title:          title,
+		Authors:        authors,
+		Abstract:       invertedIndexText(w.AbstractInvertedIndex),
+		PublishedDate:  w.PublicationDate,
+		Source:         "OpenAlex",
+		URL:            w.ID,
+		DOI:            strings.TrimPrefix(w.DOI, "https://doi.org/"),
+		PubMedID:       strings.TrimPrefix(w.IDs.PMID, "https://pubmed.ncbi.nlm.nih.gov/"),
+		CitationCount:  w.CitedByCount,
+		FieldsOfStudy:  fields,
+		OpenAccess:     w.OpenAccess.IsOA,
+		RelevanceScore: w.RelevanceScore,
+	}
+
+	if loc := w.PrimaryLocation; loc != nil {
+		if loc.LandingPageURL != "" {
+			paper.URL = loc.LandingPageURL
+		}
+		paper.PDFURL = loc.PDFURL
+		if loc.Source != nil {
+			paper.Venue = loc.Source.DisplayName
+			if loc.Source.Type == "journal" {
+				paper.Journal = loc.Source.DisplayName
+			}
+		}
+	}
+	if loc := w.BestOALocation; loc != nil && loc.PDFURL != "" {
+		paper.PDFURL = loc.PDFURL
+	}
+	return paper
+}
+
+// invertedIndexText rebuilds text from an OpenAlex inverted index, which maps
+// each word to the positions it appears at. Words are sorted by position
+// rather than placed in a slice indexed by it, so a huge position sent by the
+// server costs no more memory than a small one.
+func invertedIndexText(index map[string][]int) string {
+	type placedWord struct {
+		pos  int
+		word string
+	}
+	var placed []placedWord
+	for word, positions := range index {
+		for _, pos := range positions {
+			if pos >= 0 {
+				placed = append(placed, placedWord{pos, word})
+			}
+		}
+	}
+	slices.SortFunc(placed, func(a, b placedWord) int {
+		return cmp.Or(cmp.Compare(a.pos, b.pos), strings.Compare(a.word, b.word))
+	})
+
+	words := make([]string, 0, len(placed))
+	for i, p := range placed {
+		if i > 0 && p.pos == placed[i-1].pos {
+			continue // One word per position
+		}
+		words = append(words, p.word)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,121 @@
+// ABOUTME: Unit tests for the OpenAlex research provider
+// ABOUTME: Tests request parameters, API keys, abstract reconstruction and field mapping
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const openAlexResponse = `{
+	"meta": {"count": 1, "page": 1, "per_page": 25},
+	"results": [
+		{
+			"id": "https://openalex.org/W2741809807",
+			"doi": "https://doi.org/10.7717/peerj.4375",
+			"title": "The state of OA: a large-scale analysis",
+			"display_name": "The state of OA: a large-scale analysis",
+			"relevance_score": 912.5,
+			"publication_year": 2018,
+			"publication_date": "2018-02-13",
+			"ids": {"openalex": "https://openalex.org/W2741809807", "doi": "https://doi.org/10.7717/peerj.4375", "pmid": "https://pubmed.ncbi.nlm.nih.gov/29456894"},
+			"primary_location": {
+				"landing_page_url": "https://peerj.com/articles/4375",
+				"pdf_url": null,
+				"source": {"display_name": "PeerJ", "type": "journal"}
+			},
+			"best_oa_location": {"landing_page_url": "https://peerj.com/articles/4375", "pdf_url": "https://peerj.com/articles/4375.pdf"},
+			"open_access": {"is_oa": true, "oa_status": "gold"},
+			"authorships": [{"author": {"display_name": "Heather Piwowar"}}, {"author": {"display_name": "Jason Priem"}}],
+			"cited_by_count": 1024,
+			"abstract_inverted_index": {"Despite": [0], "growing": [1], "interest": [2], "in": [3, 5], "OA": [4], "practice": [6]},
+			"topics": [
+				{"display_name": "scientometrics", "field": {"display_name": "Computer Science"}},
+				{"display_name": "open science", "field": {"display_name": "Social Sciences"}},
+				{"display_name": "citation analysis", "field": {"display_name": "Computer Science"}}
+			]
+		}
+	]
+}`
+
+func TestOpenAlexProvider(t *testing.T) {
+	t.Parallel()
+
+	var gotQuery map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, openAlexResponse)
+	}))
+	defer server.Close()
+
+	cfg := newToolConfig(
+		WithProviderBaseURL("openalex", server.URL+"/works"),
+		WithProviderAPIKey("openalex", "oa-key"),
+	)
+	result, err := cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{
+		Query:     "open access",
+		Providers: []string{"openalex"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string][]string{"search": {"open access"}, "per_page": {"10"}, "api_key": {"oa-key"}}
+	if !reflect.DeepEqual(gotQuery, want) {
+		t.Errorf("Unexpected query %v, want %v", gotQuery, want)
+	}
+	if len(result.Papers) != 1 || result.Providers[0].Error != "" {
+		t.Fatalf("Expected one paper, got %+v", result)
+	}
+
+	paper := result.Papers[0]
+	expected := ResearchPaper{
+		Title:          "The state of OA: a large-scale analysis",
+		Authors:        []string{"Heather Piwowar", "Jason Priem"},
+		Abstract:       "Despite growing interest in OA in practice",
+		PublishedDate:  "2018-02-13",
+		Source:         "OpenAlex",
+		URL:            "https://peerj.com/articles/4375",
+		PDFURL:         "https://peerj.com/articles/4375.pdf",
+		DOI:            "10.7717/peerj.4375",
+		PubMedID:       "29456894",
+		Journal:        "PeerJ",
+		Venue:          "PeerJ",
+		CitationCount:  intPtr(1024),
+		FieldsOfStudy:  []string{"Computer Science", "Social Sciences"},
+		OpenAccess:     true,
+		RelevanceScore: 1, // The top rank, whatever the raw score
+	}
+	if !reflect.DeepEqual(paper, expected) {
+		t.Errorf("Unexpected paper:\n%+v\nwant:\n%+v", paper, expected)
+	}
+}
+
+func TestInvertedIndexText(t *testing.T) {
+	t.Parallel()
+
+	if got := invertedIndexText(map[string][]int{"b": {1}, "a": {0, 3}, "c": {2}, "bad": {-1}}); got != "a b c a" {
+		t.Errorf("Unexpected text %q", got)
+	}
+	// Gaps in the positions are skipped
+	if got := invertedIndexText(map[string][]int{"first": {0}, "last": {4}}); got != "first last" {
+		t.Errorf("Unexpected text %q", got)
+	}
+	// A huge position allocates nothing in proportion to it
+	if got := invertedIndexText(map[string][]int{"end": {math.MaxInt}, "start": {0}}); got != "start end" {
+		t.Errorf("Unexpected text %q", got)
+	}
+	// One word per position
+	if got := invertedIndexText(map[string][]int{"b": {0}, "a": {0}, "c": {1}}); got != "a c" {
+		t.Errorf("Unexpected text %q", got)
+	}
+	if got := invertedIndexText(nil); got != "" {
+		t.Errorf("Expected empty text, got %q", got)
+	}
+}
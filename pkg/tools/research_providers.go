@@ -0,0 +1,250 @@
+// ABOUTME: Pluggable research providers searched by the research_paper_api tool
+// ABOUTME: Defines the ResearchProvider interface, its per-tool environment and the registry the tool schema derives from
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	sdomain "github.com/lexlapax/go-llms/pkg/schema/domain"
+)
+
+// ResearchProvider is a source of academic papers for research_paper_api,
+// such as arXiv or an in-house repository. Implementations must be safe for
+// concurrent use.
+type ResearchProvider interface {
+	// Name identifies the provider in the "providers" parameter, e.g. "arxiv"
+	Name() string
+	// Capabilities describes the provider's coverage and native filters
+	Capabilities() ResearchCapabilities
+	// Search returns papers matching params. Papers read before an error
+	// may be returned along with it.
+	Search(ctx context.Context, env *ProviderEnv, params ResearchPaperAPIParams) ([]ResearchPaper, error)
+}
+
+// ResearchCapabilities describes what a research provider covers and which
+// filters it can apply in its own query syntax. Whether a filter was applied
+// to a particular search is reported with ProviderEnv.MarkNative.
+type ResearchCapabilities struct {
+	Source     string   // Display name, also set as ResearchPaper.Source, e.g. "arXiv"
+	Categories []string // Subject categories the provider specializes in; none for general-purpose providers
+
+	DateFilter       bool // Applies start_date and end_date
+	AuthorFilter     bool // Applies authors
+	CategoryFilter   bool // Applies categories
+	OpenAccessFilter bool // Applies open_access
+}
+
+// ProviderEnv gives a provider the configuration of the tool searching it:
+// its HTTP client, endpoint overrides, API keys and response size limit
+type ProviderEnv struct {
+	cfg      *toolConfig
+	provider string
+
+	mu     sync.Mutex
+	native []string // Filters the provider applied, see MarkNative
+}
+
+// MarkNative records filters (ResearchFilterDate, ResearchFilterAuthors, ...)
+// the provider applied in its query. The tool post-filters the papers on the
+// requested filters that were not marked.
+func (e *ProviderEnv) MarkNative(filters ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, f := range filters {
+		if !slices.Contains(e.native, f) {
+			e.native = append(e.native, f)
+		}
+	}
+}
+
+// nativeFilters returns the filters marked by the provider
+func (e *ProviderEnv) nativeFilters() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.native)
+}
+
+// Client returns an HTTP client with the given timeout that honors the
+// tool's transport, cache and URL policy
+func (e *ProviderEnv) Client(timeout time.Duration) *http.Client {
+	return e.cfg.client(timeout)
+}
+
+// Endpoint returns the URL set for the provider with WithProviderBaseURL,
+// or defaultURL
+func (e *ProviderEnv) Endpoint(defaultURL string) string {
+	return e.cfg.providerEndpoint(e.provider, defaultURL)
+}
+
+// APIKey resolves the provider's API key: a key carried by the context,
+// then one set with WithProviderAPIKey, then the credential provider's
+// value for name. A missing key yields an empty string and no error.
+func (e *ProviderEnv) APIKey(ctx context.Context, name string) (string, error) {
+	return e.cfg.credential(ctx, name, e.cfg.providerKeys[e.provider])
+}
+
+// ResponseLimit returns the maximum number of response bytes to read
+func (e *ProviderEnv) ResponseLimit(defaultLimit int64) int64 {
+	return e.cfg.responseLimit(defaultLimit)
+}
+
+// ResearchProviderRegistry holds the providers research_paper_api can
+// search, in the order they are searched and listed. It is safe for
+// concurrent use.
+type ResearchProviderRegistry struct {
+	mu        sync.RWMutex
+	providers []ResearchProvider
+}
+
+// NewResearchProviderRegistry creates an empty registry
+func NewResearchProviderRegistry() *ResearchProviderRegistry {
+	return &ResearchProviderRegistry{}
+}
+
+// Register adds a provider. Names are case-insensitive and must be unique.
+func (r *ResearchProviderRegistry) Register(provider ResearchProvider) error {
+	name := provider.Name()
+	if strings.TrimSpace(name) == "" {
+		return errors.New("research provider name is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.providers {
+		if strings.EqualFold(p.Name(), name) {
+			return fmt.Errorf("research provider %q already registered", name)
+		}
+	}
+	r.providers = append(r.providers, provider)
+	return nil
+}
+
+// Provider returns the provider with the given name
+func (r *ResearchProviderRegistry) Provider(name string) (ResearchProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.providers {
+		if strings.EqualFold(p.Name(), name) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Providers returns the registered providers in registration order
+func (r *ResearchProviderRegistry) Providers() []ResearchProvider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.providers)
+}
+
+// Names returns the names of the registered providers
+func (r *ResearchProviderRegistry) Names() []string {
+	providers := r.Providers()
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// Clone returns a registry with the same providers, e.g. to add in-house
+// sources to the built-in ones for a single tool
+func (r *ResearchProviderRegistry) Clone() *ResearchProviderRegistry {
+	return &ResearchProviderRegistry{providers: r.Providers()}
+}
+
+// defaultResearchProviders holds the built-in providers and is used by tools
+// not given a registry with WithResearchProviders
+var defaultResearchProviders = &ResearchProviderRegistry{
+	providers: []ResearchProvider{
+		arxivProvider{},
+		pubMedProvider{},
+		coreProvider{},
+		semanticScholarProvider{},
+		openAlexProvider{},
+	},
+}
+
+// DefaultResearchProviders returns the registry shared by research tools.
+// Providers registered with it are available to tools created afterwards.
+func DefaultResearchProviders() *ResearchProviderRegistry {
+	return defaultResearchProviders
+}
+
+// researchProviders returns the registry the tool searches
+func (c *toolConfig) researchProviders() *ResearchProviderRegistry {
+	if c.researchRegistry != nil {
+		return c.researchRegistry
+	}
+	return defaultResearchProviders
+}
+
+// selectProviders chooses which providers to search. Without explicit
+// providers, categories narrow the search to the providers specializing in
+// them plus the general-purpose ones.
+func selectProviders(registry *ResearchProviderRegistry, params ResearchPaperAPIParams) []string {
+	if len(params.Providers) > 0 {
+		return params.Providers
+	}
+
+	var all, selected []string
+	for _, p := range registry.Providers() {
+		all = append(all, p.Name())
+		covered := p.Capabilities().Categories
+		if len(covered) == 0 || slices.ContainsFunc(params.Categories, func(cat string) bool {
+			return slices.ContainsFunc(covered, func(c string) bool { return strings.EqualFold(c, cat) })
+		}) {
+			selected = append(selected, p.Name())
+		}
+	}
+
+	if len(params.Categories) == 0 || len(selected) == 0 {
+		return all
+	}
+	return selected
+}
+
+// researchPaperAPIParamSchema returns the parameter schema with the
+// providers of the registry
+func researchPaperAPIParamSchema(registry *ResearchProviderRegistry) *sdomain.Schema {
+	names := registry.Names()
+	description := fmt.Sprintf("Specific providers to search (%s)", strings.Join(names, ", "))
+
+	schema := *researchPaperAPIBaseSchema
+	schema.Properties = make(map[string]sdomain.Property, len(researchPaperAPIBaseSchema.Properties))
+	for key, prop := range researchPaperAPIBaseSchema.Properties {
+		schema.Properties[key] = prop
+	}
+	schema.Properties["providers"] = sdomain.Property{
+		Type:        "array",
+		Description: description,
+		Items: &sdomain.Property{
+			Type: "string",
+			Enum: names,
+		},
+	}
+	return &schema
+}
+
+// researchPaperAPIDescription returns the tool description naming the
+// sources of the registry
+func researchPaperAPIDescription(registry *ResearchProviderRegistry) string {
+	var sources []string
+	for _, p := range registry.Providers() {
+		source := p.Capabilities().Source
+		if source == "" {
+			source = p.Name()
+		}
+		sources = append(sources, source)
+	}
+	return fmt.Sprintf("Searches for academic papers across multiple research databases (%s) in parallel", strings.Join(sources, ", "))
+}
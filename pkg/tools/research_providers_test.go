@@ -0,0 +1,156 @@
+// ABOUTME: Unit tests for pluggable research providers
+// ABOUTME: Tests registering custom providers, the provider environment, provider selection and the derived schema
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// repositoryProvider is an in-house repository searched over a JSON API
+type repositoryProvider struct {
+	name       string
+	categories []string
+}
+
+func (p repositoryProvider) Name() string { return p.name }
+
+func (p repositoryProvider) Capabilities() ResearchCapabilities {
+	return ResearchCapabilities{Source: "Lab Repository", Categories: p.categories}
+}
+
+func (p repositoryProvider) Search(ctx context.Context, env *ProviderEnv, params ResearchPaperAPIParams) ([]ResearchPaper, error) {
+	key, err := env.APIKey(ctx, "LAB_REPOSITORY_KEY")
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", env.Endpoint("https://repository.invalid/search")+"?q="+params.Query, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Key", key)
+	resp, err := env.Client(0).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var titles []string
+	if err := json.NewDecoder(resp.Body).Decode(&titles); err != nil {
+		return nil, err
+	}
+	papers := make([]ResearchPaper, len(titles))
+	for i, title := range titles {
+		papers[i] = ResearchPaper{Title: title, Source: "Lab Repository"}
+	}
+	return papers, nil
+}
+
+func TestResearchProviders_CustomProvider(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Key") != "secret" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode([]string{"Internal report on " + r.URL.Query().Get("q")})
+	}))
+	defer server.Close()
+
+	registry := NewResearchProviderRegistry()
+	if err := registry.Register(repositoryProvider{name: "lab"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cfg := newToolConfig(
+		WithResearchProviders(registry),
+		WithProviderBaseURL("lab", server.URL),
+		WithProviderAPIKey("lab", "secret"),
+	)
+
+	result, err := cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{Query: "turbines"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Papers) != 1 || result.Papers[0].Title != "Internal report on turbines" {
+		t.Errorf("Expected the custom provider's paper, got %+v", result.Papers)
+	}
+	if len(result.Providers) != 1 || result.Providers[0].Name != "lab" || result.Providers[0].Error != "" {
+		t.Errorf("Unexpected provider info: %+v", result.Providers)
+	}
+
+	// Providers outside the registry are reported, not silently skipped
+	result, err = cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{Query: "turbines", Providers: []string{"arxiv"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Providers) != 1 || !strings.Contains(result.Providers[0].Error, "unknown research provider") {
+		t.Errorf("Expected an unknown provider error, got %+v", result.Providers)
+	}
+}
+
+func TestResearchProviderRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry := DefaultResearchProviders().Clone()
+	if err := registry.Register(repositoryProvider{name: "lab", categories: []string{"law"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := registry.Register(repositoryProvider{name: "ArXiv"}); err == nil {
+		t.Error("Expected error for a duplicate name")
+	}
+	if err := registry.Register(repositoryProvider{name: " "}); err == nil {
+		t.Error("Expected error for an empty name")
+	}
+
+	if got := registry.Names(); !reflect.DeepEqual(got, []string{"arxiv", "pubmed", "core", "semanticscholar", "openalex", "lab"}) {
+		t.Errorf("Unexpected names %v", got)
+	}
+	if got := DefaultResearchProviders().Names(); !reflect.DeepEqual(got, []string{"arxiv", "pubmed", "core", "semanticscholar", "openalex"}) {
+		t.Errorf("Expected the default registry unchanged by its clone, got %v", got)
+	}
+	if p, ok := registry.Provider("LAB"); !ok || p.Name() != "lab" {
+		t.Error("Expected case-insensitive lookup")
+	}
+
+	tests := []struct {
+		categories []string
+		want       []string
+	}{
+		{nil, []string{"arxiv", "pubmed", "core", "semanticscholar", "openalex", "lab"}},
+		{[]string{"cs"}, []string{"arxiv", "core", "semanticscholar", "openalex"}},
+		{[]string{"Medicine", "stat"}, []string{"arxiv", "pubmed", "core", "semanticscholar", "openalex"}},
+		{[]string{"law"}, []string{"core", "semanticscholar", "openalex", "lab"}},
+		{[]string{"poetry"}, []string{"core", "semanticscholar", "openalex"}},
+	}
+	for _, tt := range tests {
+		got := selectProviders(registry, ResearchPaperAPIParams{Categories: tt.categories})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("selectProviders(%v) = %v, want %v", tt.categories, got, tt.want)
+		}
+	}
+	if got := selectProviders(registry, ResearchPaperAPIParams{Categories: []string{"cs"}, Providers: []string{"lab"}}); !reflect.DeepEqual(got, []string{"lab"}) {
+		t.Errorf("Expected explicit providers to win, got %v", got)
+	}
+
+	// The schema and description list the registry's providers
+	tool := NewResearchPaperAPITool(WithResearchProviders(registry))
+	providers := tool.ParameterSchema().Properties["providers"]
+	if !reflect.DeepEqual(providers.Items.Enum, []string{"arxiv", "pubmed", "core", "semanticscholar", "openalex", "lab"}) {
+		t.Errorf("Unexpected providers enum %v", providers.Items.Enum)
+	}
+	want := "Searches for academic papers across multiple research databases (arXiv, PubMed, CORE, Semantic Scholar, OpenAlex, Lab Repository) in parallel"
+	if tool.Description() != want {
+		t.Errorf("Unexpected description %q", tool.Description())
+	}
+	if enum := ResearchPaperAPIParamSchema.Properties["providers"].Items.Enum; fmt.Sprint(enum) != "[arxiv pubmed core semanticscholar openalex]" {
+		t.Errorf("Expected the exported schema to list the built-in providers, got %v", enum)
+	}
+}
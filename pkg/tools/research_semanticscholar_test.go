@@ -0,0 +1,190 @@
+// ABOUTME: Unit tests for the Semantic Scholar research provider
+// ABOUTME: Tests request parameters, API keys, field mapping and merging with duplicates from other providers
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const semanticScholarResponse = `{
+	"total": 2,
+	"offset": 0,
+	"data": [
+		{
+			"paperId": "204e3073870fae3d05bcbc2f6a8e263d9b72e776",
+			"externalIds": {"DOI": "10.5555/3295222.3295349", "ArXiv": "1706.03762", "DBLP": "conf/nips/VaswaniSPUJGKP17"},
+			"url": "https://www.semanticscholar.org/paper/204e3073870fae3d05bcbc2f6a8e263d9b72e776",
+			"title": "Attention is All you Need",
+			"abstract": "The dominant sequence transduction models are based on complex recurrent networks.",
+			"venue": "Neural Information Processing Systems",
+			"year": 2017,
+			"publicationDate": "2017-06-12",
+			"journal": {"name": "Advances in Neural Information Processing Systems", "volume": "30"},
+			"citationCount": 120000,
+			"isOpenAccess": false,
+			"openAccessPdf": null,
+			"fieldsOfStudy": ["Computer Science"],
+			"authors": [{"authorId": "40348417", "name": "Ashish Vaswani"}, {"authorId": "1846258", "name": "Noam Shazeer"}]
+		},
+		{
+			"paperId": "abc123",
+			"externalIds": {},
+			"url": null,
+			"title": "A Workshop Note",
+			"abstract": null,
+			"venue": "",
+			"year": 2021,
+			"publicationDate": null,
+			"journal": null,
+			"citationCount": 0,
+			"isOpenAccess": true,
+			"openAccessPdf": {"url": "https://example.org/note.pdf", "status": "GREEN"},
+			"fieldsOfStudy": null,
+			"authors": []
+		}
+	]
+}`
+
+func TestSemanticScholarProvider(t *testing.T) {
+	t.Parallel()
+
+	var gotQuery, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		gotKey = r.Header.Get("x-api-key")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, semanticScholarResponse)
+	}))
+	defer server.Close()
+
+	cfg := newToolConfig(
+		WithProviderBaseURL("semanticscholar", server.URL+"/graph/v1/paper/search"),
+		WithProviderAPIKey("semanticscholar", "s2-key"),
+	)
+	result, err := cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{
+		Query:      "transformers",
+		MaxResults: 500,
+		Providers:  []string{"semanticscholar"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotKey != "s2-key" {
+		t.Errorf("Expected the API key header, got %q", gotKey)
+	}
+	for _, want := range []string{"query=transformers", "limit=100", "citationCount"} {
+		if !strings.Contains(gotQuery, want) {
+			t.Errorf("Expected %q in query %q", want, gotQuery)
+		}
+	}
+
+	if len(result.Papers) != 2 || result.Providers[0].Error != "" {
+		t.Fatalf("Expected 2 papers, got %+v", result)
+	}
+	paper := result.Papers[0]
+	if paper.Source != "Semantic Scholar" || paper.DOI != "10.5555/3295222.3295349" || paper.ArxivID != "1706.03762" {
+		t.Errorf("Unexpected identifiers: %+v", paper)
+	}
+	if paper.CitationCount == nil || *paper.CitationCount != 120000 {
+		t.Errorf("Expected citation count 120000, got %v", paper.CitationCount)
+	}
+	if paper.Venue != "Neural Information Processing Systems" || paper.Journal != "Advances in Neural Information Processing Systems" {
+		t.Errorf("Unexpected venue %q / journal %q", paper.Venue, paper.Journal)
+	}
+	if !reflect.DeepEqual(paper.Authors, []string{"Ashish Vaswani", "Noam Shazeer"}) || !reflect.DeepEqual(paper.FieldsOfStudy, []string{"Computer Science"}) {
+		t.Errorf("Unexpected authors %v or fields %v", paper.Authors, paper.FieldsOfStudy)
+	}
+	if paper.PublishedDate != "2017-06-12" || paper.PDFURL != "" || paper.OpenAccess {
+		t.Errorf("Unexpected date %q, PDF %q or open access %v", paper.PublishedDate, paper.PDFURL, paper.OpenAccess)
+	}
+
+	note := result.Papers[1]
+	if note.PublishedDate != "2021" || note.PDFURL != "https://example.org/note.pdf" || !note.OpenAccess {
+		t.Errorf("Unexpected year-only paper: %+v", note)
+	}
+	if note.CitationCount == nil || *note.CitationCount != 0 {
+		t.Errorf("Expected a known count of zero citations, got %v", note.CitationCount)
+	}
+	if note.URL != "https://www.semanticscholar.org/paper/abc123" {
+		t.Errorf("Expected a URL built from the paper ID, got %q", note.URL)
+	}
+}
+
+func TestSemanticScholarProvider_Errors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "" {
+			t.Errorf("Expected no API key header without a key, got %q", r.Header.Get("x-api-key"))
+		}
+		http.Error(w, `{"message": "Too Many Requests"}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	cfg := newToolConfig(
+		WithProviderBaseURL("semanticscholar", server.URL),
+		WithCredentialProvider(EnvCredentialProvider{Prefix: "FLOCK_TEST_UNSET_"}),
+	)
+	result, err := cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{Query: "x", Providers: []string{"semanticscholar"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Providers[0].Error, "status 403") {
+		t.Errorf("Expected the API error in the provider info, got %+v", result.Providers)
+	}
+}
+
+func TestResearchPaperAPIHandler_MergesDuplicates(t *testing.T) {
+	t.Parallel()
+
+	arxivServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<entry>
+		<id>http://arxiv.org/abs/1706.03762v7</id>
+		<title>Attention Is All You Need</title>
+		<summary>The dominant sequence transduction models...</summary>
+		<author><name>Ashish Vaswani</name></author>
+		<published>2017-06-12T17:57:34Z</published>
+		<link href="http://arxiv.org/pdf/1706.03762v7" rel="related" type="application/pdf"/>
+	</entry>
+</feed>`)
+	}))
+	defer arxivServer.Close()
+
+	s2Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, semanticScholarResponse)
+	}))
+	defer s2Server.Close()
+
+	cfg := newToolConfig(
+		WithProviderBaseURL("arxiv", arxivServer.URL),
+		WithProviderBaseURL("semanticscholar", s2Server.URL),
+	)
+	result, err := cfg.researchPaperAPIHandler(context.Background(), ResearchPaperAPIParams{
+		Query:     "attention",
+		Providers: []string{"arxiv", "semanticscholar"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Papers) != 2 {
+		t.Fatalf("Expected the shared paper once, got %d papers", len(result.Papers))
+	}
+	for _, paper := range result.Papers {
+		if !strings.EqualFold(paper.Title, "Attention is All you Need") {
+			continue
+		}
+		// Whichever copy is kept carries details from both
+		if paper.CitationCount == nil || *paper.CitationCount != 120000 || paper.PDFURL == "" || paper.DOI == "" || paper.ArxivID == "" {
+			t.Errorf("Expected merged details, got %+v", paper)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+// ABOUTME: robots.txt fetching, parsing and crawl politeness for web tools
+// ABOUTME: Caches rules per host, matches the go-flock user agent and honors Crawl-delay
+
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RobotsUserAgent is the product token matched against User-agent lines in
+// robots.txt, regardless of the User-Agent header a tool sends
+const RobotsUserAgent = "go-flock"
+
+// maxRobotsSize caps how much of a robots.txt file is parsed
+const maxRobotsSize = 500 * 1024
+
+// RobotsDisallowedError is returned when robots.txt forbids fetching a URL
+type RobotsDisallowedError struct {
+	URL    string
+	Reason string
+}
+
+func (e *RobotsDisallowedError) Error() string {
+	return fmt.Sprintf("fetching %s disallowed by robots.txt: %s", e.URL, e.Reason)
+}
+
+// RobotsChecker fetches and caches robots.txt per host and spaces requests
+// to each host according to its Crawl-delay. It is safe for concurrent use.
+type RobotsChecker struct {
+	UserAgent string        // Product token to match; defaults to RobotsUserAgent
+	TTL       time.Duration // How long rules are cached; defaults to 24 hours
+
+	// UnavailableTTL is how long a server error for robots.txt, which
+	// disallows the whole host, is cached; defaults to 5 minutes so a
+	// transient outage does not block the host for the full TTL
+	UnavailableTTL time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*robotsHost
+}
+
+type robotsHost struct {
+	mu        sync.Mutex // Serializes fetching robots.txt for the host
+	rules     *robotsRules
+	expiresAt time.Time
+	nextFetch time.Time // Earliest time the next request may start
+}
+
+// NewRobotsChecker creates a RobotsChecker with default settings
+func NewRobotsChecker() *RobotsChecker {
+	return &RobotsChecker{}
+}
+
+// defaultRobotsChecker is shared by all tools unless overridden
+var defaultRobotsChecker = NewRobotsChecker()
+
+// Check returns a *RobotsDisallowedError if robots.txt forbids fetching u.
+// When the URL is allowed it waits out the host's Crawl-delay, if any.
+// Non-HTTP URLs are always allowed.
+func (rc *RobotsChecker) Check(ctx context.Context, client *http.Client, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	if u.EscapedPath() == "/robots.txt" {
+		return nil
+	}
+
+	host := rc.host(u)
+	rules, err := rc.rules(ctx, client, u, host)
+	if err != nil {
+		return err
+	}
+
+	if rules.unavailable != "" {
+		return &RobotsDisallowedError{URL: u.String(), Reason: rules.unavailable}
+	}
+	if !rules.allowed(robotsPath(u)) {
+		return &RobotsDisallowedError{URL: u.String(), Reason: fmt.Sprintf("path disallowed for %s", rc.userAgent())}
+	}
+
+	return rc.wait(ctx, host, rules.crawlDelay)
+}
+
+// CrawlDelay returns the cached Crawl-delay of the host serving u, or zero
+// if none is known
+func (rc *RobotsChecker) CrawlDelay(u *url.URL) time.Duration {
+	h := rc.host(u)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.rules == nil {
+		return 0
+	}
+	return h.rules.crawlDelay
+}
+
+func (rc *RobotsChecker) userAgent() string {
+	if rc.UserAgent != "" {
+		return rc.UserAgent
+	}
+	return RobotsUserAgent
+}
+
+func (rc *RobotsChecker) ttl() time.Duration {
+	if rc.TTL > 0 {
+		return rc.TTL
+	}
+	return 24 * time.Hour
+}
+
+func (rc *RobotsChecker) unavailableTTL() time.Duration {
+	if rc.UnavailableTTL > 0 {
+		return rc.UnavailableTTL
+	}
+	return 5 * time.Minute
+}
+
+func (rc *RobotsChecker) host(u *url.URL) *robotsHost {
+	key := u.Scheme + "://" + strings.ToLower(u.Host)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.hosts == nil {
+		rc.hosts = make(map[string]*robotsHost)
+	}
+	h, ok := rc.hosts[key]
+	if !ok {
+		h = &robotsHost{}
+		rc.hosts[key] = h
+	}
+	return h
+}
+
+// rules returns the cached rules of a host, fetching them when missing or expired
+func (rc *RobotsChecker) rules(ctx context.Context, client *http.Client, u *url.URL, h *robotsHost) (*robotsRules, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.rules != nil && time.Now().Before(h.expiresAt) {
+		return h.rules, nil
+	}
+
+	rules, err := fetchRobots(ctx, client, u, rc.userAgent())
+	if err != nil {
+		return nil, err
+	}
+	ttl := rc.ttl()
+	if rules.unavailable != "" {
+		ttl = min(ttl, rc.unavailableTTL())
+	}
+	h.rules = rules
+	h.expiresAt = time.Now().Add(ttl)
+	return rules, nil
+}
+
+// wait blocks until the host's Crawl-delay since the previous request has passed
+func (rc *RobotsChecker) wait(ctx context.Context, h *robotsHost, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	start := h.nextFetch
+	if start.Before(now) {
+		start = now
+	}
+	h.nextFetch = start.Add(delay)
+	h.mu.Unlock()
+
+	if d := time.Until(start); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// fetchRobots downloads and parses robots.txt for the host of u. Following
+// RFC 9309, a missing file (4xx) allows everything and a server error (5xx)
+// disallows everything.
+func fetchRobots(ctx context.Context, client *http.Client, u *url.URL, agent string) (*robotsRules, error) {
+	robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating robots.txt request: %w", err)
+	}
+	req.Header.Set("User-Agent", "go-flock/1.0 RobotsChecker")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return &robotsRules{unavailable: fmt.Sprintf("robots.txt unavailable (status %d)", resp.StatusCode)}, nil
+	case resp.StatusCode != http.StatusOK:
+		return &robotsRules{}, nil
+	}
+
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsSize), agent), nil
+}
+
+// robotsRules are the rules of the group matching our user agent
+type robotsRules struct {
+	rules       []robotsRule
+	crawlDelay  time.Duration
+	unavailable string // Set when robots.txt could not be retrieved and everything is disallowed
+}
+
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// allowed reports whether path may be fetched. The longest matching pattern
+// wins; Allow wins ties.
+func (r *robotsRules) allowed(path string) bool {
+	best := -1
+	allow := true
+	for _, rule := range r.rules {
+		if !robotsPatternMatch(rule.pattern, path) {
+			continue
+		}
+		n := len(rule.pattern)
+		if n > best || (n == best && rule.allow) {
+			best = n
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// parseRobots extracts the rules that apply to agent. Groups naming the agent
+// take precedence over the "*" group; several matching groups are merged.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+
+	type group struct {
+		agents     []string
+		rules      []robotsRule
+		crawlDelay time.Duration
+	}
+	var groups []*group
+	var current *group
+	inAgents := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{pattern: value, allow: key == "allow"})
+		case "crawl-delay":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+				current.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		default:
+			// Sitemap and unknown lines do not end a group's user-agent list
+		}
+	}
+
+	specific := &robotsRules{}
+	wildcard := &robotsRules{}
+	foundSpecific := false
+	for _, g := range groups {
+		for _, a := range g.agents {
+			name, _, _ := strings.Cut(a, "/")
+			target := wildcard
+			if name == agent {
+				target = specific
+				foundSpecific = true
+			} else if name != "*" {
+				continue
+			}
+			target.rules = append(target.rules, g.rules...)
+			if g.crawlDelay > target.crawlDelay {
+				target.crawlDelay = g.crawlDelay
+			}
+			break
+		}
+	}
+
+	if foundSpecific {
+		return specific
+	}
+	return wildcard
+}
+
+// parseRobotsSitemaps returns the URLs of Sitemap lines, which apply to the
+// whole file rather than to a user-agent group
+func parseRobotsSitemaps(r io.Reader) []string {
+	var sitemaps []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "sitemap") {
+			continue
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			sitemaps = append(sitemaps, value)
+		}
+	}
+	return sitemaps
+}
+
+// robotsPath returns the path and query of u as matched against rules
+func robotsPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
+
+// robotsPatternMatch matches a robots.txt path pattern supporting the "*"
+// wildcard and the "$" end anchor
+func robotsPatternMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	if len(parts) == 1 {
+		return !anchored || path == parts[0]
+	}
+
+	pos := len(parts[0])
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return true
+}
@@ -0,0 +1,315 @@
+// ABOUTME: Unit tests for robots.txt compliance
+// ABOUTME: Tests rule parsing and matching, per-host caching, Crawl-delay and tool integration
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const sampleRobots = `# Example robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public.html
+Disallow: /*.pdf$
+
+User-agent: OtherBot
+Disallow: /
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestParseRobots(t *testing.T) {
+	t.Parallel()
+
+	rules := parseRobots(strings.NewReader(sampleRobots), RobotsUserAgent)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/index.html", true},
+		{"/private/", false},
+		{"/private/secret.html", false},
+		{"/private/public.html", true},
+		{"/docs/report.pdf", false},
+		{"/docs/report.pdf?download=1", true},
+	}
+	for _, tt := range tests {
+		if got := rules.allowed(tt.path); got != tt.want {
+			t.Errorf("allowed(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRobots_SpecificAgentGroup(t *testing.T) {
+	t.Parallel()
+
+	robots := `User-agent: *
+Disallow: /
+
+User-agent: Go-Flock
+User-agent: OtherBot
+Disallow: /admin
+Crawl-delay: 2.5
+`
+	rules := parseRobots(strings.NewReader(robots), RobotsUserAgent)
+
+	if !rules.allowed("/articles") {
+		t.Error("Expected the go-flock group to override the wildcard group")
+	}
+	if rules.allowed("/admin/users") {
+		t.Error("Expected /admin to be disallowed")
+	}
+	if rules.crawlDelay != 2500*time.Millisecond {
+		t.Errorf("Expected crawl delay 2.5s, got %v", rules.crawlDelay)
+	}
+}
+
+func TestRobotsPatternMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish.html", false},
+		{"/fish*", "/fishheads/yummy.html", true},
+		{"/*.php", "/folder/filename.php?parameters", true},
+		{"/*.php$", "/filename.php", true},
+		{"/*.php$", "/filename.php?parameters", false},
+		{"/fish*.php", "/fishheads/catfish.php?parameters", true},
+		{"/fish*.php", "/Fish.PHP", false},
+		{"/exact$", "/exact", true},
+		{"/exact$", "/exact/more", false},
+	}
+	for _, tt := range tests {
+		if got := robotsPatternMatch(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("robotsPatternMatch(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func newRobotsServer(robots string, robotsStatus int, robotsFetches *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			if robotsFetches != nil {
+				atomic.AddInt32(robotsFetches, 1)
+			}
+			w.WriteHeader(robotsStatus)
+			fmt.Fprint(w, robots)
+			return
+		}
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/private/page.html", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, sampleHTML)
+	}))
+}
+
+func TestFetchWebPageHandler_RobotsDisallowed(t *testing.T) {
+	t.Parallel()
+
+	server := newRobotsServer(sampleRobots, http.StatusOK, nil)
+	defer server.Close()
+
+	cfg := newToolConfig(WithRobotsChecker(NewRobotsChecker()))
+	_, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/private/page.html"})
+
+	var robotsErr *RobotsDisallowedError
+	if !errors.As(err, &robotsErr) {
+		t.Fatalf("Expected RobotsDisallowedError, got %v", err)
+	}
+	if robotsErr.URL != server.URL+"/private/page.html" {
+		t.Errorf("Expected error URL to be the disallowed page, got %s", robotsErr.URL)
+	}
+
+	if _, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/index.html"}); err != nil {
+		t.Errorf("Expected allowed page to be fetched, got %v", err)
+	}
+}
+
+func TestFetchWebPageHandler_RobotsRedirect(t *testing.T) {
+	t.Parallel()
+
+	server := newRobotsServer(sampleRobots, http.StatusOK, nil)
+	defer server.Close()
+
+	cfg := newToolConfig(WithRobotsChecker(NewRobotsChecker()))
+	_, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/moved", FollowRedirects: true})
+
+	var robotsErr *RobotsDisallowedError
+	if !errors.As(err, &robotsErr) {
+		t.Fatalf("Expected redirect to a disallowed page to fail, got %v", err)
+	}
+}
+
+func TestFetchWebPageHandler_IgnoreRobots(t *testing.T) {
+	t.Parallel()
+
+	var robotsFetches int32
+	server := newRobotsServer(sampleRobots, http.StatusOK, &robotsFetches)
+	defer server.Close()
+
+	cfg := newToolConfig(WithIgnoreRobots())
+	if _, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/private/page.html"}); err != nil {
+		t.Errorf("Expected opt-out to skip robots.txt, got %v", err)
+	}
+	if n := atomic.LoadInt32(&robotsFetches); n != 0 {
+		t.Errorf("Expected robots.txt not to be fetched, got %d fetches", n)
+	}
+}
+
+func TestExtractLinksHandler_RobotsDisallowed(t *testing.T) {
+	t.Parallel()
+
+	server := newRobotsServer("User-agent: go-flock\nDisallow: /\n", http.StatusOK, nil)
+	defer server.Close()
+
+	cfg := newToolConfig(WithRobotsChecker(NewRobotsChecker()))
+	_, err := cfg.extractLinksHandler(context.Background(), ExtractLinksParams{URL: server.URL + "/"})
+
+	var robotsErr *RobotsDisallowedError
+	if !errors.As(err, &robotsErr) {
+		t.Errorf("Expected RobotsDisallowedError, got %v", err)
+	}
+}
+
+func TestExtractLinksHandler_RobotsRedirect(t *testing.T) {
+	t.Parallel()
+
+	server := newRobotsServer(sampleRobots, http.StatusOK, nil)
+	defer server.Close()
+
+	cfg := newToolConfig(WithRobotsChecker(NewRobotsChecker()))
+	_, err := cfg.extractLinksHandler(context.Background(), ExtractLinksParams{URL: server.URL + "/moved"})
+
+	var robotsErr *RobotsDisallowedError
+	if !errors.As(err, &robotsErr) {
+		t.Fatalf("Expected redirect to a disallowed page to fail, got %v", err)
+	}
+}
+
+func TestRobotsChecker_CachesPerHost(t *testing.T) {
+	t.Parallel()
+
+	var robotsFetches int32
+	server := newRobotsServer(sampleRobots, http.StatusOK, &robotsFetches)
+	defer server.Close()
+
+	cfg := newToolConfig(WithRobotsChecker(NewRobotsChecker()))
+	for i := 0; i < 3; i++ {
+		if _, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/index.html"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&robotsFetches); n != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, got %d", n)
+	}
+}
+
+func TestRobotsChecker_StatusHandling(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status  int
+		allowed bool
+	}{
+		{http.StatusNotFound, true},
+		{http.StatusForbidden, true},
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			t.Parallel()
+
+			server := newRobotsServer("User-agent: *\nDisallow: /\n", tt.status, nil)
+			defer server.Close()
+
+			cfg := newToolConfig(WithRobotsChecker(NewRobotsChecker()), WithHTTPClient(&http.Client{}))
+			_, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/index.html"})
+
+			var robotsErr *RobotsDisallowedError
+			if got := !errors.As(err, &robotsErr); got != tt.allowed {
+				t.Errorf("Expected allowed=%v, got error %v", tt.allowed, err)
+			}
+		})
+	}
+}
+
+func TestRobotsChecker_RetriesAfterServerError(t *testing.T) {
+	t.Parallel()
+
+	var robotsFetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			// Only the first fetch fails
+			if atomic.AddInt32(&robotsFetches, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, "User-agent: *\nAllow: /\n")
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, sampleHTML)
+	}))
+	defer server.Close()
+
+	checker := &RobotsChecker{UnavailableTTL: 20 * time.Millisecond}
+	cfg := newToolConfig(WithRobotsChecker(checker), WithHTTPClient(&http.Client{})) // No retries on 503
+	params := FetchWebPageParams{URL: server.URL + "/index.html"}
+
+	var robotsErr *RobotsDisallowedError
+	if _, err := cfg.fetchWebPageHandler(context.Background(), params); !errors.As(err, &robotsErr) {
+		t.Fatalf("Expected the server error to disallow the host, got %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := cfg.fetchWebPageHandler(context.Background(), params); err != nil {
+		t.Fatalf("Expected robots.txt to be fetched again, got %v", err)
+	}
+	// The successful result is cached for the full TTL
+	if _, err := cfg.fetchWebPageHandler(context.Background(), params); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := atomic.LoadInt32(&robotsFetches); n != 2 {
+		t.Errorf("Expected 2 robots.txt fetches, got %d", n)
+	}
+	if d := NewRobotsChecker().unavailableTTL(); d > 10*time.Minute {
+		t.Errorf("Expected a short default for server errors, got %v", d)
+	}
+}
+
+func TestRobotsChecker_CrawlDelay(t *testing.T) {
+	t.Parallel()
+
+	server := newRobotsServer("User-agent: *\nCrawl-delay: 0.3\n", http.StatusOK, nil)
+	defer server.Close()
+
+	cfg := newToolConfig(WithRobotsChecker(NewRobotsChecker()))
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/index.html"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Three requests need two crawl delays between them
+	if elapsed := time.Since(start); elapsed < 600*time.Millisecond {
+		t.Errorf("Expected requests to be spaced by the crawl delay, took %v", elapsed)
+	}
+}
@@ -0,0 +1,228 @@
+// ABOUTME: Named sessions that keep cookies and default headers across web tool calls
+// ABOUTME: Sessions are carried by the context for an agent run and can preload Netscape cookies.txt files
+
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// DefaultSessionName is the session used by calls that do not name one
+const DefaultSessionName = "default"
+
+// Session holds the cookies and default headers that persist across the web
+// tool calls using it, so consent cookies and logins survive from one call
+// to the next. It is safe for concurrent use.
+type Session struct {
+	Name string
+
+	jar     *cookiejar.Jar
+	mu      sync.RWMutex
+	headers http.Header
+}
+
+// NewSession creates an empty session
+func NewSession(name string) *Session {
+	// A public suffix list keeps sites from setting cookies for all of .co.uk
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	return &Session{Name: name, jar: jar, headers: make(http.Header)}
+}
+
+// SetHeader sets a header sent with every request of the session, replacing
+// the tool's default for it. Headers passed to a single call still win.
+func (s *Session) SetHeader(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.headers.Set(key, value)
+}
+
+// DelHeader removes a default header
+func (s *Session) DelHeader(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.headers.Del(key)
+}
+
+// SetCookies stores cookies as if u had set them
+func (s *Session) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	s.jar.SetCookies(u, cookies)
+}
+
+// Cookies returns the cookies the session sends to u
+func (s *Session) Cookies(u *url.URL) []*http.Cookie {
+	return s.jar.Cookies(u)
+}
+
+// LoadCookiesFile preloads cookies from a Netscape cookies.txt file, as
+// exported by browsers and written by curl -c
+func (s *Session) LoadCookiesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening cookies file: %w", err)
+	}
+	defer f.Close()
+	return s.LoadCookies(f)
+}
+
+// LoadCookies preloads cookies in Netscape cookies.txt format: one cookie per
+// line with tab-separated domain, include-subdomains flag, path, secure flag,
+// expiry as Unix time (0 for session cookies), name and value. Lines starting
+// with "#HttpOnly_" hold HTTP-only cookies; other "#" lines are comments.
+// Expired cookies are skipped.
+func (s *Session) LoadCookies(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if rest, ok := strings.CutPrefix(line, "#HttpOnly_"); ok {
+			line = rest
+			httpOnly = true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) == 6 {
+			fields = append(fields, "") // Empty value
+		}
+		if len(fields) != 7 {
+			return fmt.Errorf("cookies file line %d: expected 7 tab-separated fields, got %d", lineNum, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("cookies file line %d: invalid expiry %q", lineNum, fields[4])
+		}
+
+		host := strings.TrimPrefix(fields[0], ".")
+		secure := strings.EqualFold(fields[3], "TRUE")
+		cookie := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Path:     fields[2],
+			Secure:   secure,
+			HttpOnly: httpOnly,
+		}
+		// Cookies for subdomains carry a Domain attribute; host-only ones do not
+		if strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = host
+		}
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+			if cookie.Expires.Before(time.Now()) {
+				continue
+			}
+		}
+
+		scheme := "http"
+		if secure {
+			scheme = "https"
+		}
+		s.jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: fields[2]}, []*http.Cookie{cookie})
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading cookies file: %w", err)
+	}
+	return nil
+}
+
+// attach makes client store and send cookies through the session. A nil
+// session leaves the client stateless.
+func (s *Session) attach(client *http.Client) {
+	if s != nil {
+		client.Jar = s.jar
+	}
+}
+
+// applyHeaders sets the session's default headers on req
+func (s *Session) applyHeaders(req *http.Request) {
+	if s == nil {
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for key, values := range s.headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+}
+
+// SessionStore holds the named sessions of an agent run
+type SessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewSessionStore creates an empty session store
+func NewSessionStore() *SessionStore {
+	return &SessionStore{sessions: make(map[string]*Session)}
+}
+
+// Session returns the named session, creating it on first use
+func (st *SessionStore) Session(name string) *Session {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.sessions[name]
+	if !ok {
+		s = NewSession(name)
+		st.sessions[name] = s
+	}
+	return s
+}
+
+// Delete forgets the named session, e.g. to log out
+func (st *SessionStore) Delete(name string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.sessions, name)
+}
+
+type sessionsContextKey struct{}
+
+// ContextWithSessions returns a context carrying a session store. Web tools
+// called with it keep cookies and headers in the session named by their
+// "session" parameter, or in DefaultSessionName.
+func ContextWithSessions(ctx context.Context, store *SessionStore) context.Context {
+	return context.WithValue(ctx, sessionsContextKey{}, store)
+}
+
+// SessionsFromContext returns the session store carried by the context, if any
+func SessionsFromContext(ctx context.Context) *SessionStore {
+	store, _ := ctx.Value(sessionsContextKey{}).(*SessionStore)
+	return store
+}
+
+// session returns the session a call uses: the named one, or the default
+// one, from the store carried by the context or configured with
+// WithSessions. Without a store calls are stateless and session is nil.
+func (c *toolConfig) session(ctx context.Context, name string) (*Session, error) {
+	store := SessionsFromContext(ctx)
+	if store == nil {
+		store = c.sessions
+	}
+	if store == nil {
+		if name != "" {
+			return nil, fmt.Errorf("session %q requested but no session store is configured", name)
+		}
+		return nil, nil
+	}
+	if name == "" {
+		name = DefaultSessionName
+	}
+	return store.Session(name), nil
+}
@@ -0,0 +1,175 @@
+// ABOUTME: Unit tests for named sessions shared by web tools
+// ABOUTME: Tests cookie persistence across tools, consent redirects, default headers, isolation and cookies.txt loading
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSessionTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.NotFound(w, r)
+		case "/consent":
+			http.SetCookie(w, &http.Cookie{Name: "consent", Value: "yes", Path: "/"})
+			http.Redirect(w, r, "/article", http.StatusFound)
+		case "/article":
+			if _, err := r.Cookie("consent"); err != nil {
+				http.Redirect(w, r, "/consent", http.StatusFound)
+				return
+			}
+			fmt.Fprint(w, `<html><head><title>Article</title></head><body>Article text</body></html>`)
+		case "/login":
+			if r.Method == "POST" {
+				http.SetCookie(w, &http.Cookie{Name: "user", Value: r.FormValue("name"), Path: "/"})
+				http.Redirect(w, r, "/account", http.StatusSeeOther)
+				return
+			}
+			fmt.Fprint(w, `<html><body><form method="post" action="/login"><input name="name"><button>Log in</button></form></body></html>`)
+		case "/account":
+			user, err := r.Cookie("user")
+			if err != nil {
+				http.Error(w, "login required", http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `<html><head><title>Account</title></head><body>Hello %s (%s) <a href="/orders">Orders</a></body></html>`,
+				user.Value, r.Header.Get("Accept-Language"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSessions_AcrossTools(t *testing.T) {
+	t.Parallel()
+
+	server := newSessionTestServer(t)
+	store := NewSessionStore()
+	store.Session(DefaultSessionName).SetHeader("Accept-Language", "de-DE")
+	ctx := ContextWithSessions(context.Background(), store)
+	cfg := newToolConfig()
+
+	// A consent redirect loop resolves only when the cookie is kept
+	if _, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/article", FollowRedirects: true}); err == nil {
+		t.Error("Expected the consent redirect loop to fail without a session")
+	}
+	page, err := cfg.fetchWebPageHandler(ctx, FetchWebPageParams{URL: server.URL + "/article", FollowRedirects: true, ExtractText: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if page.Title != "Article" {
+		t.Errorf("Expected the article after consent, got %q", page.Title)
+	}
+
+	// A login with submit_form carries over to later calls of other tools
+	if _, err := cfg.submitFormHandler(ctx, SubmitFormParams{URL: server.URL + "/login", Values: map[string]string{"name": "ada"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	account, err := cfg.fetchWebPageHandler(ctx, FetchWebPageParams{URL: server.URL + "/account", ExtractText: true})
+	if err != nil {
+		t.Fatalf("Expected to stay logged in, got %v", err)
+	}
+	if !strings.Contains(account.Content, "Hello ada (de-DE)") {
+		t.Errorf("Expected logged-in page with session headers, got %q", account.Content)
+	}
+	links, err := cfg.extractLinksHandler(ctx, ExtractLinksParams{URL: server.URL + "/account", IncludeInternal: true})
+	if err != nil {
+		t.Fatalf("Expected extract_links to use the session, got %v", err)
+	}
+	if links.TotalLinks != 1 {
+		t.Errorf("Expected the account page's link, got %d links", links.TotalLinks)
+	}
+
+	// Per-call headers win over session headers
+	english, err := cfg.fetchWebPageHandler(ctx, FetchWebPageParams{URL: server.URL + "/account", ExtractText: true, Headers: map[string]string{"Accept-Language": "en"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(english.Content, "(en)") {
+		t.Errorf("Expected per-call header to win, got %q", english.Content)
+	}
+
+	// Other sessions and calls without a store know nothing of the login
+	other, err := cfg.fetchWebPageHandler(ctx, FetchWebPageParams{URL: server.URL + "/account", Session: "other"})
+	if err != nil || other.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected another session to be logged out, got %v", err)
+	}
+	stateless, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/account"})
+	if err != nil || stateless.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a call without sessions to be logged out, got %v", err)
+	}
+}
+
+func TestSessions_Configuration(t *testing.T) {
+	t.Parallel()
+
+	server := newSessionTestServer(t)
+
+	// A store configured on the tool applies when the context has none
+	store := NewSessionStore()
+	u, _ := url.Parse(server.URL)
+	store.Session("shop").SetCookies(u, []*http.Cookie{{Name: "user", Value: "grace", Path: "/"}})
+	cfg := newToolConfig(WithSessions(store))
+	page, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/account", ExtractText: true, Session: "shop"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(page.Content, "Hello grace") {
+		t.Errorf("Expected the configured session's cookie, got %q", page.Content)
+	}
+
+	if _, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL, Session: "shop"}); err == nil || !strings.Contains(err.Error(), "no session store") {
+		t.Errorf("Expected error for a named session without a store, got %v", err)
+	}
+}
+
+func TestSession_LoadCookies(t *testing.T) {
+	t.Parallel()
+
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Hour).Unix()
+	cookies := strings.Join([]string{
+		"# Netscape HTTP Cookie File",
+		"",
+		fmt.Sprintf("127.0.0.1\tFALSE\t/\tFALSE\t%d\tuser\tlinus", future),
+		fmt.Sprintf("#HttpOnly_127.0.0.1\tFALSE\t/\tFALSE\t0\tsid\tabc123"),
+		fmt.Sprintf("127.0.0.1\tFALSE\t/\tFALSE\t%d\told\tgone", past),
+		fmt.Sprintf(".example.com\tTRUE\t/\tTRUE\t%d\tpref\tdark", future),
+		"127.0.0.1\tFALSE\t/\tFALSE\t0\tempty",
+	}, "\n")
+
+	session := NewSession("test")
+	if err := session.LoadCookies(strings.NewReader(cookies)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	local := session.Cookies(&url.URL{Scheme: "http", Host: "127.0.0.1", Path: "/"})
+	got := make(map[string]string)
+	for _, c := range local {
+		got[c.Name] = c.Value
+	}
+	if len(got) != 3 || got["user"] != "linus" || got["sid"] != "abc123" || got["empty"] != "" {
+		t.Errorf("Unexpected cookies for 127.0.0.1: %v", got)
+	}
+	if sub := session.Cookies(&url.URL{Scheme: "https", Host: "www.example.com", Path: "/"}); len(sub) != 1 || sub[0].Value != "dark" {
+		t.Errorf("Expected domain cookie on a subdomain over https, got %v", sub)
+	}
+	if plain := session.Cookies(&url.URL{Scheme: "http", Host: "example.com", Path: "/"}); len(plain) != 0 {
+		t.Errorf("Expected secure cookie withheld over http, got %v", plain)
+	}
+
+	if err := NewSession("bad").LoadCookies(strings.NewReader("example.com\tTRUE\t/")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected error for a malformed line, got %v", err)
+	}
+}
@@ -0,0 +1,444 @@
+// ABOUTME: Sitemap parsing tool for sitemap.xml files and sitemap indexes
+// ABOUTME: Handles gzip, lastmod filtering, Google News extensions and discovery via robots.txt
+
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
+	"github.com/lexlapax/go-llms/pkg/agent/tools"
+	sdomain "github.com/lexlapax/go-llms/pkg/schema/domain"
+)
+
+// maxSitemapSize caps an uncompressed sitemap, as the sitemaps protocol does,
+// unless the tool is configured with WithMaxResponseSize
+const maxSitemapSize = 50 * 1024 * 1024
+
+// Sitemap XML structures. Tags without a namespace match the sitemap and
+// Google News namespaces alike.
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapURLEntry `xml:"url"`
+	Sitemaps []sitemapRef      `xml:"sitemap"`
+
+	truncated bool // The sitemap exceeded the size limit; later entries are missing
+}
+
+type sitemapURLEntry struct {
+	Loc        string       `xml:"loc"`
+	LastMod    string       `xml:"lastmod"`
+	ChangeFreq string       `xml:"changefreq"`
+	Priority   string       `xml:"priority"`
+	News       *sitemapNews `xml:"news"`
+}
+
+type sitemapNews struct {
+	Publication struct {
+		Name     string `xml:"name"`
+		Language string `xml:"language"`
+	} `xml:"publication"`
+	PublicationDate string `xml:"publication_date"`
+	Title           string `xml:"title"`
+	Keywords        string `xml:"keywords"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// Tool Parameters
+type ParseSitemapParams struct {
+	URL         string `json:"url" description:"Sitemap URL, or a site URL or bare host to discover sitemaps from robots.txt"`
+	Since       string `json:"since,omitempty" description:"Only return URLs modified or published on or after this date (YYYY-MM-DD or RFC3339)"`
+	Limit       int    `json:"limit,omitempty" description:"Maximum number of URLs to return (default: 500, max: 50000)"`
+	MaxSitemaps int    `json:"max_sitemaps,omitempty" description:"Maximum number of sitemap files to fetch, including indexes (default: 20, max: 200)"`
+	Timeout     int    `json:"timeout,omitempty" description:"Timeout in seconds for each request (default: 30)"`
+}
+
+// Tool Results
+type ParseSitemapResult struct {
+	URL       string       `json:"url"`
+	Sitemaps  []string     `json:"sitemaps"` // Sitemap files fetched, in order
+	URLs      []SitemapURL `json:"urls"`
+	URLCount  int          `json:"url_count"`
+	Truncated bool         `json:"truncated"` // The limit or max_sitemaps was reached, or a sitemap exceeded the size limit
+	Errors    []string     `json:"errors,omitempty"`
+	FetchedAt string       `json:"fetched_at"`
+}
+
+type SitemapURL struct {
+	Loc        string           `json:"loc"`
+	LastMod    string           `json:"lastmod,omitempty"`
+	ChangeFreq string           `json:"changefreq,omitempty"`
+	Priority   string           `json:"priority,omitempty"`
+	News       *SitemapNewsInfo `json:"news,omitempty"` // Google News sitemap extension
+}
+
+type SitemapNewsInfo struct {
+	PublicationName string   `json:"publication_name,omitempty"`
+	Language        string   `json:"language,omitempty"`
+	PublicationDate string   `json:"publication_date,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	Keywords        []string `json:"keywords,omitempty"`
+}
+
+// Schema definitions
+var ParseSitemapParamSchema = &sdomain.Schema{
+	Type:        "object",
+	Description: "Parameters for parsing a sitemap",
+	Properties: map[string]sdomain.Property{
+		"url": {
+			Type:        "string",
+			Description: "Sitemap URL (sitemap.xml, sitemap index or .xml.gz), or a site URL or bare host such as 'example.com' to discover its sitemaps from robots.txt",
+		},
+		"since": {
+			Type:        "string",
+			Description: "Only return URLs whose lastmod or news publication date is on or after this date (YYYY-MM-DD or RFC3339); undated URLs are left out",
+		},
+		"limit": {
+			Type:        "integer",
+			Description: "Maximum number of URLs to return (default: 500)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(50000),
+		},
+		"max_sitemaps": {
+			Type:        "integer",
+			Description: "Maximum number of sitemap files to fetch, including indexes (default: 20)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(200),
+		},
+		"timeout": {
+			Type:        "integer",
+			Description: "Timeout in seconds for each request (default: 30)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(300),
+		},
+	},
+	Required: []string{"url"},
+}
+
+// NewParseSitemapTool creates a new sitemap parsing tool
+func NewParseSitemapTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
+	return tools.NewTool(
+		"parse_sitemap",
+		"Parses a site's sitemaps, following sitemap indexes, to list its pages with last-modified and Google News dates",
+		cfg.parseSitemapHandler,
+		ParseSitemapParamSchema,
+	)
+}
+
+func (c *toolConfig) parseSitemapHandler(ctx context.Context, params ParseSitemapParams) (*ParseSitemapResult, error) {
+	// Set defaults
+	limit := 500
+	if params.Limit > 0 {
+		limit = min(params.Limit, 50000)
+	}
+	maxSitemaps := 20
+	if params.MaxSitemaps > 0 {
+		maxSitemaps = min(params.MaxSitemaps, 200)
+	}
+	timeout := 30
+	if params.Timeout > 0 {
+		timeout = params.Timeout
+	}
+
+	var since time.Time
+	if params.Since != "" {
+		t, ok := parseW3CDate(params.Since)
+		if !ok {
+			return nil, fmt.Errorf("invalid since date %q (use YYYY-MM-DD or RFC3339)", params.Since)
+		}
+		since = t
+	}
+
+	target := strings.TrimSpace(params.URL)
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("parsing URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	client := c.client(time.Duration(timeout) * time.Second)
+
+	// A bare host or site root means discovering sitemaps from robots.txt
+	queue := []string{u.String()}
+	if u.Path == "" || u.Path == "/" {
+		queue, err = discoverSitemaps(ctx, client, u)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ParseSitemapResult{
+		URL:       params.URL,
+		Sitemaps:  []string{},
+		URLs:      []SitemapURL{},
+		FetchedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	// Failures deeper in an index are reported alongside what could be read;
+	// the tool only fails when no sitemap loaded at all
+	var firstErr error
+	loaded := 0
+	seen := make(map[string]bool)
+	for len(queue) > 0 && len(result.URLs) < limit {
+		loc := queue[0]
+		queue = queue[1:]
+		if seen[loc] {
+			continue
+		}
+		seen[loc] = true
+		if len(result.Sitemaps) >= maxSitemaps {
+			result.Truncated = true
+			break
+		}
+		result.Sitemaps = append(result.Sitemaps, loc)
+
+		doc, err := c.fetchSitemap(ctx, client, loc)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", loc, err))
+			continue
+		}
+		loaded++
+		if doc.truncated {
+			result.Truncated = true
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: exceeds %d bytes; later entries are missing", loc, c.responseLimit(maxSitemapSize)))
+		}
+
+		for _, ref := range doc.Sitemaps {
+			ref.Loc = strings.TrimSpace(ref.Loc)
+			if ref.Loc == "" {
+				continue
+			}
+			// A child sitemap last modified before since cannot hold newer URLs
+			if t, ok := parseW3CDate(ref.LastMod); ok && !since.IsZero() && t.Before(since) {
+				continue
+			}
+			queue = append(queue, ref.Loc)
+		}
+
+		for _, entry := range doc.URLs {
+			if len(result.URLs) >= limit {
+				result.Truncated = true
+				break
+			}
+			entry.Loc = strings.TrimSpace(entry.Loc)
+			if entry.Loc == "" || !since.IsZero() && !entry.modifiedSince(since) {
+				continue
+			}
+			result.URLs = append(result.URLs, entry.info())
+		}
+	}
+	if len(queue) > 0 && len(result.URLs) >= limit {
+		result.Truncated = true
+	}
+	if loaded == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+
+	result.URLCount = len(result.URLs)
+	return result, nil
+}
+
+// discoverSitemaps lists the sitemaps advertised in robots.txt, falling back
+// to /sitemap.xml when there are none
+func discoverSitemaps(ctx context.Context, client *http.Client, site *url.URL) ([]string, error) {
+	robotsURL := &url.URL{Scheme: site.Scheme, Host: site.Host, Path: "/robots.txt"}
+	fallback := []string{(&url.URL{Scheme: site.Scheme, Host: site.Host, Path: "/sitemap.xml"}).String()}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", "go-flock/1.0 SitemapReader")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fallback, nil
+	}
+	sitemaps := parseRobotsSitemaps(io.LimitReader(resp.Body, maxRobotsSize))
+	if len(sitemaps) == 0 {
+		return fallback, nil
+	}
+	return sitemaps, nil
+}
+
+// fetchSitemap downloads and parses one sitemap or sitemap index,
+// decompressing gzip files
+func (c *toolConfig) fetchSitemap(ctx context.Context, client *http.Client, loc string) (*sitemapDocument, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", loc, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", "go-flock/1.0 SitemapReader")
+
+	if err := c.checkRobots(ctx, client, req.URL); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching sitemap: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Servers send .xml.gz files with all sorts of content types, so detect
+	// gzip by its magic number
+	br := bufio.NewReader(resp.Body)
+	var src io.Reader = br
+	contentType := resp.Header.Get("Content-Type")
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing sitemap: %w", err)
+		}
+		defer gz.Close()
+		src = gz
+		contentType = ""
+	}
+
+	// Parse entries as they stream in; the limit applies to the uncompressed
+	// sitemap, and the entries read before it are kept
+	limit := c.responseLimit(maxSitemapSize)
+	body := newCappedReader(src, limit)
+	decoder, err := newXMLDecoder(body, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("parsing sitemap: %w", err)
+	}
+
+	root, err := xmlRootElement(decoder)
+	if err != nil {
+		if body.truncated {
+			return nil, &ResponseTooLargeError{URL: loc, Limit: limit}
+		}
+		return nil, fmt.Errorf("parsing sitemap: %w", err)
+	}
+
+	doc := &sitemapDocument{XMLName: root.Name}
+	switch root.Name.Local {
+	case "urlset":
+		err = streamXMLElements(decoder, "url", func(start *xml.StartElement) error {
+			var entry sitemapURLEntry
+			if err := decoder.DecodeElement(&entry, start); err != nil {
+				return err
+			}
+			doc.URLs = append(doc.URLs, entry)
+			return nil
+		})
+	case "sitemapindex":
+		err = streamXMLElements(decoder, "sitemap", func(start *xml.StartElement) error {
+			var ref sitemapRef
+			if err := decoder.DecodeElement(&ref, start); err != nil {
+				return err
+			}
+			doc.Sitemaps = append(doc.Sitemaps, ref)
+			return nil
+		})
+	default:
+		return nil, fmt.Errorf("parsing sitemap: unexpected root element <%s>", root.Name.Local)
+	}
+	if err != nil && !body.truncated {
+		return nil, fmt.Errorf("parsing sitemap: %w", err)
+	}
+	doc.truncated = body.truncated
+	return doc, nil
+}
+
+// xmlRootElement reads up to and including the document's root element
+func xmlRootElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return xml.StartElement{}, errors.New("no root element")
+		}
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// modifiedSince reports whether the entry's lastmod, or its news publication
+// date when lastmod is missing, is on or after since
+func (e sitemapURLEntry) modifiedSince(since time.Time) bool {
+	date := e.LastMod
+	if date == "" && e.News != nil {
+		date = e.News.PublicationDate
+	}
+	t, ok := parseW3CDate(date)
+	return ok && !t.Before(since)
+}
+
+func (e sitemapURLEntry) info() SitemapURL {
+	info := SitemapURL{
+		Loc:        e.Loc,
+		LastMod:    strings.TrimSpace(e.LastMod),
+		ChangeFreq: strings.TrimSpace(e.ChangeFreq),
+		Priority:   strings.TrimSpace(e.Priority),
+	}
+	if e.News != nil {
+		info.News = &SitemapNewsInfo{
+			PublicationName: strings.TrimSpace(e.News.Publication.Name),
+			Language:        strings.TrimSpace(e.News.Publication.Language),
+			PublicationDate: strings.TrimSpace(e.News.PublicationDate),
+			Title:           strings.TrimSpace(e.News.Title),
+			Keywords:        splitKeywords(e.News.Keywords),
+		}
+	}
+	return info
+}
+
+// parseW3CDate parses the W3C Datetime profile of ISO 8601 used by sitemaps
+func parseW3CDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, format := range []string{
+		time.RFC3339Nano,
+		time.RFC3339,
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+		"2006-01",
+		"2006",
+	} {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
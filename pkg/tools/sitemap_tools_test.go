@@ -0,0 +1,294 @@
+// ABOUTME: Unit tests for the parse_sitemap tool
+// ABOUTME: Tests robots.txt discovery, sitemap indexes, gzip, Google News entries and lastmod filtering
+
+package tools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleSitemapIndex = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>%[1]s/pages.xml.gz</loc><lastmod>2024-12-10</lastmod></sitemap>
+	<sitemap><loc>%[1]s/news.xml</loc><lastmod>2024-12-15T09:00:00Z</lastmod></sitemap>
+	<sitemap><loc>%[1]s/archive.xml</loc><lastmod>2019-01-01</lastmod></sitemap>
+	<sitemap><loc>%[1]s/news.xml</loc></sitemap>
+</sitemapindex>`
+
+const samplePagesSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>%[1]s/</loc><lastmod>2024-12-10</lastmod><changefreq>daily</changefreq><priority>1.0</priority></url>
+	<url><loc>%[1]s/about</loc><lastmod>2023-05-01</lastmod></url>
+	<url><loc>%[1]s/contact</loc></url>
+</urlset>`
+
+const sampleNewsSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:news="http://www.google.com/schemas/sitemap-news/0.9">
+	<url>
+		<loc>%[1]s/news/launch</loc>
+		<news:news>
+			<news:publication><news:name>Example Times</news:name><news:language>en</news:language></news:publication>
+			<news:publication_date>2024-12-15T08:00:00Z</news:publication_date>
+			<news:title>Launch day</news:title>
+			<news:keywords>space, launch</news:keywords>
+		</news:news>
+	</url>
+</urlset>`
+
+func gzipBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(s)); err != nil {
+		t.Fatalf("Failed to gzip: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newSitemapServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprintf(w, "User-agent: *\nDisallow: /private/\n\nSitemap: %s/sitemap_index.xml\n", server.URL)
+		case "/sitemap_index.xml":
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprintf(w, sampleSitemapIndex, server.URL)
+		case "/pages.xml.gz":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(gzipBytes(t, fmt.Sprintf(samplePagesSitemap, server.URL)))
+		case "/news.xml":
+			w.Header().Set("Content-Type", "text/xml")
+			fmt.Fprintf(w, sampleNewsSitemap, server.URL)
+		case "/archive.xml":
+			http.Error(w, "gone", http.StatusGone)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return server
+}
+
+func TestParseSitemapHandler(t *testing.T) {
+	t.Parallel()
+
+	server := newSitemapServer(t)
+	defer server.Close()
+
+	t.Run("discovery from robots.txt", func(t *testing.T) {
+		result, err := newToolConfig().parseSitemapHandler(context.Background(), ParseSitemapParams{
+			URL: server.URL,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		wantSitemaps := []string{
+			server.URL + "/sitemap_index.xml",
+			server.URL + "/pages.xml.gz",
+			server.URL + "/news.xml",
+			server.URL + "/archive.xml",
+		}
+		if !reflect.DeepEqual(result.Sitemaps, wantSitemaps) {
+			t.Errorf("Unexpected sitemaps:\ngot:  %v\nwant: %v", result.Sitemaps, wantSitemaps)
+		}
+		if result.URLCount != 4 || len(result.URLs) != 4 {
+			t.Fatalf("Expected 4 URLs, got %d/%d", result.URLCount, len(result.URLs))
+		}
+		home := result.URLs[0]
+		if home.Loc != server.URL+"/" || home.ChangeFreq != "daily" || home.Priority != "1.0" {
+			t.Errorf("Unexpected first URL from gzipped sitemap: %+v", home)
+		}
+		news := result.URLs[3].News
+		if news == nil {
+			t.Fatalf("Expected news extension on %s", result.URLs[3].Loc)
+		}
+		if news.PublicationName != "Example Times" || news.Language != "en" || news.Title != "Launch day" ||
+			news.PublicationDate != "2024-12-15T08:00:00Z" || !reflect.DeepEqual(news.Keywords, []string{"space", "launch"}) {
+			t.Errorf("Unexpected news extension: %+v", news)
+		}
+		if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "archive.xml: unexpected status code: 410") {
+			t.Errorf("Expected the failing archive sitemap to be reported, got %v", result.Errors)
+		}
+		if result.Truncated {
+			t.Error("Expected result not to be truncated")
+		}
+	})
+
+	t.Run("since filter", func(t *testing.T) {
+		result, err := newToolConfig().parseSitemapHandler(context.Background(), ParseSitemapParams{
+			URL:   server.URL + "/sitemap_index.xml",
+			Since: "2024-12-01",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var locs []string
+		for _, u := range result.URLs {
+			locs = append(locs, strings.TrimPrefix(u.Loc, server.URL))
+		}
+		if want := []string{"/", "/news/launch"}; !reflect.DeepEqual(locs, want) {
+			t.Errorf("Expected %v, got %v", want, locs)
+		}
+		if len(result.Sitemaps) != 3 || len(result.Errors) != 0 {
+			t.Errorf("Expected the old archive sitemap to be skipped, got %v / %v", result.Sitemaps, result.Errors)
+		}
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		result, err := newToolConfig().parseSitemapHandler(context.Background(), ParseSitemapParams{
+			URL:   server.URL + "/sitemap_index.xml",
+			Limit: 2,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.URLCount != 2 || !result.Truncated {
+			t.Errorf("Expected 2 URLs and truncation, got %d (truncated=%v)", result.URLCount, result.Truncated)
+		}
+
+		result, err = newToolConfig().parseSitemapHandler(context.Background(), ParseSitemapParams{
+			URL:         server.URL + "/sitemap_index.xml",
+			MaxSitemaps: 2,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Sitemaps) != 2 || result.URLCount != 3 || !result.Truncated {
+			t.Errorf("Expected 2 sitemaps with 3 URLs and truncation, got %v / %d (truncated=%v)", result.Sitemaps, result.URLCount, result.Truncated)
+		}
+	})
+}
+
+func TestParseSitemapHandler_Fallback(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sitemap.xml" {
+			fmt.Fprint(w, `<urlset><url><loc>https://example.com/only</loc></url></urlset>`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().parseSitemapHandler(context.Background(), ParseSitemapParams{URL: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.URLs) != 1 || result.URLs[0].Loc != "https://example.com/only" {
+		t.Errorf("Expected URL from /sitemap.xml fallback, got %+v", result.URLs)
+	}
+}
+
+func TestParseSitemapHandler_Oversized(t *testing.T) {
+	t.Parallel()
+
+	var sitemap strings.Builder
+	sitemap.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sitemap, "<url><loc>https://example.com/page/%d</loc></url>\n", i)
+	}
+	sitemap.WriteString(`</urlset>`)
+
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte(sitemap.String()))
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			fmt.Fprint(w, sitemap.String())
+		case "/sitemap.xml.gz":
+			w.Write(gzipped.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// The limit applies to the uncompressed sitemap in both cases
+	cfg := newToolConfig(WithMaxResponseSize(8 * 1024))
+	for _, path := range []string{"/sitemap.xml", "/sitemap.xml.gz"} {
+		result, err := cfg.parseSitemapHandler(context.Background(), ParseSitemapParams{URL: server.URL + path, Limit: 5000})
+		if err != nil {
+			t.Fatalf("%s: expected the entries before the cut, got %v", path, err)
+		}
+		if !result.Truncated || result.URLCount == 0 || result.URLCount >= 1000 {
+			t.Errorf("%s: expected some URLs and truncation, got %d (truncated=%v)", path, result.URLCount, result.Truncated)
+		}
+		if result.URLs[0].Loc != "https://example.com/page/0" || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "exceeds 8192 bytes") {
+			t.Errorf("%s: unexpected result %+v", path, result.Errors)
+		}
+	}
+}
+
+func TestParseSitemapHandler_Errors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			fmt.Fprint(w, `<rss version="2.0"><channel></channel></rss>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		params ParseSitemapParams
+		errMsg string
+	}{
+		{name: "bad since", params: ParseSitemapParams{URL: server.URL + "/sitemap.xml", Since: "last week"}, errMsg: "invalid since date"},
+		{name: "bad scheme", params: ParseSitemapParams{URL: "ftp://example.com/sitemap.xml"}, errMsg: "unsupported URL scheme"},
+		{name: "not a sitemap", params: ParseSitemapParams{URL: server.URL + "/feed.xml"}, errMsg: "unexpected root element <rss>"},
+		{name: "missing", params: ParseSitemapParams{URL: server.URL + "/sitemap.xml"}, errMsg: "unexpected status code: 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newToolConfig().parseSitemapHandler(context.Background(), tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestParseW3CDate(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"2024", "2024-12", "2024-12-15", "2024-12-15T08:00+01:00", "2024-12-15T08:00:00Z", "2024-12-15T08:00:00.123-05:00"} {
+		if _, ok := parseW3CDate(s); !ok {
+			t.Errorf("Expected %q to parse", s)
+		}
+	}
+	if _, ok := parseW3CDate("15/12/2024"); ok {
+		t.Error("Expected non-W3C date to be rejected")
+	}
+}
+
+func TestParseRobotsSitemaps(t *testing.T) {
+	t.Parallel()
+
+	robots := "User-agent: *\nDisallow: /\n# Sitemap: https://example.com/commented.xml\nsitemap: https://example.com/a.xml\nSITEMAP:https://example.com/b.xml.gz\n"
+	got := parseRobotsSitemaps(strings.NewReader(robots))
+	want := []string{"https://example.com/a.xml", "https://example.com/b.xml.gz"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,237 @@
+// ABOUTME: Token estimation and chunking of long tool output for LLM context windows
+// ABOUTME: Splits text on paragraph, line, sentence and word boundaries and selects one chunk per call
+
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ChunkLimit bounds the size of a chunk of text. Zero fields are unlimited.
+type ChunkLimit struct {
+	MaxTokens int // Estimated tokens, see EstimateTokens
+	MaxChars  int // Characters (runes)
+}
+
+// ChunkInfo describes which part of a long content the result holds
+type ChunkInfo struct {
+	Chunk           int `json:"chunk"`            // 1-based index of the returned chunk
+	TotalChunks     int `json:"total_chunks"`     // Request chunk 2..total_chunks to read on
+	TotalChars      int `json:"total_chars"`      // Characters in the whole content
+	EstimatedTokens int `json:"estimated_tokens"` // Estimated tokens in the whole content
+}
+
+// textSize counts the characters of a text in the classes EstimateTokens
+// weighs differently, so sizes of adjacent pieces can be added up
+type textSize struct {
+	ascii int
+	other int
+}
+
+func measureText(s string) textSize {
+	var size textSize
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			size.ascii++
+		} else {
+			size.other++
+		}
+	}
+	return size
+}
+
+func (s textSize) add(o textSize) textSize {
+	return textSize{ascii: s.ascii + o.ascii, other: s.other + o.other}
+}
+
+func (s textSize) chars() int {
+	return s.ascii + s.other
+}
+
+func (s textSize) tokens() int {
+	return (s.ascii+3)/4 + s.other
+}
+
+// EstimateTokens approximates how many tokens text takes up in a model's
+// context. BPE tokenizers average about four characters per token of English
+// text but close to one per character of CJK and other scripts, so ASCII is
+// counted at four characters per token and everything else at one, which
+// errs on the high side.
+func EstimateTokens(text string) int {
+	return measureText(text).tokens()
+}
+
+func (l ChunkLimit) enabled() bool {
+	return l.MaxTokens > 0 || l.MaxChars > 0
+}
+
+func (l ChunkLimit) fits(size textSize) bool {
+	return (l.MaxTokens <= 0 || size.tokens() <= l.MaxTokens) &&
+		(l.MaxChars <= 0 || size.chars() <= l.MaxChars)
+}
+
+// sentenceEnd matches the punctuation and whitespace ending a sentence
+var sentenceEnd = regexp.MustCompile(`[.!?]+["'”’)\]]*\s+|[。！？]+`)
+
+// chunkSplitters break text at ever finer boundaries: paragraphs, lines,
+// sentences and words. Each keeps the separator with the preceding piece.
+var chunkSplitters = []func(string) []string{
+	func(s string) []string { return strings.SplitAfter(s, "\n\n") },
+	func(s string) []string { return strings.SplitAfter(s, "\n") },
+	splitSentences,
+	func(s string) []string { return strings.SplitAfter(s, " ") },
+}
+
+func splitSentences(s string) []string {
+	var pieces []string
+	start := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(s, -1) {
+		pieces = append(pieces, s[start:loc[1]])
+		start = loc[1]
+	}
+	return append(pieces, s[start:])
+}
+
+// ChunkText splits text into chunks within limit, breaking at the coarsest
+// boundary that works: paragraphs, then lines, sentences, words and, for
+// runs without any, single characters. Adjacent pieces are packed into a
+// chunk as long as they fit. Text within the limit is returned whole.
+func ChunkText(text string, limit ChunkLimit) []string {
+	if !limit.enabled() || limit.fits(measureText(text)) {
+		return []string{text}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	var currentSize textSize
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+		currentSize = textSize{}
+	}
+
+	var add func(piece string, level int)
+	add = func(piece string, level int) {
+		size := measureText(piece)
+		if limit.fits(currentSize.add(size)) {
+			current.WriteString(piece)
+			currentSize = currentSize.add(size)
+			return
+		}
+		flush()
+		if limit.fits(size) {
+			current.WriteString(piece)
+			currentSize = size
+			return
+		}
+		if level == len(chunkSplitters) {
+			// No boundary left; a single character always fits
+			for _, r := range piece {
+				add(string(r), level)
+			}
+			return
+		}
+		for _, p := range chunkSplitters[level](piece) {
+			if p != "" {
+				add(p, level+1)
+			}
+		}
+	}
+	add(text, 0)
+	flush()
+
+	if len(chunks) == 0 {
+		return []string{""}
+	}
+	return chunks
+}
+
+// contentWindow selects the chunk of a tool's content that the max_tokens,
+// max_chars and chunk parameters ask for
+type contentWindow struct {
+	limit ChunkLimit
+	chunk int // 1-based
+}
+
+// contentWindow validates the chunking parameters of a call. max_tokens
+// defaults to the budget set with WithTokenBudget.
+func (c *toolConfig) contentWindow(maxTokens, maxChars, chunk int) (contentWindow, error) {
+	if maxTokens < 0 || maxChars < 0 {
+		return contentWindow{}, fmt.Errorf("max_tokens and max_chars must not be negative")
+	}
+	if chunk < 0 {
+		return contentWindow{}, fmt.Errorf("invalid chunk %d (chunks are numbered from 1)", chunk)
+	}
+	if maxTokens == 0 {
+		maxTokens = c.tokenBudget
+	}
+	if chunk == 0 {
+		chunk = 1
+	}
+	return contentWindow{limit: ChunkLimit{MaxTokens: maxTokens, MaxChars: maxChars}, chunk: chunk}, nil
+}
+
+// apply returns the requested chunk of content and, when a limit is set, a
+// description of the chunking
+func (w contentWindow) apply(content string) (string, *ChunkInfo, error) {
+	if !w.limit.enabled() {
+		if w.chunk > 1 {
+			return "", nil, fmt.Errorf("chunk %d requested without max_tokens or max_chars", w.chunk)
+		}
+		return content, nil, nil
+	}
+
+	chunks := ChunkText(content, w.limit)
+	if w.chunk > len(chunks) {
+		return "", nil, fmt.Errorf("chunk %d out of range (content has %d chunks)", w.chunk, len(chunks))
+	}
+	size := measureText(content)
+	return chunks[w.chunk-1], &ChunkInfo{
+		Chunk:           w.chunk,
+		TotalChunks:     len(chunks),
+		TotalChars:      size.chars(),
+		EstimatedTokens: size.tokens(),
+	}, nil
+}
+
+// applyItems splits a list of items of the given sizes into runs within the
+// limit and returns the bounds of the requested run and, when a limit is
+// set, a description of the split. An item too large to share a run makes
+// up a run of its own.
+func (w contentWindow) applyItems(sizes []textSize) (start, end int, chunking *ChunkInfo, err error) {
+	if !w.limit.enabled() {
+		if w.chunk > 1 {
+			return 0, 0, nil, fmt.Errorf("chunk %d requested without max_tokens or max_chars", w.chunk)
+		}
+		return 0, len(sizes), nil, nil
+	}
+
+	var total, run textSize
+	runs := []int{0} // Start of each run
+	for i, size := range sizes {
+		if i > runs[len(runs)-1] && !w.limit.fits(run.add(size)) {
+			runs = append(runs, i)
+			run = textSize{}
+		}
+		run = run.add(size)
+		total = total.add(size)
+	}
+	if w.chunk > len(runs) {
+		return 0, 0, nil, fmt.Errorf("chunk %d out of range (content has %d chunks)", w.chunk, len(runs))
+	}
+	start, end = runs[w.chunk-1], len(sizes)
+	if w.chunk < len(runs) {
+		end = runs[w.chunk]
+	}
+	return start, end, &ChunkInfo{
+		Chunk:           w.chunk,
+		TotalChunks:     len(runs),
+		TotalChars:      total.chars(),
+		EstimatedTokens: total.tokens(),
+	}, nil
+}
@@ -0,0 +1,198 @@
+// ABOUTME: Unit tests for token estimation, text chunking and the model catalog
+// ABOUTME: Tests boundary selection, chunk limits, chunk selection in fetch_webpage and context window budgets
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		"":            0,
+		"word":        1,
+		"hello world": 3,
+		"日本語のテキスト":    8,
+		"café":        2,
+	}
+	for text, want := range tests {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fits", func(t *testing.T) {
+		chunks := ChunkText("Short text.", ChunkLimit{MaxChars: 100})
+		if len(chunks) != 1 || chunks[0] != "Short text." {
+			t.Errorf("Expected text returned whole, got %q", chunks)
+		}
+	})
+
+	t.Run("paragraphs", func(t *testing.T) {
+		text := "First paragraph is here.\n\nSecond paragraph is here.\n\nThird paragraph is here."
+		chunks := ChunkText(text, ChunkLimit{MaxChars: 60})
+		want := []string{"First paragraph is here.\n\nSecond paragraph is here.", "Third paragraph is here."}
+		if fmt.Sprint(chunks) != fmt.Sprint(want) {
+			t.Errorf("Expected paragraph chunks %q, got %q", want, chunks)
+		}
+	})
+
+	t.Run("sentences", func(t *testing.T) {
+		text := "One sentence here. Another one follows! Does a third? Yes."
+		chunks := ChunkText(text, ChunkLimit{MaxChars: 25})
+		want := []string{"One sentence here.", "Another one follows!", "Does a third? Yes."}
+		if fmt.Sprint(chunks) != fmt.Sprint(want) {
+			t.Errorf("Expected sentence chunks %q, got %q", want, chunks)
+		}
+	})
+
+	t.Run("words and characters", func(t *testing.T) {
+		text := "supercalifragilistic expialidocious"
+		chunks := ChunkText(text, ChunkLimit{MaxChars: 10})
+		for _, c := range chunks {
+			if utf8.RuneCountInString(c) > 10 {
+				t.Errorf("Chunk %q exceeds 10 characters", c)
+			}
+		}
+		if strings.Join(chunks, "") != strings.ReplaceAll(text, " ", "") {
+			t.Errorf("Expected all text kept, got %q", chunks)
+		}
+	})
+
+	t.Run("tokens", func(t *testing.T) {
+		var b strings.Builder
+		for i := range 200 {
+			fmt.Fprintf(&b, "Sentence number %d of a long document. ", i)
+			if i%10 == 9 {
+				b.WriteString("\n\n")
+			}
+		}
+		chunks := ChunkText(b.String(), ChunkLimit{MaxTokens: 200})
+		if len(chunks) < 10 {
+			t.Errorf("Expected the document split into many chunks, got %d", len(chunks))
+		}
+		for _, c := range chunks {
+			if EstimateTokens(c) > 200 {
+				t.Errorf("Chunk of %d tokens exceeds the limit", EstimateTokens(c))
+			}
+			if !strings.HasSuffix(c, ".") {
+				t.Errorf("Expected chunk to end at a sentence boundary: %q", c[max(0, len(c)-40):])
+			}
+		}
+	})
+}
+
+func TestFetchWebPageHandler_Chunking(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><head><title>Long</title></head><body>")
+		for i := range 100 {
+			fmt.Fprintf(w, "<p>Paragraph %d has a few words in it.</p>", i)
+		}
+		fmt.Fprint(w, "</body></html>")
+	}))
+	defer server.Close()
+
+	cfg := newToolConfig()
+	first, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL, ExtractText: true, MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	info := first.Chunking
+	if info == nil || info.Chunk != 1 || info.TotalChunks < 2 || info.EstimatedTokens <= 100 {
+		t.Fatalf("Unexpected chunking info: %+v", info)
+	}
+	if EstimateTokens(first.Content) > 100 || !strings.Contains(first.Content, "Paragraph 0 ") {
+		t.Errorf("Unexpected first chunk: %q", first.Content)
+	}
+
+	last, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL, ExtractText: true, MaxTokens: 100, Chunk: info.TotalChunks})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(last.Content, "Paragraph 99") || last.Title != "Long" {
+		t.Errorf("Expected last chunk to hold the end of the page, got %q", last.Content)
+	}
+
+	if _, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL, ExtractText: true, MaxTokens: 100, Chunk: info.TotalChunks + 1}); err == nil {
+		t.Error("Expected error for a chunk past the end")
+	}
+
+	// The configured budget applies when max_tokens is not given
+	budgeted, err := newToolConfig(WithTokenBudget(100)).fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL, ExtractText: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if budgeted.Chunking == nil || budgeted.Chunking.TotalChunks != info.TotalChunks {
+		t.Errorf("Expected the token budget to chunk content, got %+v", budgeted.Chunking)
+	}
+
+	whole, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL, ExtractText: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if whole.Chunking != nil || !strings.Contains(whole.Content, "Paragraph 99") {
+		t.Errorf("Expected whole content without limits, got chunking %+v", whole.Chunking)
+	}
+}
+
+func TestModelCatalog(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := ParseModelCatalog([]byte(`[
+		{"provider": "anthropic", "name": "claude-3-haiku-20240307", "context_window": 200000, "max_output_tokens": 4096},
+		{"provider": "openai", "name": "gpt-4o", "context_window": 0}
+	]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := catalog.ContextWindow("anthropic/Claude-3-Haiku-20240307"); got != 200000 {
+		t.Errorf("Expected context window 200000, got %d", got)
+	}
+	if got := catalog.TokenBudget("claude-3-haiku-20240307"); got != (200000-4096)/4 {
+		t.Errorf("Unexpected token budget %d", got)
+	}
+	if got := catalog.TokenBudget("gpt-4o"); got != 0 {
+		t.Errorf("Expected no budget for an unknown context window, got %d", got)
+	}
+	if got := catalog.TokenBudget("missing"); got != 0 {
+		t.Errorf("Expected no budget for an unknown model, got %d", got)
+	}
+
+	inventory, err := ParseModelCatalog([]byte(`{"_metadata": {"version": "1"}, "models": [{"name": "m", "context_window": 8192}]}`))
+	if err != nil || inventory.ContextWindow("m") != 8192 {
+		t.Errorf("Expected inventory format to parse, got %v", err)
+	}
+
+	if _, err := ParseModelCatalog([]byte(`not json`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+
+	// The catalog shipped at the repository root
+	shipped, err := LoadModelCatalog(filepath.Join("..", "..", "models.json"))
+	if err != nil {
+		t.Fatalf("Failed to load models.json: %v", err)
+	}
+	if shipped.ContextWindow("gemini-1.5-pro") != 2000000 {
+		t.Errorf("Unexpected context window for gemini-1.5-pro: %d", shipped.ContextWindow("gemini-1.5-pro"))
+	}
+}
@@ -0,0 +1,241 @@
+// ABOUTME: URL policy that guards tools against server-side request forgery
+// ABOUTME: Restricts schemes and domains and blocks private, loopback and link-local addresses after DNS resolution
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// URLPolicy restricts which URLs tools may fetch. It is enforced on every
+// request a tool makes, including redirects and robots.txt lookups. The zero
+// value allows http and https URLs on public addresses only.
+type URLPolicy struct {
+	Schemes              []string // Allowed URL schemes; defaults to http and https
+	AllowedDomains       []string // When set, only these hosts and their subdomains may be fetched
+	DeniedDomains        []string // Hosts and their subdomains that may never be fetched; wins over AllowedDomains
+	AllowPrivateNetworks bool     // Permit loopback, private, link-local and other non-public addresses
+}
+
+// URLPolicyError is returned when a URLPolicy forbids fetching a URL
+type URLPolicyError struct {
+	URL    string
+	Reason string
+}
+
+func (e *URLPolicyError) Error() string {
+	return fmt.Sprintf("fetching %s blocked by URL policy: %s", e.URL, e.Reason)
+}
+
+// nonPublicPrefixes are address ranges not covered by the netip predicates
+// that must never be reachable from an agent
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // Reserved
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use IPv4/IPv6 translation
+	netip.MustParsePrefix("100::/64"),       // Discard-only
+	netip.MustParsePrefix("2001::/32"),      // Teredo, which can tunnel to private IPv4
+	netip.MustParsePrefix("2001:db8::/32"),  // Documentation
+	netip.MustParsePrefix("2002::/16"),      // 6to4, which can tunnel to private IPv4
+	netip.MustParsePrefix("fec0::/10"),      // Deprecated site-local
+}
+
+// isPublicAddr reports whether addr is a globally routable unicast address.
+// Loopback, link-local (including cloud metadata at 169.254.169.254),
+// multicast and RFC 1918 / ULA private addresses are rejected by the netip
+// predicates.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns a *URLPolicyError if the policy forbids fetching u. Unless
+// private networks are allowed, the host is resolved and every address it
+// resolves to must be public.
+func (p *URLPolicy) Check(ctx context.Context, u *url.URL) error {
+	if err := p.checkURL(u); err != nil {
+		return err
+	}
+	if p.AllowPrivateNetworks {
+		return nil
+	}
+
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(u.String(), addr)
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		// Let the request fail with the usual DNS error
+		return nil
+	}
+	for _, addr := range addrs {
+		if err := p.checkAddr(u.String(), addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkURL applies the scheme and domain rules, which need no network access
+func (p *URLPolicy) checkURL(u *url.URL) error {
+	schemes := p.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	if !slices.ContainsFunc(schemes, func(s string) bool { return strings.EqualFold(s, u.Scheme) }) {
+		return &URLPolicyError{URL: u.String(), Reason: fmt.Sprintf("scheme %q not allowed", u.Scheme)}
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return &URLPolicyError{URL: u.String(), Reason: "missing host"}
+	}
+	if domainListMatch(p.DeniedDomains, host) {
+		return &URLPolicyError{URL: u.String(), Reason: fmt.Sprintf("host %s is denied", host)}
+	}
+	if len(p.AllowedDomains) > 0 && !domainListMatch(p.AllowedDomains, host) {
+		return &URLPolicyError{URL: u.String(), Reason: fmt.Sprintf("host %s is not allowed", host)}
+	}
+	return nil
+}
+
+func (p *URLPolicy) checkAddr(rawURL string, addr netip.Addr) error {
+	if p.AllowPrivateNetworks || isPublicAddr(addr) {
+		return nil
+	}
+	return &URLPolicyError{URL: rawURL, Reason: fmt.Sprintf("address %s is not public", addr.Unmap())}
+}
+
+// domainListMatch reports whether host equals or is a subdomain of an entry.
+// Entries may be written as "example.com", ".example.com" or "*.example.com".
+func domainListMatch(domains []string, host string) bool {
+	for _, d := range domains {
+		d = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(d)), ".")
+		d = strings.TrimPrefix(strings.TrimPrefix(d, "*"), ".")
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyTransport checks every request, including each redirect hop, against
+// a URLPolicy before passing it on
+type policyTransport struct {
+	next   http.RoundTripper
+	policy *URLPolicy
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *policyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.Check(req.Context(), req.URL); err != nil {
+		return nil, err
+	}
+	// The shared transport checks the address it actually dials as well, so a
+	// host whose DNS answer changes after the check cannot slip through
+	ctx := context.WithValue(req.Context(), urlPolicyKey{}, t.policy)
+	return t.next.RoundTrip(req.WithContext(ctx))
+}
+
+type urlPolicyKey struct{}
+
+// guardDial rejects connections to addresses forbidden by the URL policy
+// carried by the dial context. It runs after DNS resolution for every address
+// the dialer tries, except for connections to a configured proxy.
+func guardDial(ctx context.Context, network, address string, _ syscall.RawConn) error {
+	policy, ok := ctx.Value(urlPolicyKey{}).(*URLPolicy)
+	if !ok || policy.AllowPrivateNetworks || ctx.Value(proxyDialKey{}) != nil {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return &URLPolicyError{URL: address, Reason: "unparseable dial address"}
+	}
+	return policy.checkAddr(network+"://"+address, addrPort.Addr())
+}
+
+// guardTransport returns a copy of a transport given with WithHTTPClient
+// whose connections are checked like those of the shared transport. A nil
+// transport stands for http.DefaultTransport. Other round trippers are
+// returned unchanged: only the check before each request applies to them.
+func guardTransport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return rt
+	}
+	t := base.Clone()
+
+	// Remember the proxies the transport picks so connections to them are let
+	// through, as proxyAwareDial does for the shared transport
+	var proxies sync.Map
+	if proxy := base.Proxy; proxy != nil {
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			u, err := proxy(req)
+			if u != nil {
+				for addr := range proxyAddrs(u.String()) {
+					proxies.Store(addr, true)
+				}
+			}
+			return u, err
+		}
+	}
+
+	dial := base.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{
+			Timeout:        30 * time.Second,
+			KeepAlive:      30 * time.Second,
+			ControlContext: guardDial,
+		}).DialContext
+	}
+	t.DialContext = guardConnDial(dial, &proxies)
+	if base.DialTLSContext != nil {
+		t.DialTLSContext = guardConnDial(base.DialTLSContext, &proxies)
+	}
+	return t
+}
+
+// guardConnDial wraps a dial function so the address a connection reached
+// is checked against the URL policy, even when a custom dialer resolves host
+// names itself
+func guardConnDial(dial func(ctx context.Context, network, address string) (net.Conn, error), proxies *sync.Map) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if _, ok := proxies.Load(address); ok {
+			ctx = context.WithValue(ctx, proxyDialKey{}, true)
+		}
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		if err := guardDial(ctx, network, conn.RemoteAddr().String(), nil); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+}
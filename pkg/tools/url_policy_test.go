@@ -0,0 +1,234 @@
+// ABOUTME: Unit tests for the URL policy guarding tools against SSRF
+// ABOUTME: Tests address classification, scheme and domain rules, redirects and the dial-time check
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]bool{
+		"8.8.8.8":                true,
+		"2606:4700::1111":        true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"255.255.255.255":        false,
+		"224.0.0.1":              false,
+		"::1":                    false,
+		"fe80::1":                false,
+		"fd00:ec2::254":          false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"2002:a00:1::":           false,
+	}
+	for addr, want := range tests {
+		if got := isPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestURLPolicyCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy URLPolicy
+		url    string
+		reason string // Empty when the URL is allowed
+	}{
+		{name: "public address", url: "http://93.184.215.14/page"},
+		{name: "cloud metadata", url: "http://169.254.169.254/latest/meta-data/", reason: "address 169.254.169.254 is not public"},
+		{name: "private address", url: "https://10.0.0.5:8443/", reason: "address 10.0.0.5 is not public"},
+		{name: "ipv6 loopback", url: "http://[::1]/", reason: "address ::1 is not public"},
+		{name: "resolved loopback", url: "http://localhost/admin", reason: "is not public"},
+		{name: "scheme", url: "file:///etc/passwd", reason: `scheme "file" not allowed`},
+		{name: "custom schemes", policy: URLPolicy{Schemes: []string{"https"}}, url: "http://93.184.215.14/", reason: `scheme "http" not allowed`},
+		{name: "private allowed", policy: URLPolicy{AllowPrivateNetworks: true}, url: "http://192.168.1.10/"},
+		{name: "allowlist subdomain", policy: URLPolicy{AllowPrivateNetworks: true, AllowedDomains: []string{"example.com"}}, url: "https://docs.Example.com./guide"},
+		{name: "allowlist miss", policy: URLPolicy{AllowPrivateNetworks: true, AllowedDomains: []string{"example.com"}}, url: "https://notexample.com/", reason: "host notexample.com is not allowed"},
+		{name: "denylist wins", policy: URLPolicy{AllowPrivateNetworks: true, AllowedDomains: []string{"example.com"}, DeniedDomains: []string{"*.internal.example.com"}}, url: "http://db.internal.example.com/", reason: "host db.internal.example.com is denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatalf("Failed to parse URL: %v", err)
+			}
+			err = tt.policy.Check(context.Background(), u)
+			if tt.reason == "" {
+				if err != nil {
+					t.Errorf("Expected %s to be allowed, got %v", tt.url, err)
+				}
+				return
+			}
+			var policyErr *URLPolicyError
+			if !errors.As(err, &policyErr) || !strings.Contains(policyErr.Reason, tt.reason) {
+				t.Errorf("Expected *URLPolicyError containing %q, got %v", tt.reason, err)
+			}
+		})
+	}
+}
+
+func TestURLPolicy_Tools(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.NotFound(w, r)
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprint(w, `<rss version="2.0"><channel><title>Feed</title></channel></rss>`)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>Internal</title></head><body><a href="/x">x</a></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	cfg := newToolConfig(WithURLPolicy(&URLPolicy{}))
+	ctx := context.Background()
+
+	calls := map[string]func() error{
+		"fetch_webpage": func() error {
+			_, err := cfg.fetchWebPageHandler(ctx, FetchWebPageParams{URL: server.URL})
+			return err
+		},
+		"extract_links": func() error {
+			_, err := cfg.extractLinksHandler(ctx, ExtractLinksParams{URL: server.URL})
+			return err
+		},
+		"extract_metadata": func() error {
+			_, err := cfg.extractMetadataHandler(ctx, ExtractMetadataParams{URL: server.URL})
+			return err
+		},
+		"check_url_status": func() error {
+			_, err := cfg.checkURLStatusHandler(ctx, CheckURLStatusParams{URL: server.URL})
+			return err
+		},
+		"fetch_rss_feed": func() error {
+			_, err := cfg.fetchRSSFeedHandler(ctx, FetchRSSFeedParams{URL: server.URL + "/feed.xml"})
+			return err
+		},
+	}
+	for name, call := range calls {
+		var policyErr *URLPolicyError
+		if err := call(); !errors.As(err, &policyErr) {
+			t.Errorf("%s: expected *URLPolicyError for a loopback server, got %v", name, err)
+		}
+	}
+
+	// Without a policy the same server is reachable
+	if _, err := newToolConfig().fetchWebPageHandler(ctx, FetchWebPageParams{URL: server.URL}); err != nil {
+		t.Errorf("Expected fetch without a policy to succeed, got %v", err)
+	}
+}
+
+func TestURLPolicy_Redirects(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.NotFound(w, r)
+		case "/go":
+			// Same server, but reached through a host the policy does not allow
+			target := *r.URL
+			target.Scheme = "http"
+			target.Host = strings.Replace(r.Host, "127.0.0.1", "localhost", 1)
+			target.Path = "/landing"
+			http.Redirect(w, r, target.String(), http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body>landing</body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	cfg := newToolConfig(WithURLPolicy(&URLPolicy{
+		AllowPrivateNetworks: true,
+		AllowedDomains:       []string{"127.0.0.1"},
+	}))
+
+	if _, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/landing"}); err != nil {
+		t.Fatalf("Expected allowed host to be fetched, got %v", err)
+	}
+
+	_, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: server.URL + "/go", FollowRedirects: true})
+	var policyErr *URLPolicyError
+	if !errors.As(err, &policyErr) || !strings.Contains(policyErr.Reason, "host localhost is not allowed") {
+		t.Errorf("Expected redirect to be blocked, got %v", err)
+	}
+}
+
+func TestGuardDial(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Bypass the up-front check to exercise the dial-time check of the
+	// shared transport, which catches DNS answers that change after it
+	ctx := context.WithValue(context.Background(), urlPolicyKey{}, &URLPolicy{})
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	_, err = NewTransport().RoundTrip(req)
+	var policyErr *URLPolicyError
+	if !errors.As(err, &policyErr) || !strings.Contains(policyErr.Reason, "address 127.0.0.1 is not public") {
+		t.Errorf("Expected dial to be blocked, got %v", err)
+	}
+}
+
+func TestGuardDial_CustomClient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Reached</title></head></html>`)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	// A dialer that ignores the address stands in for a DNS answer that
+	// changes to a private address after the up-front check
+	rebinding := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, serverURL.Host)
+		},
+	}}
+	cfg := newToolConfig(WithHTTPClient(rebinding), WithURLPolicy(&URLPolicy{}), WithIgnoreRobots())
+	_, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: "http://93.184.216.34/"})
+	var policyErr *URLPolicyError
+	if !errors.As(err, &policyErr) || !strings.Contains(policyErr.Reason, "address 127.0.0.1 is not public") {
+		t.Errorf("Expected dial to be blocked, got %v", err)
+	}
+
+	// Connections to the client's own proxy are let through
+	proxied := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(serverURL)}}
+	cfg = newToolConfig(WithHTTPClient(proxied), WithURLPolicy(&URLPolicy{}), WithIgnoreRobots())
+	result, err := cfg.fetchWebPageHandler(context.Background(), FetchWebPageParams{URL: "http://93.184.216.34/"})
+	if err != nil || result.Title != "Reached" {
+		t.Errorf("Expected the request to go through the proxy, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,370 @@
+// ABOUTME: Readability-style main article extraction for fetch_webpage
+// ABOUTME: Scores DOM blocks to isolate the article body and collects byline, date and lead image
+
+package tools
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ArticleInfo describes the main article found in "article" extract mode
+type ArticleInfo struct {
+	Byline        string `json:"byline,omitempty"`
+	PublishedDate string `json:"published_date,omitempty"`
+	LeadImage     string `json:"lead_image,omitempty"`
+	WordCount     int    `json:"word_count"`
+}
+
+// article is the result of extractArticle
+type article struct {
+	content *html.Node // Detached container holding the article body
+	info    ArticleInfo
+}
+
+var (
+	unlikelyCandidateRegex = regexp.MustCompile(`(?i)ad-break|agegate|banner|breadcrumb|combx|comment|community|consent|cookie|disqus|extra|foot|header|legends|menu|modal|nav|newsletter|pager|pagination|popup|promo|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe`)
+	maybeCandidateRegex    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveWeightRegex    = regexp.MustCompile(`(?i)article|blog|body|content|entry|h-entry|hentry|main|page|post|story|text`)
+	negativeWeightRegex    = regexp.MustCompile(`(?i)-ad-|banner|combx|comment|com-|contact|foot|footnote|gdpr|hidden|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	bylineRegex            = regexp.MustCompile(`(?i)byline|author|writtenby|p-author`)
+)
+
+// removedElements are dropped before scoring; they never hold article text
+var removedElements = map[string]bool{
+	"aside": true, "button": true, "footer": true, "form": true, "input": true,
+	"nav": true, "select": true, "textarea": true,
+}
+
+// extractArticle isolates the main article of a parsed document. The
+// document is modified in the process. base resolves the lead image URL.
+func extractArticle(doc *html.Node, base *url.URL) *article {
+	info := ArticleInfo{
+		Byline:        metaContent(doc, "author", "article:author", "dc.creator", "parsely-author"),
+		PublishedDate: metaContent(doc, "article:published_time", "datepublished", "pubdate", "publishdate", "date", "dc.date", "dcterms.created", "parsely-pub-date"),
+		LeadImage:     metaContent(doc, "og:image", "og:image:url", "twitter:image"),
+	}
+	if strings.HasPrefix(info.Byline, "http") {
+		// article:author is often a profile URL rather than a name
+		info.Byline = ""
+	}
+	if info.PublishedDate == "" {
+		info.PublishedDate = publishedDateFromDOM(doc)
+	}
+
+	body := domFind(doc, func(n *html.Node) bool { return isTag(n, "body") })
+	if body == nil {
+		body = doc
+	}
+
+	pruneUnlikely(body, &info)
+	content := topCandidateContent(body)
+	cleanConditionally(content)
+
+	if info.LeadImage == "" {
+		if img := domFind(content, func(n *html.Node) bool { return isTag(n, "img") && domAttr(n, "src") != "" }); img != nil {
+			info.LeadImage = domAttr(img, "src")
+		}
+	}
+	if info.LeadImage != "" && base != nil {
+		if u, err := resolveURL(base, info.LeadImage); err == nil {
+			info.LeadImage = u.String()
+		}
+	}
+
+	info.WordCount = len(strings.Fields(domText(content)))
+	return &article{content: content, info: info}
+}
+
+// metaContent returns the content of the first <meta> whose name, property
+// or itemprop matches one of keys, trying keys in order
+func metaContent(doc *html.Node, keys ...string) string {
+	metas := domFindAll(doc, func(n *html.Node) bool { return isTag(n, "meta") })
+	for _, key := range keys {
+		for _, m := range metas {
+			for _, attr := range []string{"name", "property", "itemprop"} {
+				if strings.EqualFold(domAttr(m, attr), key) {
+					if v := strings.TrimSpace(domAttr(m, "content")); v != "" {
+						return v
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// publishedDateFromDOM looks for microdata or <time> elements carrying a date
+func publishedDateFromDOM(doc *html.Node) string {
+	if n := domFind(doc, func(n *html.Node) bool { return strings.EqualFold(domAttr(n, "itemprop"), "datePublished") }); n != nil {
+		for _, attr := range []string{"datetime", "content"} {
+			if v := domAttr(n, attr); v != "" {
+				return v
+			}
+		}
+		return domText(n)
+	}
+	if n := domFind(doc, func(n *html.Node) bool { return isTag(n, "time") && domAttr(n, "datetime") != "" }); n != nil {
+		return domAttr(n, "datetime")
+	}
+	return ""
+}
+
+// pruneUnlikely removes hidden, navigational and boilerplate elements. A
+// byline found along the way is recorded in info when none is known yet.
+func pruneUnlikely(root *html.Node, info *ArticleInfo) {
+	var remove []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.CommentNode {
+			remove = append(remove, n)
+			return
+		}
+		if n.Type == html.ElementNode {
+			if hiddenElements[n.Data] || removedElements[n.Data] || isHiddenElement(n) {
+				remove = append(remove, n)
+				return
+			}
+
+			match := domAttr(n, "class") + " " + domAttr(n, "id")
+			if isByline(n, match) {
+				if info.Byline == "" {
+					info.Byline = strings.TrimPrefix(domText(n), "By ")
+				}
+				remove = append(remove, n)
+				return
+			}
+			if !isTag(n, "body", "article", "main", "a") &&
+				unlikelyCandidateRegex.MatchString(match) && !maybeCandidateRegex.MatchString(match) {
+				remove = append(remove, n)
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	for _, n := range remove {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+func isHiddenElement(n *html.Node) bool {
+	style := strings.ReplaceAll(strings.ToLower(domAttr(n, "style")), " ", "")
+	for _, a := range n.Attr {
+		if a.Key == "hidden" {
+			return true
+		}
+	}
+	return strings.Contains(style, "display:none") ||
+		strings.Contains(style, "visibility:hidden") ||
+		domAttr(n, "aria-hidden") == "true"
+}
+
+func isByline(n *html.Node, match string) bool {
+	isAuthor := domAttr(n, "rel") == "author" || strings.Contains(strings.ToLower(domAttr(n, "itemprop")), "author")
+	if !isAuthor && !bylineRegex.MatchString(match) {
+		return false
+	}
+	text := domText(n)
+	return text != "" && len(text) < 100
+}
+
+// topCandidateContent scores paragraphs, credits their ancestors and returns
+// a container with the best-scoring node and its related siblings
+func topCandidateContent(body *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+
+	initialize := func(n *html.Node) {
+		if _, ok := scores[n]; ok {
+			return
+		}
+		score := classWeight(n)
+		switch n.DataAtom {
+		case atom.Div, atom.Article, atom.Main, atom.Section:
+			score += 5
+		case atom.Pre, atom.Td, atom.Blockquote:
+			score += 3
+		case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+			score -= 3
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+			score -= 5
+		}
+		if n.DataAtom == atom.Article {
+			score += 10
+		}
+		scores[n] = score
+		candidates = append(candidates, n)
+	}
+
+	for _, p := range domFindAll(body, isParagraphLike) {
+		text := domText(p)
+		if len(text) < 25 {
+			continue
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+
+		level := 0
+		for ancestor := p.Parent; ancestor != nil && level < 3; ancestor = ancestor.Parent {
+			if ancestor.Type != html.ElementNode || isTag(ancestor, "html") {
+				break
+			}
+			initialize(ancestor)
+			switch level {
+			case 0:
+				scores[ancestor] += score
+			case 1:
+				scores[ancestor] += score / 2
+			default:
+				scores[ancestor] += score / float64(level*3)
+			}
+			level++
+		}
+	}
+
+	var top *html.Node
+	best := 0.0
+	for _, c := range candidates {
+		score := scores[c] * (1 - linkDensity(c))
+		scores[c] = score
+		if top == nil || score > best {
+			top, best = c, score
+		}
+	}
+
+	container := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	if top == nil {
+		moveChildren(body, container)
+		return container
+	}
+
+	parent := top.Parent
+	if parent == nil {
+		detach(top)
+		container.AppendChild(top)
+		return container
+	}
+
+	threshold := max(10, best*0.2)
+	var keep []*html.Node
+	for sibling := parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling == top {
+			keep = append(keep, sibling)
+			continue
+		}
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+		bonus := 0.0
+		if domAttr(sibling, "class") != "" && domAttr(sibling, "class") == domAttr(top, "class") {
+			bonus = best * 0.2
+		}
+		if score, ok := scores[sibling]; ok && score+bonus >= threshold {
+			keep = append(keep, sibling)
+			continue
+		}
+		if isTag(sibling, "p") {
+			text := domText(sibling)
+			density := linkDensity(sibling)
+			if (len(text) > 80 && density < 0.25) ||
+				(len(text) > 0 && density == 0 && strings.Contains(text, ". ")) {
+				keep = append(keep, sibling)
+			}
+		}
+	}
+
+	for _, n := range keep {
+		detach(n)
+		container.AppendChild(n)
+	}
+	return container
+}
+
+// isParagraphLike reports whether n holds paragraph text: p, pre and td
+// elements, and divs without block-level children
+func isParagraphLike(n *html.Node) bool {
+	if isTag(n, "p", "pre", "td", "blockquote") {
+		return true
+	}
+	if !isTag(n, "div") {
+		return false
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && blockElements[c.Data] {
+			return false
+		}
+	}
+	return true
+}
+
+// classWeight scores an element's class and id against article-like and boilerplate names
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, v := range []string{domAttr(n, "class"), domAttr(n, "id")} {
+		if v == "" {
+			continue
+		}
+		if negativeWeightRegex.MatchString(v) {
+			weight -= 25
+		}
+		if positiveWeightRegex.MatchString(v) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity is the share of an element's text that sits inside links
+func linkDensity(n *html.Node) float64 {
+	total := len(domText(n))
+	if total == 0 {
+		return 0
+	}
+	linkText := 0
+	for _, a := range domFindAll(n, func(n *html.Node) bool { return isTag(n, "a") }) {
+		linkText += len(domText(a))
+	}
+	return float64(linkText) / float64(total)
+}
+
+// cleanConditionally removes link-heavy lists and blocks left inside the article
+func cleanConditionally(content *html.Node) {
+	blocks := domFindAll(content, func(n *html.Node) bool {
+		return n != content && isTag(n, "div", "section", "ul", "ol", "table")
+	})
+	// Visit innermost blocks first so removals do not hide nested candidates
+	for i := len(blocks) - 1; i >= 0; i-- {
+		n := blocks[i]
+		if n.Parent == nil {
+			continue
+		}
+		if len(domText(n)) < 200 && (classWeight(n) < 0 || linkDensity(n) > 0.5) {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+func detach(n *html.Node) {
+	if n.Parent != nil {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+func moveChildren(from, to *html.Node) {
+	for c := from.FirstChild; c != nil; {
+		next := c.NextSibling
+		from.RemoveChild(c)
+		to.AppendChild(c)
+		c = next
+	}
+}
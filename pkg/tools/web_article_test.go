@@ -0,0 +1,141 @@
+// ABOUTME: Unit tests for readability-style article extraction
+// ABOUTME: Tests boilerplate removal and byline, date, lead image and word count detection
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const sampleArticleHTML = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Rivers Return to the Valley</title>
+	<meta property="article:published_time" content="2024-03-02T08:00:00Z">
+</head>
+<body>
+	<header class="site-header">
+		<nav><a href="/">Home</a> <a href="/news">News</a> <a href="/sport">Sport</a></nav>
+	</header>
+	<div id="cookie-banner">We use cookies to improve your experience. Accept all cookies?</div>
+	<div class="layout">
+		<div class="sidebar">
+			<ul><li><a href="/a">Trending story one</a></li><li><a href="/b">Trending story two</a></li></ul>
+		</div>
+		<article class="story">
+			<h1>Rivers Return to the Valley</h1>
+			<p class="byline">By Jane Rivera</p>
+			<figure><img src="/images/valley.jpg" alt="The valley"></figure>
+			<p>After a decade of drought, the rivers of the northern valley are flowing again, bringing water to farms, towns and wetlands that had nearly dried out.</p>
+			<p>Local farmers, many of whom had abandoned their fields, say the return of the water has changed everything, from the crops they plant to the way they plan for the future.</p>
+			<p>Scientists caution, however, that the recovery may be temporary, and that long-term planning, careful monitoring and new storage projects will be needed to protect the region.</p>
+			<div class="share-tools"><a href="/share/fb">Facebook</a> <a href="/share/x">X</a></div>
+		</article>
+	</div>
+	<section id="comments">
+		<p>Great article, thanks for sharing this with all of us, really enjoyed it!</p>
+	</section>
+	<footer><p>Copyright 2024 Valley News. All rights reserved, including the right to reproduce.</p></footer>
+</body>
+</html>`
+
+func TestExtractArticle(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, sampleArticleHTML)
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{
+		URL:         server.URL + "/news/rivers",
+		ExtractMode: "article",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"After a decade of drought", "Local farmers", "Scientists caution"} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("Expected content to contain %q, got:\n%s", want, result.Content)
+		}
+	}
+	for _, unwanted := range []string{"Sport", "cookies", "Trending", "Great article", "Copyright", "Facebook", "By Jane"} {
+		if strings.Contains(result.Content, unwanted) {
+			t.Errorf("Expected content to exclude %q, got:\n%s", unwanted, result.Content)
+		}
+	}
+
+	if result.Title != "Rivers Return to the Valley" {
+		t.Errorf("Expected title 'Rivers Return to the Valley', got %q", result.Title)
+	}
+	if result.Article == nil {
+		t.Fatal("Expected article info")
+	}
+	if result.Article.Byline != "Jane Rivera" {
+		t.Errorf("Expected byline 'Jane Rivera', got %q", result.Article.Byline)
+	}
+	if result.Article.PublishedDate != "2024-03-02T08:00:00Z" {
+		t.Errorf("Expected published date '2024-03-02T08:00:00Z', got %q", result.Article.PublishedDate)
+	}
+	if result.Article.LeadImage != server.URL+"/images/valley.jpg" {
+		t.Errorf("Expected absolute lead image URL, got %q", result.Article.LeadImage)
+	}
+	if result.Article.WordCount < 60 || result.Article.WordCount > 100 {
+		t.Errorf("Expected word count of the article body, got %d", result.Article.WordCount)
+	}
+}
+
+func TestExtractArticle_MetadataFallbacks(t *testing.T) {
+	t.Parallel()
+
+	page := `<html><head>
+		<meta name="author" content="Sam Lee">
+		<meta property="og:image" content="https://cdn.example.com/lead.png">
+	</head><body><div id="main">
+		<time datetime="2023-11-20">November 20, 2023</time>
+		<p>The committee published its findings on Tuesday, concluding a two-year review of the program, its costs and its results.</p>
+		<p>Members agreed that the program should continue, but recommended changes to how funding is allocated across regions.</p>
+	</div></body></html>`
+
+	doc, err := parseHTML(page)
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	art := extractArticle(doc, nil)
+
+	if art.info.Byline != "Sam Lee" {
+		t.Errorf("Expected byline from meta author, got %q", art.info.Byline)
+	}
+	if art.info.PublishedDate != "2023-11-20" {
+		t.Errorf("Expected date from <time>, got %q", art.info.PublishedDate)
+	}
+	if art.info.LeadImage != "https://cdn.example.com/lead.png" {
+		t.Errorf("Expected lead image from og:image, got %q", art.info.LeadImage)
+	}
+	if text := domBlockText(art.content); !strings.Contains(text, "committee published") {
+		t.Errorf("Expected article text, got %q", text)
+	}
+}
+
+func TestFetchWebPageHandler_InvalidExtractMode(t *testing.T) {
+	t.Parallel()
+
+	_, err := newToolConfig().fetchWebPageHandler(context.Background(), FetchWebPageParams{
+		URL:         "http://example.com",
+		ExtractMode: "summary",
+	})
+	if err == nil || !strings.Contains(err.Error(), "extract_mode") {
+		t.Errorf("Expected extract_mode error, got %v", err)
+	}
+}
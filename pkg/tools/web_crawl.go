@@ -0,0 +1,523 @@
+// ABOUTME: Bounded site crawler tool built on the link extraction of extract_links
+// ABOUTME: Crawls breadth-first within a host or path scope with depth, page, pattern and concurrency limits
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
+	"github.com/lexlapax/go-llms/pkg/agent/tools"
+	sdomain "github.com/lexlapax/go-llms/pkg/schema/domain"
+	"golang.org/x/net/html"
+)
+
+// Crawl Site Tool
+
+type CrawlSiteParams struct {
+	URL           string   `json:"url" description:"The URL to start crawling from"`
+	MaxDepth      int      `json:"max_depth,omitempty" description:"Maximum link depth from the start page (default: 2, max: 5)"`
+	MaxPages      int      `json:"max_pages,omitempty" description:"Maximum number of pages to fetch (default: 20, max: 200)"`
+	Scope         string   `json:"scope,omitempty" description:"Which links to follow: host or path (default: host)"`
+	Include       []string `json:"include,omitempty" description:"Regular expressions; only follow URLs matching one of them"`
+	Exclude       []string `json:"exclude,omitempty" description:"Regular expressions; never follow URLs matching any of them"`
+	Concurrency   int      `json:"concurrency,omitempty" description:"Maximum parallel requests (default: 2, max: 8)"`
+	DelayMS       int      `json:"delay_ms,omitempty" description:"Minimum delay between requests in milliseconds (default: 0)"`
+	SummaryLength int      `json:"summary_length,omitempty" description:"Maximum characters in each page summary (default: 300)"`
+	Timeout       int      `json:"timeout,omitempty" description:"Timeout in seconds for each request (default: 30)"`
+	MaxTokens     int      `json:"max_tokens,omitempty" description:"Split a page list longer than this many estimated tokens into chunks"`
+	MaxChars      int      `json:"max_chars,omitempty" description:"Split a page list longer than this many characters into chunks"`
+	Chunk         int      `json:"chunk,omitempty" description:"Which chunk of a split page list to return (default: 1)"`
+}
+
+type CrawlSiteResult struct {
+	URL        string      `json:"url"`
+	Pages      []CrawlPage `json:"pages"`
+	PageCount  int         `json:"page_count"`         // Pages crawled, including those in other chunks
+	ErrorCount int         `json:"error_count"`        // Pages that failed, including those in other chunks
+	Truncated  bool        `json:"truncated"`          // More in-scope links remained when max_pages was reached
+	Chunking   *ChunkInfo  `json:"chunking,omitempty"` // Set when max_tokens or max_chars applies
+	CrawledAt  string      `json:"crawled_at"`
+}
+
+type CrawlPage struct {
+	URL         string   `json:"url"`
+	FinalURL    string   `json:"final_url,omitempty"` // Set when redirected
+	Depth       int      `json:"depth"`
+	StatusCode  int      `json:"status_code,omitempty"`
+	ContentType string   `json:"content_type,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Links       []string `json:"links"` // Outgoing links, internal and external
+	Error       string   `json:"error,omitempty"`
+}
+
+var CrawlSiteParamSchema = &sdomain.Schema{
+	Type:        "object",
+	Description: "Parameters for crawling a web site",
+	Properties: map[string]sdomain.Property{
+		"url": {
+			Type:        "string",
+			Description: "The URL to start crawling from",
+		},
+		"max_depth": {
+			Type:        "integer",
+			Description: "Maximum link depth from the start page, which is depth 0 (default: 2)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(5),
+		},
+		"max_pages": {
+			Type:        "integer",
+			Description: "Maximum number of pages to fetch (default: 20)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(200),
+		},
+		"scope": {
+			Type:        "string",
+			Description: "Which links to follow: 'host' for the start URL's host, 'path' for URLs under the start URL's directory (default: host)",
+			Enum:        []string{"host", "path"},
+		},
+		"include": {
+			Type:        "array",
+			Description: "Regular expressions matched against absolute URLs; when set, only matching URLs are followed",
+			Items:       &sdomain.Property{Type: "string"},
+		},
+		"exclude": {
+			Type:        "array",
+			Description: "Regular expressions matched against absolute URLs; matching URLs are never followed",
+			Items:       &sdomain.Property{Type: "string"},
+		},
+		"concurrency": {
+			Type:        "integer",
+			Description: "Maximum parallel requests (default: 2)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(8),
+		},
+		"delay_ms": {
+			Type:        "integer",
+			Description: "Minimum delay between requests in milliseconds, in addition to any robots.txt Crawl-delay (default: 0)",
+			Minimum:     float64Ptr(0),
+			Maximum:     float64Ptr(60000),
+		},
+		"summary_length": {
+			Type:        "integer",
+			Description: "Maximum characters in each page summary (default: 300)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(5000),
+		},
+		"timeout": {
+			Type:        "integer",
+			Description: "Timeout in seconds for each request (default: 30)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(300),
+		},
+		"max_tokens": {
+			Type:        "integer",
+			Description: "Split a page list longer than this many estimated tokens into chunks of whole pages and return one chunk",
+			Minimum:     float64Ptr(1),
+		},
+		"max_chars": {
+			Type:        "integer",
+			Description: "Split a page list longer than this many characters into chunks of whole pages and return one chunk",
+			Minimum:     float64Ptr(1),
+		},
+		"chunk": {
+			Type:        "integer",
+			Description: "Which chunk of a split page list to return; the same crawl runs again, so pass the same parameters otherwise (default: 1)",
+			Minimum:     float64Ptr(1),
+		},
+	},
+	Required: []string{"url"},
+}
+
+// NewCrawlSiteTool creates a bounded site crawling tool
+func NewCrawlSiteTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
+	return tools.NewTool(
+		"crawl_site",
+		"Crawls a web site from a start URL within depth and page limits, returning each page's title, summary and links",
+		cfg.crawlSiteHandler,
+		CrawlSiteParamSchema,
+	)
+}
+
+func (c *toolConfig) crawlSiteHandler(ctx context.Context, params CrawlSiteParams) (*CrawlSiteResult, error) {
+	// Set defaults
+	maxDepth := 2
+	if params.MaxDepth > 0 {
+		maxDepth = min(params.MaxDepth, 5)
+	}
+	maxPages := 20
+	if params.MaxPages > 0 {
+		maxPages = min(params.MaxPages, 200)
+	}
+	concurrency := 2
+	if params.Concurrency > 0 {
+		concurrency = min(params.Concurrency, 8)
+	}
+	summaryLength := 300
+	if params.SummaryLength > 0 {
+		summaryLength = params.SummaryLength
+	}
+	timeout := 30
+	if params.Timeout > 0 {
+		timeout = params.Timeout
+	}
+
+	scope := "host"
+	if params.Scope != "" {
+		scope = params.Scope
+	}
+	if scope != "host" && scope != "path" {
+		return nil, fmt.Errorf("invalid scope %q (must be 'host' or 'path')", params.Scope)
+	}
+
+	start, err := url.Parse(params.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing URL: %w", err)
+	}
+	if start.Scheme != "http" && start.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", start.Scheme)
+	}
+	start.Fragment = ""
+
+	window, err := c.contentWindow(params.MaxTokens, params.MaxChars, params.Chunk)
+	if err != nil {
+		return nil, err
+	}
+	include, err := compilePatterns("include", params.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compilePatterns("exclude", params.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	crawler := &siteCrawler{
+		config:        c,
+		client:        c.client(time.Duration(timeout) * time.Second),
+		start:         start,
+		pathPrefix:    start.Path[:strings.LastIndex(start.Path, "/")+1],
+		scope:         scope,
+		include:       include,
+		exclude:       exclude,
+		delay:         time.Duration(params.DelayMS) * time.Millisecond,
+		summaryLength: summaryLength,
+	}
+	crawler.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return c.checkRobots(req.Context(), crawler.client, req.URL)
+	}
+
+	result := &CrawlSiteResult{
+		URL:       params.URL,
+		Pages:     []CrawlPage{},
+		CrawledAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	// Breadth-first, one depth level at a time, so pages are reported in
+	// discovery order and the page budget goes to the shallowest pages
+	seen := map[string]bool{start.String(): true}
+	level := []string{start.String()}
+	for depth := 0; len(level) > 0 && len(result.Pages) < maxPages; depth++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if remaining := maxPages - len(result.Pages); len(level) > remaining {
+			level = level[:remaining]
+			result.Truncated = true
+		}
+		pages := crawler.fetchAll(ctx, level, depth, concurrency)
+		if depth == 0 {
+			if final := crawler.rescope(pages[0]); final != "" {
+				seen[final] = true
+			}
+		}
+
+		var next []string
+		for _, page := range pages {
+			result.Pages = append(result.Pages, page)
+			if page.Error != "" {
+				result.ErrorCount++
+			}
+			if depth == maxDepth {
+				continue
+			}
+			for _, link := range page.Links {
+				u, err := url.Parse(link)
+				if err != nil {
+					continue
+				}
+				u.Fragment = ""
+				key := u.String()
+				if seen[key] || !crawler.follow(u) {
+					continue
+				}
+				seen[key] = true
+				next = append(next, key)
+			}
+		}
+		level = next
+	}
+	if len(level) > 0 && len(result.Pages) >= maxPages {
+		result.Truncated = true
+	}
+
+	result.PageCount = len(result.Pages)
+	if err := chunkCrawlPages(result, window); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// chunkCrawlPages keeps the chunk of the crawled pages the window asks for,
+// measuring each page by its JSON encoding
+func chunkCrawlPages(result *CrawlSiteResult, window contentWindow) error {
+	sizes := make([]textSize, len(result.Pages))
+	for i, page := range result.Pages {
+		data, err := json.Marshal(page)
+		if err != nil {
+			return fmt.Errorf("encoding page: %w", err)
+		}
+		sizes[i] = measureText(string(data))
+	}
+	start, end, chunking, err := window.applyItems(sizes)
+	if err != nil {
+		return err
+	}
+	result.Pages = result.Pages[start:end]
+	result.Chunking = chunking
+	return nil
+}
+
+// compilePatterns compiles the include or exclude URL patterns
+func compilePatterns(param string, patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %w", param, p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// siteCrawler holds the state shared by the requests of one crawl
+type siteCrawler struct {
+	config        *toolConfig
+	client        *http.Client
+	start         *url.URL
+	pathPrefix    string
+	scope         string
+	include       []*regexp.Regexp
+	exclude       []*regexp.Regexp
+	delay         time.Duration
+	summaryLength int
+
+	mu          sync.Mutex
+	lastRequest time.Time
+}
+
+// rescope moves the scope to the URL the start page redirected to, so a site
+// that redirects to https or another host name is crawled where it ended up.
+// It returns the new start URL, or "" when the start page was not redirected.
+func (s *siteCrawler) rescope(page CrawlPage) string {
+	if page.FinalURL == "" {
+		return ""
+	}
+	final, err := url.Parse(page.FinalURL)
+	if err != nil {
+		return ""
+	}
+	final.Fragment = ""
+	s.start = final
+	s.pathPrefix = final.Path[:strings.LastIndex(final.Path, "/")+1]
+	return final.String()
+}
+
+// follow reports whether a discovered link is in scope and passes the
+// include and exclude patterns
+func (s *siteCrawler) follow(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	if !isInternalLink(s.start, u) {
+		return false
+	}
+	if s.scope == "path" && !strings.HasPrefix(u.Path, s.pathPrefix) {
+		return false
+	}
+
+	link := u.String()
+	for _, re := range s.exclude {
+		if re.MatchString(link) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, re := range s.include {
+		if re.MatchString(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchAll fetches one depth level with at most concurrency requests in
+// flight, returning pages in the order of urls
+func (s *siteCrawler) fetchAll(ctx context.Context, urls []string, depth, concurrency int) []CrawlPage {
+	pages := make([]CrawlPage, len(urls))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			pages[i] = s.fetch(ctx, u, depth)
+		}()
+	}
+	wg.Wait()
+	return pages
+}
+
+// wait spaces requests by the configured delay. Crawl-delay from robots.txt
+// is enforced separately by the robots checker.
+func (s *siteCrawler) wait(ctx context.Context) error {
+	if s.delay <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	next := s.lastRequest.Add(s.delay)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	s.lastRequest = next
+	s.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(next)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetch retrieves one page. Failures are recorded on the page rather than
+// aborting the crawl.
+func (s *siteCrawler) fetch(ctx context.Context, pageURL string, depth int) CrawlPage {
+	page := CrawlPage{URL: pageURL, Depth: depth, Links: []string{}}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		page.Error = fmt.Sprintf("creating request: %v", err)
+		return page
+	}
+	req.Header.Set("User-Agent", "go-flock/1.0 SiteCrawler")
+
+	if err := s.config.checkRobots(ctx, s.client, req.URL); err != nil {
+		page.Error = err.Error()
+		return page
+	}
+	if err := s.wait(ctx); err != nil {
+		page.Error = err.Error()
+		return page
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		var robotsErr *RobotsDisallowedError
+		if errors.As(err, &robotsErr) {
+			page.Error = robotsErr.Error()
+		} else {
+			page.Error = fmt.Sprintf("fetching page: %v", err)
+		}
+		return page
+	}
+	defer resp.Body.Close()
+
+	page.StatusCode = resp.StatusCode
+	page.ContentType = resp.Header.Get("Content-Type")
+	if final := resp.Request.URL.String(); final != pageURL {
+		page.FinalURL = final
+	}
+	if resp.StatusCode >= 400 {
+		page.Error = fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+		return page
+	}
+	if !strings.Contains(page.ContentType, "text/html") && !strings.Contains(page.ContentType, "application/xhtml+xml") {
+		return page
+	}
+
+	body, _, err := readBody(resp.Body, s.config.responseLimit(DefaultMaxResponseSize))
+	if err != nil {
+		page.Error = fmt.Sprintf("reading response: %v", err)
+		return page
+	}
+	content := decodeHTML(body, page.ContentType)
+	page.Title = extractTitle(content)
+
+	doc, err := parseHTML(content)
+	if err != nil {
+		page.Error = fmt.Sprintf("parsing HTML: %v", err)
+		return page
+	}
+	base := documentBaseURL(doc, resp.Request.URL)
+	page.Summary = pageSummary(doc, s.summaryLength)
+
+	// Same resolution as extract_links, deduplicated in page order
+	links, _ := findAnchorLinks(content, base)
+	seen := make(map[string]bool, len(links))
+	for _, link := range links {
+		if !seen[link.URL] {
+			seen[link.URL] = true
+			page.Links = append(page.Links, link.URL)
+		}
+	}
+
+	return page
+}
+
+// pageSummary returns the page's meta description or the start of its text,
+// cut at a word boundary
+func pageSummary(doc *html.Node, limit int) string {
+	summary := metaContent(doc, "description", "og:description")
+	if summary == "" {
+		if body := domFind(doc, func(n *html.Node) bool { return isTag(n, "body") }); body != nil {
+			summary = normalizeSpace(domBlockText(body))
+		}
+	}
+	return truncateText(summary, limit)
+}
+
+// truncateText shortens s to at most limit runes, breaking at a space where
+// possible and marking the cut with "..."
+func truncateText(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " "); i > limit/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "..."
+}
@@ -0,0 +1,305 @@
+// ABOUTME: Unit tests for the crawl_site tool
+// ABOUTME: Tests depth and page limits, scope, include/exclude patterns, robots rules and summaries
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newCrawlServer serves a small site:
+//
+//	/docs/ -> /docs/a, /docs/b, /blog/post, /private/x, /docs/file.pdf, external
+//	/docs/a -> /docs/a/deep, /docs/ (cycle)
+//	/docs/b -> 404
+func newCrawlServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+
+		page := func(title, body string) {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprintf(w, "<html><head><title>%s</title></head><body>%s</body></html>", title, body)
+		}
+
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private/\n")
+		case "/docs/":
+			page("Docs", `<p>Welcome to the documentation for the widget toolkit.</p>
+				<a href="a">A</a> <a href="/docs/b#top">B</a> <a href="/blog/post">Blog</a>
+				<a href="/private/x">Private</a> <a href="file.pdf">PDF</a>
+				<a href="https://elsewhere.example/">Elsewhere</a> <a href="mailto:docs@example.com">Mail</a>`)
+		case "/docs/a":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>A</title><meta name="description" content="Page A summary"></head>
+				<body><a href="/docs/a/deep">Deep</a><a href="/docs/">Back</a><a href="/docs/a">Self</a></body></html>`)
+		case "/docs/a/deep":
+			page("Deep", "<p>Deep page</p>")
+		case "/blog/post":
+			page("Post", "<p>Blog post</p>")
+		case "/docs/file.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			fmt.Fprint(w, "%PDF-1.4")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requested...)
+	}
+}
+
+func crawledURLs(result *CrawlSiteResult, prefix string) []string {
+	var urls []string
+	for _, p := range result.Pages {
+		urls = append(urls, strings.TrimPrefix(p.URL, prefix))
+	}
+	return urls
+}
+
+func TestCrawlSiteHandler(t *testing.T) {
+	t.Parallel()
+
+	server, requested := newCrawlServer(t)
+
+	result, err := newToolConfig().crawlSiteHandler(context.Background(), CrawlSiteParams{
+		URL:         server.URL + "/docs/",
+		Concurrency: 3,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := crawledURLs(result, server.URL)
+	want := []string{"/docs/", "/docs/a", "/docs/b", "/blog/post", "/private/x", "/docs/file.pdf", "/docs/a/deep"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Unexpected crawl order:\ngot:  %v\nwant: %v", got, want)
+	}
+	if result.PageCount != len(want) || result.Truncated {
+		t.Errorf("Expected %d pages and no truncation, got %d / %v", len(want), result.PageCount, result.Truncated)
+	}
+
+	byPath := make(map[string]CrawlPage)
+	for _, p := range result.Pages {
+		byPath[strings.TrimPrefix(p.URL, server.URL)] = p
+	}
+
+	root := byPath["/docs/"]
+	if root.Title != "Docs" || root.Depth != 0 || !strings.HasPrefix(root.Summary, "Welcome to the documentation") {
+		t.Errorf("Unexpected root page: %+v", root)
+	}
+	if len(root.Links) != 6 || root.Links[5] != "https://elsewhere.example/" {
+		t.Errorf("Expected 6 outgoing links including external, got %v", root.Links)
+	}
+	if byPath["/docs/a"].Summary != "Page A summary" {
+		t.Errorf("Expected meta description summary, got %q", byPath["/docs/a"].Summary)
+	}
+	if byPath["/docs/a/deep"].Depth != 2 {
+		t.Errorf("Expected depth 2, got %d", byPath["/docs/a/deep"].Depth)
+	}
+	if p := byPath["/docs/b"]; p.StatusCode != http.StatusNotFound || p.Error == "" {
+		t.Errorf("Expected 404 error for /docs/b, got %+v", p)
+	}
+	if p := byPath["/private/x"]; !strings.Contains(p.Error, "robots.txt") {
+		t.Errorf("Expected robots.txt error for /private/x, got %+v", p)
+	}
+	if p := byPath["/docs/file.pdf"]; p.ContentType != "application/pdf" || p.Error != "" || len(p.Links) != 0 {
+		t.Errorf("Expected non-HTML page without links, got %+v", p)
+	}
+	if result.ErrorCount != 2 {
+		t.Errorf("Expected 2 errors, got %d", result.ErrorCount)
+	}
+
+	for _, path := range requested() {
+		if path == "/private/x" {
+			t.Error("Expected disallowed page not to be requested")
+		}
+	}
+}
+
+func TestCrawlSiteHandler_Limits(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newCrawlServer(t)
+
+	tests := []struct {
+		name          string
+		params        CrawlSiteParams
+		want          []string
+		wantTruncated bool
+	}{
+		{
+			name:   "max depth",
+			params: CrawlSiteParams{MaxDepth: 1, Exclude: []string{`\.pdf$`, `/private/`}},
+			want:   []string{"/docs/", "/docs/a", "/docs/b", "/blog/post"},
+		},
+		{
+			name:          "max pages",
+			params:        CrawlSiteParams{MaxPages: 3},
+			want:          []string{"/docs/", "/docs/a", "/docs/b"},
+			wantTruncated: true,
+		},
+		{
+			name:   "path scope",
+			params: CrawlSiteParams{Scope: "path", Exclude: []string{`\.pdf$`}},
+			want:   []string{"/docs/", "/docs/a", "/docs/b", "/docs/a/deep"},
+		},
+		{
+			name:   "include",
+			params: CrawlSiteParams{Include: []string{`/docs/a`}},
+			want:   []string{"/docs/", "/docs/a", "/docs/a/deep"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			params.URL = server.URL + "/docs/"
+			result, err := newToolConfig().crawlSiteHandler(context.Background(), params)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := crawledURLs(result, server.URL); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Unexpected pages:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+			if result.Truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated=%v, got %v", tt.wantTruncated, result.Truncated)
+			}
+		})
+	}
+}
+
+func TestCrawlSiteHandler_RedirectedStart(t *testing.T) {
+	t.Parallel()
+
+	// The site moves from localhost to 127.0.0.1, as sites move to https or
+	// a www host
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/robots.txt":
+			http.NotFound(w, r)
+		case strings.HasPrefix(r.Host, "localhost:"):
+			http.Redirect(w, r, server.URL+"/site/home", http.StatusMovedPermanently)
+		case r.URL.Path == "/site/home":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body><a href="about">About</a> <a href="home">Home</a> <a href="/other">Other</a></body></html>`)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body><p>Page</p></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	start := strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/"
+	result, err := newToolConfig().crawlSiteHandler(context.Background(), CrawlSiteParams{URL: start, Scope: "path"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := crawledURLs(result, server.URL); strings.Join(got, " ") != start+" /site/about" {
+		t.Errorf("Expected the crawl to follow links under the redirected start URL, got %v", got)
+	}
+	if result.Pages[0].FinalURL != server.URL+"/site/home" {
+		t.Errorf("Expected the start page's final URL, got %q", result.Pages[0].FinalURL)
+	}
+}
+
+func TestCrawlSiteHandler_Chunking(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newCrawlServer(t)
+	params := CrawlSiteParams{URL: server.URL + "/docs/", MaxChars: 600}
+
+	var got []string
+	for chunk := 1; ; chunk++ {
+		params.Chunk = chunk
+		result, err := newToolConfig().crawlSiteHandler(context.Background(), params)
+		if err != nil {
+			t.Fatalf("Chunk %d: unexpected error: %v", chunk, err)
+		}
+		if result.Chunking == nil || result.Chunking.Chunk != chunk || result.PageCount != 7 || result.ErrorCount != 2 {
+			t.Fatalf("Chunk %d: unexpected result %+v", chunk, result)
+		}
+		data, _ := json.Marshal(result.Pages)
+		if len(result.Pages) > 1 && len(data) > params.MaxChars {
+			t.Errorf("Chunk %d holds %d pages in %d characters", chunk, len(result.Pages), len(data))
+		}
+		got = append(got, crawledURLs(result, server.URL)...)
+		if chunk == result.Chunking.TotalChunks {
+			break
+		}
+	}
+	want := []string{"/docs/", "/docs/a", "/docs/b", "/blog/post", "/private/x", "/docs/file.pdf", "/docs/a/deep"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected the chunks to hold every page once:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	params.Chunk = 99
+	if _, err := newToolConfig().crawlSiteHandler(context.Background(), params); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("Expected an out of range error, got %v", err)
+	}
+
+	// The budget derived from a model's context window applies by default
+	catalog, err := ParseModelCatalog([]byte(`[{"name": "tiny", "context_window": 800}]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := newToolConfig(WithModelBudget(catalog, "tiny")).crawlSiteHandler(context.Background(), CrawlSiteParams{URL: server.URL + "/docs/"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Chunking == nil || result.Chunking.TotalChunks < 2 || len(result.Pages) == 7 {
+		t.Errorf("Expected the model budget to split the page list, got %+v", result.Chunking)
+	}
+}
+
+func TestCrawlSiteHandler_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		params CrawlSiteParams
+		errMsg string
+	}{
+		{name: "bad scope", params: CrawlSiteParams{URL: "https://example.com/", Scope: "domain"}, errMsg: "invalid scope"},
+		{name: "bad scheme", params: CrawlSiteParams{URL: "ftp://example.com/"}, errMsg: "unsupported URL scheme"},
+		{name: "bad pattern", params: CrawlSiteParams{URL: "https://example.com/", Exclude: []string{"("}}, errMsg: "invalid exclude pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newToolConfig().crawlSiteHandler(context.Background(), tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	t.Parallel()
+
+	if got := truncateText("short", 10); got != "short" {
+		t.Errorf("Expected unchanged text, got %q", got)
+	}
+	if got := truncateText("the quick brown fox jumps", 12); got != "the quick..." {
+		t.Errorf("Expected word-boundary cut, got %q", got)
+	}
+}
@@ -0,0 +1,586 @@
+// ABOUTME: HTML form parsing and submission tool
+// ABOUTME: Fills form fields, submits GET or POST (urlencoded and multipart) and returns the resulting page
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
+	"github.com/lexlapax/go-llms/pkg/agent/tools"
+	sdomain "github.com/lexlapax/go-llms/pkg/schema/domain"
+	"golang.org/x/net/html"
+)
+
+// Submit Form Tool
+
+type SubmitFormParams struct {
+	URL          string            `json:"url" description:"The URL of the page containing the form"`
+	FormSelector string            `json:"form_selector,omitempty" description:"CSS selector for the form to submit (default: first form on the page)"`
+	Values       map[string]string `json:"values,omitempty" description:"Field values to fill in, keyed by field name"`
+	SubmitButton string            `json:"submit_button,omitempty" description:"Name or label of the submit button to press (default: the first one)"`
+	DryRun       bool              `json:"dry_run,omitempty" description:"Only describe the forms on the page without submitting"`
+	ExtractText  bool              `json:"extract_text,omitempty" description:"Extract only text content from the result page (default: true)"`
+	ExtractMode  string            `json:"extract_mode,omitempty" description:"Content to extract from the result page: full or article (default: full)"`
+	OutputFormat string            `json:"output_format,omitempty" description:"Format of the result page content: text or markdown (default: text)"`
+	MaxTokens    int               `json:"max_tokens,omitempty" description:"Split result page content longer than this many estimated tokens into chunks"`
+	MaxChars     int               `json:"max_chars,omitempty" description:"Split result page content longer than this many characters into chunks"`
+	Chunk        int               `json:"chunk,omitempty" description:"Which chunk of split content to return (default: 1)"`
+	Session      string            `json:"session,omitempty" description:"Session whose cookies and default headers to use (default: the run's default session)"`
+	Timeout      int               `json:"timeout,omitempty" description:"Timeout in seconds (default: 30)"`
+}
+
+type SubmitFormResult struct {
+	URL          string              `json:"url"`
+	Forms        []FormInfo          `json:"forms,omitempty"` // Set in dry runs
+	Form         *FormInfo           `json:"form,omitempty"`  // The submitted form
+	Method       string              `json:"method,omitempty"`
+	SubmittedURL string              `json:"submitted_url,omitempty"`
+	Page         *FetchWebPageResult `json:"page,omitempty"` // The response, processed as by fetch_webpage
+	SubmittedAt  string              `json:"submitted_at"`
+}
+
+type FormInfo struct {
+	Index   int         `json:"index"` // 1-based position among matching forms
+	ID      string      `json:"id,omitempty"`
+	Name    string      `json:"name,omitempty"`
+	Action  string      `json:"action"`
+	Method  string      `json:"method"`
+	Enctype string      `json:"enctype"`
+	Fields  []FormField `json:"fields"`
+
+	base *url.URL // Base URL of the page, which formaction is resolved against
+	page *url.URL // URL the page was served from, the target of an empty action
+}
+
+type FormField struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"` // input type, "select" or "textarea"
+	Value    string   `json:"value,omitempty"`
+	Label    string   `json:"label,omitempty"`
+	Options  []string `json:"options,omitempty"` // Values of select options
+	Checked  bool     `json:"checked,omitempty"` // For checkboxes and radio buttons
+	Required bool     `json:"required,omitempty"`
+
+	node *html.Node
+}
+
+var SubmitFormParamSchema = &sdomain.Schema{
+	Type:        "object",
+	Description: "Parameters for filling in and submitting a form on a web page",
+	Properties: map[string]sdomain.Property{
+		"url": {
+			Type:        "string",
+			Description: "The URL of the page containing the form",
+		},
+		"form_selector": {
+			Type:        "string",
+			Description: "CSS selector for the form to submit, e.g. 'form#search' (default: first form on the page)",
+		},
+		"values": {
+			Type:                 "object",
+			Description:          "Field values to fill in, keyed by field name. Hidden fields such as CSRF tokens are kept unless overridden; for radio buttons and checkboxes give the value to select",
+			AdditionalProperties: func() *bool { b := true; return &b }(),
+		},
+		"submit_button": {
+			Type:        "string",
+			Description: "Name, value or label of the submit button to press (default: the first submit button)",
+		},
+		"dry_run": {
+			Type:        "boolean",
+			Description: "Only describe the forms on the page, with their fields and current values, without submitting",
+		},
+		"extract_text": {
+			Type:        "boolean",
+			Description: "Extract only text content from the result page (default: true)",
+		},
+		"extract_mode": {
+			Type:        "string",
+			Description: "Content to extract from the result page: 'full' or 'article' (default: full)",
+			Enum:        []string{"full", "article"},
+		},
+		"output_format": {
+			Type:        "string",
+			Description: "Format of the result page content: 'text' or 'markdown' (default: text)",
+			Enum:        []string{"text", "markdown"},
+		},
+		"max_tokens": {
+			Type:        "integer",
+			Description: "Split result page content longer than this many estimated tokens into chunks and return one chunk",
+			Minimum:     float64Ptr(1),
+		},
+		"max_chars": {
+			Type:        "integer",
+			Description: "Split result page content longer than this many characters into chunks and return one chunk",
+			Minimum:     float64Ptr(1),
+		},
+		"chunk": {
+			Type:        "integer",
+			Description: "Which chunk of split content to return; see page.chunking.total_chunks in the result (default: 1)",
+			Minimum:     float64Ptr(1),
+		},
+		"session": {
+			Type:        "string",
+			Description: "Name of the session whose cookies and default headers carry over between calls, e.g. to stay logged in after submitting a login form (default: the run's default session)",
+		},
+		"timeout": {
+			Type:        "integer",
+			Description: "Timeout in seconds for each request (default: 30)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(300),
+		},
+	},
+	Required: []string{"url"},
+}
+
+// NewSubmitFormTool creates a tool that fills in and submits web forms
+func NewSubmitFormTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
+	return tools.NewTool(
+		"submit_form",
+		"Fills in and submits a form on a web page, such as a search form, and returns the resulting page",
+		cfg.submitFormHandler,
+		SubmitFormParamSchema,
+	)
+}
+
+func (c *toolConfig) submitFormHandler(ctx context.Context, params SubmitFormParams) (*SubmitFormResult, error) {
+	extraction, err := newPageExtraction(params.ExtractText, params.ExtractMode, params.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+	if extraction.window, err = c.contentWindow(params.MaxTokens, params.MaxChars, params.Chunk); err != nil {
+		return nil, err
+	}
+
+	formSelector := "form"
+	if params.FormSelector != "" {
+		formSelector = params.FormSelector
+	}
+	sel, err := cascadia.Compile(formSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid form_selector %q: %w", formSelector, err)
+	}
+
+	timeout := 30
+	if params.Timeout > 0 {
+		timeout = params.Timeout
+	}
+
+	// Session cookies set by the form page usually have to accompany the
+	// submission, so without a session the call gets a jar of its own
+	session, err := c.session(ctx, params.Session)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		session = NewSession("")
+	}
+	client := c.client(time.Duration(timeout) * time.Second)
+	session.attach(client)
+
+	userAgent := "go-flock/1.0 FormSubmitter"
+	doc, pageURL, err := c.fetchHTMLDocument(ctx, client, session, params.URL, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	var forms []*html.Node
+	for _, n := range sel.MatchAll(doc) {
+		if isTag(n, "form") {
+			forms = append(forms, n)
+		}
+	}
+	if len(forms) == 0 {
+		return nil, fmt.Errorf("no form matching %q found", formSelector)
+	}
+
+	result := &SubmitFormResult{
+		URL:         params.URL,
+		SubmittedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if params.DryRun {
+		for i, form := range forms {
+			info := parseForm(doc, form, pageURL)
+			info.Index = i + 1
+			result.Forms = append(result.Forms, info)
+		}
+		return result, nil
+	}
+
+	form := parseForm(doc, forms[0], pageURL)
+	form.Index = 1
+	result.Form = &form
+
+	submission, err := form.submission(params.Values, params.SubmitButton)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := submission.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	session.applyHeaders(req)
+	req.Header.Set("Referer", params.URL)
+	if req.Method == "POST" {
+		// Never answer a POST from a response cache
+		req.Header.Set("Cache-Control", "no-cache")
+	}
+	result.Method = req.Method
+	result.SubmittedURL = req.URL.String()
+
+	if err := c.checkRobots(ctx, client, req.URL); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("submitting form: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkContentType(resp); err != nil {
+		return nil, err
+	}
+
+	body, truncated, err := readBody(resp.Body, c.responseLimit(DefaultMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if result.Page, err = extraction.result(resp, body); err != nil {
+		return nil, err
+	}
+	result.Page.Truncated = truncated
+
+	return result, nil
+}
+
+// submitInputTypes are the input types that submit a form when pressed
+var submitInputTypes = map[string]bool{"submit": true, "image": true}
+
+// parseForm describes a form and its successful-control candidates in tree
+// order, including controls outside the form that reference it by id
+func parseForm(doc, form *html.Node, pageURL *url.URL) FormInfo {
+	info := FormInfo{
+		ID:      domAttr(form, "id"),
+		Name:    domAttr(form, "name"),
+		Method:  formMethod(domAttr(form, "method")),
+		Enctype: formEnctype(domAttr(form, "enctype")),
+		Fields:  []FormField{},
+		base:    documentBaseURL(doc, pageURL),
+		page:    pageURL,
+	}
+	info.Action = formURL(info.base, info.page, domAttr(form, "action"))
+
+	labels := formLabels(doc)
+	owned := func(n *html.Node) bool {
+		if owner := domAttr(n, "form"); owner != "" {
+			return info.ID != "" && owner == info.ID
+		}
+		return closestAncestor(n, "form") == form
+	}
+
+	controls := domFindAll(doc, func(n *html.Node) bool { return isTag(n, "input", "select", "textarea", "button") })
+	for _, n := range controls {
+		if !owned(n) || domHasAttr(n, "disabled") {
+			continue
+		}
+		if fieldset := closestAncestor(n, "fieldset"); fieldset != nil && domHasAttr(fieldset, "disabled") {
+			continue
+		}
+
+		field := FormField{
+			Name:     domAttr(n, "name"),
+			Label:    labels[domAttr(n, "id")],
+			Required: domHasAttr(n, "required"),
+			node:     n,
+		}
+		if field.Label == "" {
+			if label := closestAncestor(n, "label"); label != nil {
+				field.Label = domText(label)
+			}
+		}
+
+		switch n.Data {
+		case "input":
+			field.Type = strings.ToLower(domAttr(n, "type"))
+			if field.Type == "" {
+				field.Type = "text"
+			}
+			field.Value = domAttr(n, "value")
+			switch field.Type {
+			case "checkbox", "radio":
+				field.Checked = domHasAttr(n, "checked")
+				if !domHasAttr(n, "value") {
+					field.Value = "on"
+				}
+			case "reset", "button":
+				continue
+			}
+		case "button":
+			field.Type = strings.ToLower(domAttr(n, "type"))
+			if field.Type == "" {
+				field.Type = "submit"
+			}
+			if field.Type != "submit" {
+				continue
+			}
+			field.Value = domAttr(n, "value")
+			if field.Label == "" {
+				field.Label = domText(n)
+			}
+		case "select":
+			field.Type = "select"
+			field.Value, field.Options = selectValue(n)
+		case "textarea":
+			field.Type = "textarea"
+			field.Value = domRawText(n)
+		}
+
+		// Unnamed controls are never submitted, but unnamed submit buttons can
+		// still be pressed
+		if field.Name == "" && !submitInputTypes[field.Type] {
+			continue
+		}
+		info.Fields = append(info.Fields, field)
+	}
+
+	return info
+}
+
+// selectValue returns the selected value of a <select>, defaulting to its
+// first option, and the values of all its options
+func selectValue(sel *html.Node) (string, []string) {
+	var value string
+	var options []string
+	selected := false
+	for _, opt := range domFindAll(sel, func(n *html.Node) bool { return isTag(n, "option") }) {
+		v := domAttr(opt, "value")
+		if !domHasAttr(opt, "value") {
+			v = domText(opt)
+		}
+		options = append(options, v)
+		if (domHasAttr(opt, "selected") && !selected) || len(options) == 1 {
+			value = v
+			selected = selected || domHasAttr(opt, "selected")
+		}
+	}
+	return value, options
+}
+
+// formLabels maps element ids to the text of their <label for=...>
+func formLabels(doc *html.Node) map[string]string {
+	labels := make(map[string]string)
+	for _, label := range domFindAll(doc, func(n *html.Node) bool { return isTag(n, "label") && domAttr(n, "for") != "" }) {
+		labels[domAttr(label, "for")] = domText(label)
+	}
+	return labels
+}
+
+// formSubmission is a form data set ready to be encoded
+type formSubmission struct {
+	action  string
+	method  string
+	enctype string
+	fields  []formValue // in tree order
+}
+
+// formValue is an entry of a form data set
+type formValue struct {
+	name  string
+	value string
+	file  bool // sent as an empty file part in multipart forms
+}
+
+// submission builds the form data set: supplied values replace the form's
+// own, unchecked checkboxes and radio buttons are left out, and the pressed
+// submit button contributes its name and value. Unknown field names are an
+// error so typos do not go unnoticed.
+func (f FormInfo) submission(values map[string]string, button string) (*formSubmission, error) {
+	s := &formSubmission{action: f.Action, method: f.Method, enctype: f.Enctype}
+
+	known := make(map[string]bool)
+	for _, field := range f.Fields {
+		if !submitInputTypes[field.Type] {
+			known[field.Name] = true
+		}
+	}
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("form has no fields named %s", strings.Join(unknown, ", "))
+	}
+
+	pressed, err := f.submitButton(button)
+	if err != nil {
+		return nil, err
+	}
+
+	filled := make(map[string]bool)
+	for _, field := range f.Fields {
+		if submitInputTypes[field.Type] {
+			if pressed != nil && field.node == pressed.node && field.Name != "" {
+				if field.Type == "image" {
+					s.fields = append(s.fields, formValue{name: field.Name + ".x", value: "0"}, formValue{name: field.Name + ".y", value: "0"})
+				} else {
+					s.fields = append(s.fields, formValue{name: field.Name, value: field.Value})
+				}
+			}
+			continue
+		}
+
+		if value, ok := values[field.Name]; ok {
+			if !filled[field.Name] {
+				filled[field.Name] = true
+				s.fields = append(s.fields, formValue{name: field.Name, value: value})
+			}
+			continue
+		}
+		if (field.Type == "checkbox" || field.Type == "radio") && !field.Checked {
+			continue
+		}
+		if field.Type == "file" {
+			// File uploads are not supported; submit as if no file was chosen
+			s.fields = append(s.fields, formValue{name: field.Name, file: true})
+			continue
+		}
+		s.fields = append(s.fields, formValue{name: field.Name, value: field.Value})
+	}
+
+	// The pressed button may override where and how the form is sent
+	if pressed != nil {
+		if action := domAttr(pressed.node, "formaction"); action != "" && f.base != nil {
+			s.action = formURL(f.base, f.page, action)
+		}
+		if method := domAttr(pressed.node, "formmethod"); method != "" {
+			s.method = formMethod(method)
+		}
+		if enctype := domAttr(pressed.node, "formenctype"); enctype != "" {
+			s.enctype = formEnctype(enctype)
+		}
+	}
+
+	return s, nil
+}
+
+// submitButton finds the button to press by name, value or label, or the
+// first submit button when no name is given. Forms without buttons are
+// submitted as if Enter was pressed.
+func (f FormInfo) submitButton(button string) (*FormField, error) {
+	for i, field := range f.Fields {
+		if !submitInputTypes[field.Type] {
+			continue
+		}
+		if button == "" || field.Name == button || field.Value == button || strings.EqualFold(field.Label, button) {
+			return &f.Fields[i], nil
+		}
+	}
+	if button != "" {
+		return nil, fmt.Errorf("form has no submit button %q", button)
+	}
+	return nil, nil
+}
+
+// request encodes the submission as a GET query or a POST body
+func (s *formSubmission) request(ctx context.Context) (*http.Request, error) {
+	if s.method == "GET" {
+		target, err := url.Parse(s.action)
+		if err != nil {
+			return nil, fmt.Errorf("parsing form action: %w", err)
+		}
+		target.RawQuery = encodeFormFields(s.fields)
+		req, err := http.NewRequestWithContext(ctx, "GET", target.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		return req, nil
+	}
+
+	var body bytes.Buffer
+	contentType := "application/x-www-form-urlencoded"
+	if s.enctype == "multipart/form-data" {
+		w := multipart.NewWriter(&body)
+		for _, field := range s.fields {
+			var err error
+			if field.file {
+				_, err = w.CreateFormFile(field.name, "")
+			} else {
+				err = w.WriteField(field.name, field.value)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("encoding form: %w", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("encoding form: %w", err)
+		}
+		contentType = w.FormDataContentType()
+	} else {
+		body.WriteString(encodeFormFields(s.fields))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.action, &body)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
+
+// encodeFormFields URL-encodes name/value pairs keeping their order, which
+// url.Values would lose
+func encodeFormFields(fields []formValue) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, url.QueryEscape(field.name)+"="+url.QueryEscape(field.value))
+	}
+	return strings.Join(parts, "&")
+}
+
+// formURL resolves a form action against the base URL; an empty action
+// submits to the page itself, whatever <base href> says
+func formURL(base, page *url.URL, action string) string {
+	action = strings.TrimSpace(action)
+	if action != "" {
+		if u, err := resolveURL(base, action); err == nil {
+			return u.String()
+		}
+	}
+	u := *page
+	u.Fragment, u.RawFragment = "", ""
+	return u.String()
+}
+
+// formMethod normalizes a method attribute; only GET and POST are valid
+func formMethod(method string) string {
+	if strings.EqualFold(strings.TrimSpace(method), "post") {
+		return "POST"
+	}
+	return "GET"
+}
+
+// formEnctype normalizes an enctype attribute
+func formEnctype(enctype string) string {
+	if strings.EqualFold(strings.TrimSpace(enctype), "multipart/form-data") {
+		return "multipart/form-data"
+	}
+	return "application/x-www-form-urlencoded"
+}
@@ -0,0 +1,284 @@
+// ABOUTME: Unit tests for the submit_form tool
+// ABOUTME: Tests form parsing, value filling, GET/POST/multipart encoding, cookies and result extraction
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+const sampleFormsHTML = `<html><body>
+<form id="login" action="/login" method="post"><input name="user"></form>
+<form id="search" action="/search" method="get">
+	<input type="hidden" name="csrf" value="tok123">
+	<label for="q">Search terms</label><input id="q" name="q" value="default" required>
+	<select name="field"><option value="all">All</option><option value="title" selected>Title</option><option>Author</option></select>
+	<input type="checkbox" name="exact" value="1">
+	<input type="checkbox" name="online" checked>
+	<input type="radio" name="sort" value="relevance" checked>
+	<input type="radio" name="sort" value="date">
+	<textarea name="notes">keep
+this</textarea>
+	<input name="disabled" value="x" disabled>
+	<input type="reset" name="reset">
+	<input type="submit" name="go" value="Search">
+	<button type="submit" name="action" value="advanced" formmethod="post" formaction="/advanced">Advanced search</button>
+</form>
+<input name="year" form="search" value="2024">
+</body></html>`
+
+func TestParseForm(t *testing.T) {
+	t.Parallel()
+
+	doc, err := parseHTML(sampleFormsHTML)
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	base, _ := url.Parse("https://library.example.org/catalog/")
+	form := domFind(doc, func(n *html.Node) bool { return isTag(n, "form") && domAttr(n, "id") == "search" })
+
+	info := parseForm(doc, form, base)
+
+	if info.Action != "https://library.example.org/search" || info.Method != "GET" {
+		t.Errorf("Unexpected action/method: %s %s", info.Method, info.Action)
+	}
+
+	var names []string
+	for _, f := range info.Fields {
+		names = append(names, f.Name)
+	}
+	wantNames := []string{"csrf", "q", "field", "exact", "online", "sort", "sort", "notes", "go", "action", "year"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("Unexpected fields: got %v, want %v", names, wantNames)
+	}
+
+	q := info.Fields[1]
+	if q.Label != "Search terms" || !q.Required || q.Value != "default" {
+		t.Errorf("Unexpected q field: %+v", q)
+	}
+	sel := info.Fields[2]
+	if sel.Value != "title" || !reflect.DeepEqual(sel.Options, []string{"all", "title", "Author"}) {
+		t.Errorf("Unexpected select field: %+v", sel)
+	}
+	if info.Fields[4].Value != "on" || !info.Fields[4].Checked {
+		t.Errorf("Expected checked checkbox with default value 'on', got %+v", info.Fields[4])
+	}
+}
+
+func TestFormSubmission(t *testing.T) {
+	t.Parallel()
+
+	doc, _ := parseHTML(sampleFormsHTML)
+	base, _ := url.Parse("https://library.example.org/catalog/")
+	form := parseForm(doc, domFind(doc, func(n *html.Node) bool { return isTag(n, "form") && domAttr(n, "id") == "search" }), base)
+
+	t.Run("default button", func(t *testing.T) {
+		s, err := form.submission(map[string]string{"q": "rivers", "sort": "date", "exact": "1"}, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := "csrf=tok123&q=rivers&field=title&exact=1&online=on&sort=date&notes=keep%0Athis&go=Search&year=2024"
+		if got := encodeFormFields(s.fields); got != want {
+			t.Errorf("Unexpected form data:\ngot:  %s\nwant: %s", got, want)
+		}
+		if s.method != "GET" || s.action != "https://library.example.org/search" {
+			t.Errorf("Unexpected method/action: %s %s", s.method, s.action)
+		}
+	})
+
+	t.Run("button overrides", func(t *testing.T) {
+		s, err := form.submission(nil, "Advanced search")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if s.method != "POST" || s.action != "https://library.example.org/advanced" {
+			t.Errorf("Expected button formmethod/formaction, got %s %s", s.method, s.action)
+		}
+		if got := encodeFormFields(s.fields); !strings.Contains(got, "action=advanced") || strings.Contains(got, "go=Search") {
+			t.Errorf("Expected only the pressed button to be submitted, got %s", got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := form.submission(map[string]string{"query": "x", "disabled": "y"}, ""); err == nil || !strings.Contains(err.Error(), "disabled, query") {
+			t.Errorf("Expected unknown field error, got %v", err)
+		}
+		if _, err := form.submission(nil, "missing"); err == nil || !strings.Contains(err.Error(), `no submit button "missing"`) {
+			t.Errorf("Expected unknown button error, got %v", err)
+		}
+	})
+
+	t.Run("relative formaction", func(t *testing.T) {
+		// The form posts to another directory; formaction is still relative
+		// to the page
+		doc, _ := parseHTML(`<form action="/search/run">
+	<button name="a" formaction="alt">Alt</button>
+	<button name="b" formaction="?x=1">Query</button>
+</form>`)
+		page, _ := url.Parse("https://library.example.org/catalog/books")
+		form := parseForm(doc, domFind(doc, func(n *html.Node) bool { return isTag(n, "form") }), page)
+
+		for button, want := range map[string]string{
+			"a": "https://library.example.org/catalog/alt",
+			"b": "https://library.example.org/catalog/books?x=1",
+		} {
+			s, err := form.submission(nil, button)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if s.action != want {
+				t.Errorf("Button %s: expected action %s, got %s", button, want, s.action)
+			}
+		}
+	})
+
+	t.Run("empty action ignores base href", func(t *testing.T) {
+		doc, _ := parseHTML(`<html><head><base href="https://cdn.example.net/assets/"></head><body>
+	<form method="post"><input name="q"><button name="go" formaction="next">Next</button></form>
+</body></html>`)
+		page, _ := url.Parse("https://library.example.org/catalog/books?page=2#results")
+		form := parseForm(doc, domFind(doc, func(n *html.Node) bool { return isTag(n, "form") }), page)
+
+		if want := "https://library.example.org/catalog/books?page=2"; form.Action != want {
+			t.Errorf("Expected an empty action to submit to %s, got %s", want, form.Action)
+		}
+		s, err := form.submission(nil, "Next")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := "https://cdn.example.net/assets/next"; s.action != want {
+			t.Errorf("Expected formaction resolved against the base, got %s", s.action)
+		}
+	})
+}
+
+func TestSubmitFormHandler(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.NotFound(w, r)
+		case "/catalog":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body>
+				<form action="/results" method="get"><input name="q"><input type="hidden" name="lang" value="en"></form>
+				<form id="upload" action="/submit" method="post" enctype="multipart/form-data">
+					<input name="title"><input type="file" name="attachment"><button>Send</button>
+				</form>
+				<form id="plain" action="/submit" method="post"><input name="title"></form>
+			</body></html>`)
+		case "/results":
+			if c, err := r.Cookie("session"); err != nil || c.Value != "abc" {
+				http.Error(w, "missing session", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprintf(w, `<html><head><title>Results</title></head><body><h1>Results for %s (%s)</h1></body></html>`, r.URL.Query().Get("q"), r.URL.Query().Get("lang"))
+		case "/submit":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				if err := r.ParseForm(); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprintf(w, "%s %s title=%s", r.Method, strings.Split(r.Header.Get("Content-Type"), ";")[0], r.FormValue("title"))
+			if r.MultipartForm != nil {
+				// Go reports file parts without a filename as values
+				fmt.Fprintf(w, " attachment=%d", len(r.MultipartForm.Value["attachment"])+len(r.MultipartForm.File["attachment"]))
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("get with session cookie", func(t *testing.T) {
+		result, err := newToolConfig().submitFormHandler(context.Background(), SubmitFormParams{
+			URL:          server.URL + "/catalog",
+			Values:       map[string]string{"q": "river maps"},
+			ExtractText:  true,
+			OutputFormat: "markdown",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Method != "GET" || result.SubmittedURL != server.URL+"/results?q=river+maps&lang=en" {
+			t.Errorf("Unexpected submission: %s %s", result.Method, result.SubmittedURL)
+		}
+		if result.Page == nil || result.Page.StatusCode != http.StatusOK {
+			t.Fatalf("Expected result page, got %+v", result.Page)
+		}
+		if result.Page.Title != "Results" || !strings.Contains(result.Page.Content, "# Results for river maps (en)") {
+			t.Errorf("Unexpected result page: %q / %q", result.Page.Title, result.Page.Content)
+		}
+		if result.Page.URL != result.SubmittedURL {
+			t.Errorf("Expected page URL %q, got %q", result.SubmittedURL, result.Page.URL)
+		}
+	})
+
+	t.Run("multipart post", func(t *testing.T) {
+		result, err := newToolConfig().submitFormHandler(context.Background(), SubmitFormParams{
+			URL:          server.URL + "/catalog",
+			FormSelector: "#upload",
+			Values:       map[string]string{"title": "Report"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := "POST multipart/form-data title=Report attachment=1"; result.Page.Content != want {
+			t.Errorf("Expected %q, got %q", want, result.Page.Content)
+		}
+	})
+
+	t.Run("urlencoded post", func(t *testing.T) {
+		result, err := newToolConfig().submitFormHandler(context.Background(), SubmitFormParams{
+			URL:          server.URL + "/catalog",
+			FormSelector: "form#plain",
+			Values:       map[string]string{"title": "a&b"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := "POST application/x-www-form-urlencoded title=a&b"; result.Page.Content != want {
+			t.Errorf("Expected %q, got %q", want, result.Page.Content)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		result, err := newToolConfig().submitFormHandler(context.Background(), SubmitFormParams{
+			URL:    server.URL + "/catalog",
+			DryRun: true,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Forms) != 3 || result.Page != nil || result.Form != nil {
+			t.Fatalf("Expected 3 described forms and no submission, got %+v", result)
+		}
+		if result.Forms[1].Enctype != "multipart/form-data" || result.Forms[1].Index != 2 {
+			t.Errorf("Unexpected second form: %+v", result.Forms[1])
+		}
+	})
+
+	t.Run("no matching form", func(t *testing.T) {
+		_, err := newToolConfig().submitFormHandler(context.Background(), SubmitFormParams{
+			URL:          server.URL + "/catalog",
+			FormSelector: "#missing",
+		})
+		if err == nil || !strings.Contains(err.Error(), `no form matching "#missing"`) {
+			t.Errorf("Expected no form error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,375 @@
+// ABOUTME: Batch link checker tool for validating many URLs at once
+// ABOUTME: Checks concurrently with per-host limits, falls back from HEAD to ranged GET and detects soft 404s
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
+	"github.com/lexlapax/go-llms/pkg/agent/tools"
+	sdomain "github.com/lexlapax/go-llms/pkg/schema/domain"
+	"golang.org/x/net/html"
+)
+
+// Check URL Statuses Tool
+
+// soft404PeekSize is how much of a page is read to look for a soft 404
+const soft404PeekSize = 16 * 1024
+
+type CheckURLStatusesParams struct {
+	URLs         []string          `json:"urls" description:"The URLs to check"`
+	Concurrency  int               `json:"concurrency,omitempty" description:"Maximum parallel requests (default: 8, max: 32)"`
+	PerHostLimit int               `json:"per_host_limit,omitempty" description:"Maximum parallel requests to one host (default: 2, max: 8)"`
+	Timeout      int               `json:"timeout,omitempty" description:"Timeout in seconds for each URL (default: 10)"`
+	Headers      map[string]string `json:"headers,omitempty" description:"Additional HTTP headers"`
+}
+
+type CheckURLStatusesResult struct {
+	Results   []URLStatus    `json:"results"` // One per distinct URL, in input order
+	Summary   URLStatusCount `json:"summary"`
+	CheckedAt string         `json:"checked_at"`
+}
+
+type URLStatus struct {
+	URL          string `json:"url"`
+	Status       string `json:"status"` // "alive", "dead", "redirect"
+	StatusCode   int    `json:"status_code,omitempty"`
+	Method       string `json:"method,omitempty"` // Method of the deciding request, HEAD or GET
+	FinalURL     string `json:"final_url,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	ResponseTime int64  `json:"response_time_ms"`
+	Soft404      bool   `json:"soft_404,omitempty"` // Answered with success but looks like a missing page
+	Reason       string `json:"reason,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+type URLStatusCount struct {
+	Total    int `json:"total"`
+	Alive    int `json:"alive"`
+	Dead     int `json:"dead"`
+	Redirect int `json:"redirect"`
+	Soft404  int `json:"soft_404"` // Included in dead
+	Errors   int `json:"errors"`   // Network failures, included in dead
+}
+
+var CheckURLStatusesParamSchema = &sdomain.Schema{
+	Type:        "object",
+	Description: "Parameters for checking the status of many URLs",
+	Properties: map[string]sdomain.Property{
+		"urls": {
+			Type:        "array",
+			Description: "The URLs to check; duplicates are checked once",
+			Items:       &sdomain.Property{Type: "string"},
+		},
+		"concurrency": {
+			Type:        "integer",
+			Description: "Maximum parallel requests (default: 8)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(32),
+		},
+		"per_host_limit": {
+			Type:        "integer",
+			Description: "Maximum parallel requests to one host (default: 2)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(8),
+		},
+		"timeout": {
+			Type:        "integer",
+			Description: "Timeout in seconds for each URL (default: 10)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(300),
+		},
+		"headers": {
+			Type:                 "object",
+			Description:          "Additional HTTP headers",
+			AdditionalProperties: func() *bool { b := true; return &b }(),
+		},
+	},
+	Required: []string{"urls"},
+}
+
+func NewCheckURLStatusesTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
+	return tools.NewTool(
+		"check_url_statuses",
+		"Checks many URLs concurrently, such as every link in a report, and summarizes which are alive, dead or redirected",
+		cfg.checkURLStatusesHandler,
+		CheckURLStatusesParamSchema,
+	)
+}
+
+func (c *toolConfig) checkURLStatusesHandler(ctx context.Context, params CheckURLStatusesParams) (*CheckURLStatusesResult, error) {
+	if len(params.URLs) == 0 {
+		return nil, fmt.Errorf("urls is required")
+	}
+	if len(params.URLs) > 1000 {
+		return nil, fmt.Errorf("too many urls: %d (max 1000)", len(params.URLs))
+	}
+
+	// Set defaults
+	concurrency := 8
+	if params.Concurrency > 0 {
+		concurrency = min(params.Concurrency, 32)
+	}
+	perHost := 2
+	if params.PerHostLimit > 0 {
+		perHost = min(params.PerHostLimit, 8)
+	}
+	timeout := 10
+	if params.Timeout > 0 {
+		timeout = params.Timeout
+	}
+
+	client := c.client(time.Duration(timeout) * time.Second)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("too many redirects")
+		}
+		return nil
+	}
+	checker := &linkChecker{
+		client:  client,
+		headers: params.Headers,
+		hosts:   make(map[string]chan struct{}),
+		perHost: perHost,
+	}
+
+	var targets []string
+	seen := make(map[string]bool)
+	for _, u := range params.URLs {
+		if u = strings.TrimSpace(u); u != "" && !seen[u] {
+			seen[u] = true
+			targets = append(targets, u)
+		}
+	}
+
+	results := make([]URLStatus, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = checker.check(ctx, target)
+		}()
+	}
+	wg.Wait()
+
+	result := &CheckURLStatusesResult{
+		Results:   results,
+		Summary:   URLStatusCount{Total: len(results)},
+		CheckedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, r := range results {
+		switch r.Status {
+		case "alive":
+			result.Summary.Alive++
+		case "redirect":
+			result.Summary.Redirect++
+		default:
+			result.Summary.Dead++
+		}
+		if r.Soft404 {
+			result.Summary.Soft404++
+		}
+		if r.Error != "" {
+			result.Summary.Errors++
+		}
+	}
+	return result, nil
+}
+
+// linkChecker checks URLs while bounding the parallel requests to each host
+type linkChecker struct {
+	client  *http.Client
+	headers map[string]string
+
+	mu      sync.Mutex
+	hosts   map[string]chan struct{}
+	perHost int
+}
+
+// hostSlot blocks until a request to host may start and returns its release
+func (l *linkChecker) hostSlot(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	slots, ok := l.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, l.perHost)
+		l.hosts[host] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *linkChecker) check(ctx context.Context, target string) (status URLStatus) {
+	status.URL = target
+	start := time.Now()
+	defer func() { status.ResponseTime = time.Since(start).Milliseconds() }()
+
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		status.Status = "dead"
+		status.Error = "invalid URL"
+		return status
+	}
+
+	release, err := l.hostSlot(ctx, u.Host)
+	if err != nil {
+		status.Status = "dead"
+		status.Error = err.Error()
+		return status
+	}
+	defer release()
+
+	resp, body, err := l.request(ctx, "HEAD", target)
+	// Many servers reject HEAD outright; ask for the first bytes instead
+	if err == nil && headRejected(resp.StatusCode) {
+		resp, body, err = l.request(ctx, "GET", target)
+	}
+	if err != nil {
+		status.Status = "dead"
+		status.Error = err.Error()
+		return status
+	}
+
+	status.StatusCode = resp.StatusCode
+	status.Method = resp.Request.Method
+	status.ContentType = resp.Header.Get("Content-Type")
+	if final := resp.Request.URL.String(); final != target {
+		status.FinalURL = final
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The resource exists but is shorter than the range, e.g. empty
+		status.Status = "alive"
+		if status.FinalURL != "" {
+			status.Status = "redirect"
+		}
+		return status
+	case resp.StatusCode >= 400:
+		status.Status = "dead"
+		return status
+	case resp.StatusCode >= 300:
+		// Only reached when the redirect could not be followed
+		status.Status = "redirect"
+		return status
+	}
+
+	// Successful HTML pages may still be error pages in disguise
+	if resp.Request.Method == "HEAD" && isHTMLContentType(status.ContentType) {
+		if getResp, getBody, err := l.request(ctx, "GET", target); err == nil && getResp.StatusCode < 300 {
+			body = getBody
+		}
+	}
+	if reason := soft404Reason(u, resp.Request.URL, status.ContentType, body); reason != "" {
+		status.Status = "dead"
+		status.Soft404 = true
+		status.Reason = reason
+		return status
+	}
+
+	status.Status = "alive"
+	if status.FinalURL != "" {
+		status.Status = "redirect"
+	}
+	return status
+}
+
+// request sends a HEAD, or a GET for the start of the body, which is
+// returned along with the already closed response
+func (l *linkChecker) request(ctx context.Context, method, target string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", "go-flock/1.0 URLChecker")
+	// A cached answer says nothing about whether the link works now
+	req.Header.Set("Cache-Control", "no-cache")
+	if method == "GET" {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", soft404PeekSize-1))
+	}
+	for key, value := range l.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && urlErr.Timeout() {
+			return nil, nil, fmt.Errorf("request timeout")
+		}
+		return nil, nil, fmt.Errorf("checking URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Servers that ignore Range send the whole body; stop reading early
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, soft404PeekSize))
+	return resp, body, nil
+}
+
+// headRejected reports whether a HEAD status likely means the server refuses
+// HEAD rather than that the resource is missing
+func headRejected(code int) bool {
+	return code == http.StatusMethodNotAllowed || code == http.StatusForbidden || code == http.StatusNotImplemented
+}
+
+func isHTMLContentType(contentType string) bool {
+	return strings.Contains(contentType, "text/html") || strings.Contains(contentType, "application/xhtml")
+}
+
+var (
+	soft404PathRegex  = regexp.MustCompile(`(?i)(^|[/_.-])(404|not[-_]?found|page[-_]?not[-_]?found)([/_.-]|$)`)
+	soft404TitleRegex = regexp.MustCompile(`(?i)\b404\b|\b(page|file|article|content) (was )?not found\b|^not found\b|\bpage (does not|doesn't|no longer) exists?\b|\bpage (cannot|can't|could not) be found\b|\bno longer available\b`)
+)
+
+// soft404Reason explains why a successful response looks like a missing page,
+// or returns "" when it does not
+func soft404Reason(requested, final *url.URL, contentType string, body []byte) string {
+	if final.String() != requested.String() {
+		if final.Path == "" || final.Path == "/" {
+			if requested.Path != "" && requested.Path != "/" {
+				return "redirected to the site root"
+			}
+		} else if soft404PathRegex.MatchString(final.Path) && !soft404PathRegex.MatchString(requested.Path) {
+			return "redirected to an error page"
+		}
+	}
+
+	if len(body) == 0 || !isHTMLContentType(contentType) {
+		return ""
+	}
+	doc, err := parseHTML(string(body))
+	if err != nil {
+		return ""
+	}
+	if title := domFind(doc, func(n *html.Node) bool { return isTag(n, "title") }); title != nil {
+		if text := domText(title); soft404TitleRegex.MatchString(text) {
+			return fmt.Sprintf("page title %q", text)
+		}
+	}
+	if h1 := domFind(doc, func(n *html.Node) bool { return isTag(n, "h1") }); h1 != nil {
+		if text := domText(h1); soft404TitleRegex.MatchString(text) {
+			return fmt.Sprintf("page heading %q", text)
+		}
+	}
+	return ""
+}
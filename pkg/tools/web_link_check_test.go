@@ -0,0 +1,191 @@
+// ABOUTME: Unit tests for the check_url_statuses tool
+// ABOUTME: Tests HEAD-to-GET fallback, redirects, soft 404 detection, per-host limits and summaries
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckURLStatusesHandler(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>Annual report</title></head><body><h1>Report</h1></body></html>`)
+		case "/no-head":
+			if r.Method == "HEAD" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Header.Get("Range") == "" {
+				http.Error(w, "expected ranged GET", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/pdf")
+			w.WriteHeader(http.StatusPartialContent)
+			fmt.Fprint(w, "%PDF-1.7")
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+		case "/old-article":
+			http.Redirect(w, r, "/", http.StatusFound)
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>Home</title></head></html>`)
+		case "/soft":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, `<html><head><title>Example News</title></head><body><h1>Sorry, this page does not exist</h1></body></html>`)
+		case "/plain":
+			// Neither a title nor a heading to inspect
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body><p>hello</p></body></html>`)
+		case "/empty":
+			// An empty file cannot satisfy the range of the fallback GET
+			if r.Method == "HEAD" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		case "/soft-title":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>Page Not Found | Example</title></head></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().checkURLStatusesHandler(context.Background(), CheckURLStatusesParams{
+		URLs: []string{
+			server.URL + "/ok",
+			server.URL + "/no-head",
+			server.URL + "/moved",
+			server.URL + "/old-article",
+			server.URL + "/soft",
+			server.URL + "/soft-title",
+			server.URL + "/plain",
+			server.URL + "/empty",
+			server.URL + "/missing",
+			server.URL + "/ok",
+			"mailto:someone@example.com",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Results) != 10 {
+		t.Fatalf("Expected 10 distinct results, got %d", len(result.Results))
+	}
+
+	byPath := make(map[string]URLStatus)
+	for _, r := range result.Results {
+		if u, err := url.Parse(r.URL); err == nil && u.Scheme == "http" {
+			byPath[u.Path] = r
+		} else {
+			byPath[r.URL] = r
+		}
+	}
+
+	tests := []struct {
+		path    string
+		status  string
+		code    int
+		method  string
+		soft404 bool
+	}{
+		{path: "/ok", status: "alive", code: http.StatusOK, method: "HEAD"},
+		{path: "/no-head", status: "alive", code: http.StatusPartialContent, method: "GET"},
+		{path: "/moved", status: "redirect", code: http.StatusOK, method: "HEAD"},
+		{path: "/old-article", status: "dead", code: http.StatusOK, method: "HEAD", soft404: true},
+		{path: "/soft", status: "dead", code: http.StatusOK, method: "HEAD", soft404: true},
+		{path: "/soft-title", status: "dead", code: http.StatusOK, method: "HEAD", soft404: true},
+		{path: "/plain", status: "alive", code: http.StatusOK, method: "HEAD"},
+		{path: "/empty", status: "alive", code: http.StatusRequestedRangeNotSatisfiable, method: "GET"},
+		{path: "/missing", status: "dead", code: http.StatusNotFound, method: "HEAD"},
+		{path: "mailto:someone@example.com", status: "dead"},
+	}
+	for _, tt := range tests {
+		r, ok := byPath[tt.path]
+		if !ok {
+			t.Errorf("%s: missing result", tt.path)
+			continue
+		}
+		if r.Status != tt.status || r.StatusCode != tt.code || r.Method != tt.method || r.Soft404 != tt.soft404 {
+			t.Errorf("%s: got status=%s code=%d method=%s soft404=%v (reason %q), want status=%s code=%d method=%s soft404=%v",
+				tt.path, r.Status, r.StatusCode, r.Method, r.Soft404, r.Reason, tt.status, tt.code, tt.method, tt.soft404)
+		}
+	}
+
+	if got := byPath["/moved"].FinalURL; got != server.URL+"/ok" {
+		t.Errorf("Expected final URL %s/ok, got %q", server.URL, got)
+	}
+	if got := byPath["/old-article"].Reason; got != "redirected to the site root" {
+		t.Errorf("Unexpected soft 404 reason: %q", got)
+	}
+	if got := byPath["mailto:someone@example.com"].Error; got != "invalid URL" {
+		t.Errorf("Expected invalid URL error, got %q", got)
+	}
+
+	want := URLStatusCount{Total: 10, Alive: 4, Dead: 5, Redirect: 1, Soft404: 3, Errors: 1}
+	if result.Summary != want {
+		t.Errorf("Unexpected summary: got %+v, want %+v", result.Summary, want)
+	}
+}
+
+func TestCheckURLStatusesHandler_PerHostLimit(t *testing.T) {
+	t.Parallel()
+
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/plain")
+	}))
+	defer server.Close()
+
+	var urls []string
+	for i := range 8 {
+		urls = append(urls, fmt.Sprintf("%s/page/%d", server.URL, i))
+	}
+	result, err := newToolConfig().checkURLStatusesHandler(context.Background(), CheckURLStatusesParams{
+		URLs:         urls,
+		Concurrency:  8,
+		PerHostLimit: 2,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Summary.Alive != 8 {
+		t.Errorf("Expected 8 alive URLs, got %+v", result.Summary)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("Expected at most 2 parallel requests to the host, saw %d", p)
+	}
+}
+
+func TestCheckURLStatusesHandler_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := newToolConfig().checkURLStatusesHandler(context.Background(), CheckURLStatusesParams{}); err == nil {
+		t.Error("Expected error for empty urls")
+	}
+	if _, err := newToolConfig().checkURLStatusesHandler(context.Background(), CheckURLStatusesParams{URLs: make([]string, 1001)}); err == nil {
+		t.Error("Expected error for too many urls")
+	}
+}
@@ -0,0 +1,280 @@
+// ABOUTME: CSS selector based structured data extraction tool
+// ABOUTME: Maps field names to selectors with attribute, HTML and list modifiers and returns JSON values
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
+	"github.com/lexlapax/go-llms/pkg/agent/tools"
+	sdomain "github.com/lexlapax/go-llms/pkg/schema/domain"
+	"golang.org/x/net/html"
+)
+
+// Extract Structured Data Tool
+
+type ExtractStructuredDataParams struct {
+	URL          string            `json:"url,omitempty" description:"The URL of the web page to extract data from"`
+	HTML         string            `json:"html,omitempty" description:"HTML to extract data from instead of fetching a URL"`
+	Fields       map[string]string `json:"fields" description:"Map of field names to CSS selectors with optional @attr, @html, @text and [] modifiers"`
+	ItemSelector string            `json:"item_selector,omitempty" description:"CSS selector for repeated items; fields are then extracted relative to each item"`
+	Timeout      int               `json:"timeout,omitempty" description:"Timeout in seconds (default: 30)"`
+}
+
+type ExtractStructuredDataResult struct {
+	URL       string           `json:"url,omitempty"`
+	Data      map[string]any   `json:"data,omitempty"`  // Set without item_selector
+	Items     []map[string]any `json:"items,omitempty"` // Set with item_selector
+	ItemCount int              `json:"item_count"`
+	FetchedAt string           `json:"fetched_at"`
+}
+
+var ExtractStructuredDataParamSchema = &sdomain.Schema{
+	Type:        "object",
+	Description: "Parameters for extracting structured data from a web page with CSS selectors",
+	Properties: map[string]sdomain.Property{
+		"url": {
+			Type:        "string",
+			Description: "The URL of the web page to extract data from. Also used to resolve relative links when html is given",
+		},
+		"html": {
+			Type:        "string",
+			Description: "HTML to extract data from instead of fetching a URL",
+		},
+		"fields": {
+			Type: "object",
+			Description: "Map of field names to CSS selectors. By default the text of the first match is returned. " +
+				"Append '@attr' to return an attribute (e.g. 'a.next@href'; links are made absolute), '@html' for inner HTML " +
+				"or '@text' for text, and '[]' to the selector to return every match as a list (e.g. 'ul.tags li[]', 'img[]@src')",
+			AdditionalProperties: func() *bool { b := true; return &b }(),
+		},
+		"item_selector": {
+			Type:        "string",
+			Description: "CSS selector for repeated items such as search results or listings. When set, fields are extracted relative to each item and returned as a list of objects",
+		},
+		"timeout": {
+			Type:        "integer",
+			Description: "Timeout in seconds (default: 30)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(300),
+		},
+	},
+	Required: []string{"fields"},
+}
+
+// NewExtractStructuredDataTool creates a tool that extracts data with CSS selectors
+func NewExtractStructuredDataTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
+	return tools.NewTool(
+		"extract_structured_data",
+		"Extracts specific data from a web page using CSS selectors and returns it as JSON",
+		cfg.extractStructuredDataHandler,
+		ExtractStructuredDataParamSchema,
+	)
+}
+
+func (c *toolConfig) extractStructuredDataHandler(ctx context.Context, params ExtractStructuredDataParams) (*ExtractStructuredDataResult, error) {
+	if params.URL == "" && params.HTML == "" {
+		return nil, fmt.Errorf("either url or html is required")
+	}
+	if len(params.Fields) == 0 {
+		return nil, fmt.Errorf("fields must contain at least one selector")
+	}
+
+	// Compile selectors before fetching so mistakes fail fast
+	fields := make([]fieldSelector, 0, len(params.Fields))
+	for name, spec := range params.Fields {
+		field, err := parseFieldSelector(name, spec)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+
+	var itemSelector cascadia.Selector
+	if params.ItemSelector != "" {
+		sel, err := cascadia.Compile(params.ItemSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid item_selector %q: %w", params.ItemSelector, err)
+		}
+		itemSelector = sel
+	}
+
+	timeout := 30
+	if params.Timeout > 0 {
+		timeout = params.Timeout
+	}
+
+	// Load the document
+	var doc *html.Node
+	var base *url.URL
+	if params.HTML != "" {
+		parsed, err := parseHTML(params.HTML)
+		if err != nil {
+			return nil, fmt.Errorf("parsing HTML: %w", err)
+		}
+		doc = parsed
+		if params.URL != "" {
+			pageURL, err := url.Parse(params.URL)
+			if err != nil {
+				return nil, fmt.Errorf("parsing URL: %w", err)
+			}
+			base = documentBaseURL(doc, pageURL)
+		}
+	} else {
+		client := c.client(time.Duration(timeout) * time.Second)
+		fetched, pageURL, err := c.fetchHTMLDocument(ctx, client, nil, params.URL, "go-flock/1.0 DataExtractor")
+		if err != nil {
+			return nil, err
+		}
+		doc, base = fetched, documentBaseURL(fetched, pageURL)
+	}
+
+	result := &ExtractStructuredDataResult{
+		URL:       params.URL,
+		FetchedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if itemSelector == nil {
+		result.Data = extractFields(doc, fields, base)
+		result.ItemCount = 1
+		return result, nil
+	}
+
+	result.Items = []map[string]any{}
+	for _, item := range itemSelector.MatchAll(doc) {
+		result.Items = append(result.Items, extractFields(item, fields, base))
+	}
+	result.ItemCount = len(result.Items)
+
+	return result, nil
+}
+
+// fieldSelector is a compiled field specification such as "a.title@href[]"
+type fieldSelector struct {
+	name     string
+	selector cascadia.Selector // nil selects the root itself
+	attr     string            // attribute name, "text" or "html"
+	list     bool
+}
+
+// attrModifierRegex matches a trailing @attr modifier
+var attrModifierRegex = regexp.MustCompile(`@([A-Za-z_:][-A-Za-z0-9_:.]*)$`)
+
+// urlAttributes hold links that are resolved against the page URL
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "action": true, "poster": true, "cite": true, "data-src": true,
+}
+
+// parseFieldSelector parses "selector[@attr]" where the selector may end in
+// "[]" to select every match. "[]" is also accepted after the modifier. A
+// bare "@attr" reads the attribute of the element being searched, which is
+// how item attributes are read with item_selector.
+func parseFieldSelector(name, spec string) (fieldSelector, error) {
+	field := fieldSelector{name: name, attr: "text"}
+
+	s := strings.TrimSpace(spec)
+	if strings.HasSuffix(s, "[]") {
+		field.list = true
+		s = strings.TrimSpace(strings.TrimSuffix(s, "[]"))
+	}
+	if m := attrModifierRegex.FindStringSubmatchIndex(s); m != nil {
+		field.attr = strings.ToLower(s[m[2]:m[3]])
+		s = strings.TrimSpace(s[:m[0]])
+	}
+	if strings.HasSuffix(s, "[]") {
+		field.list = true
+		s = strings.TrimSpace(strings.TrimSuffix(s, "[]"))
+	}
+	if s == "" {
+		// "@attr" on its own selects the item itself
+		if !strings.Contains(spec, "@") {
+			return field, fmt.Errorf("empty selector for field %q", name)
+		}
+		return field, nil
+	}
+
+	sel, err := cascadia.Compile(s)
+	if err != nil {
+		return field, fmt.Errorf("invalid selector for field %q: %w", name, err)
+	}
+	field.selector = sel
+	return field, nil
+}
+
+// extractFields evaluates every field under root. Missing single values are
+// nil and missing lists are empty.
+func extractFields(root *html.Node, fields []fieldSelector, base *url.URL) map[string]any {
+	data := make(map[string]any, len(fields))
+	for _, f := range fields {
+		matches := []*html.Node{root}
+		if f.selector != nil {
+			matches = f.selector.MatchAll(root)
+		}
+
+		if f.list {
+			values := []string{}
+			for _, n := range matches {
+				if v, ok := selectedValue(n, f.attr, base); ok {
+					values = append(values, v)
+				}
+			}
+			data[f.name] = values
+			continue
+		}
+
+		data[f.name] = nil
+		for _, n := range matches {
+			if v, ok := selectedValue(n, f.attr, base); ok {
+				data[f.name] = v
+				break
+			}
+		}
+	}
+	return data
+}
+
+// selectedValue returns the requested value of n. Elements without the
+// requested attribute report false so the next match can be used.
+func selectedValue(n *html.Node, attr string, base *url.URL) (string, bool) {
+	switch attr {
+	case "text":
+		return domText(n), true
+	case "html":
+		return innerHTML(n), true
+	}
+
+	for _, a := range n.Attr {
+		if a.Namespace != "" || !strings.EqualFold(a.Key, attr) {
+			continue
+		}
+		value := strings.TrimSpace(a.Val)
+		if urlAttributes[attr] && base != nil {
+			if resolved, err := resolveURL(base, value); err == nil {
+				value = resolved.String()
+			}
+		}
+		return value, true
+	}
+	return "", false
+}
+
+// innerHTML renders the children of n
+func innerHTML(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&b, c); err != nil {
+			break
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
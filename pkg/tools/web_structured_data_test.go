@@ -0,0 +1,212 @@
+// ABOUTME: Unit tests for the extract_structured_data tool
+// ABOUTME: Tests selector modifiers, item extraction, URL resolution and error handling
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleListingHTML = `<html><head><title>Listings</title></head><body>
+<h1 class="page-title"> Used  Bikes </h1>
+<ul class="tags"><li>road</li><li>gravel</li></ul>
+<div class="listing" data-id="1">
+	<a class="title" href="/bikes/1">Steel Tourer</a>
+	<span class="price">$450</span>
+	<img src="/img/1.jpg">
+</div>
+<div class="listing" data-id="2">
+	<a class="title" href="https://other.example/bikes/2">Carbon Racer</a>
+	<span class="price">$1,200</span>
+</div>
+<p class="note">Prices in <b>USD</b></p>
+</body></html>`
+
+func TestParseFieldSelector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec string
+		attr string
+		list bool
+	}{
+		{spec: "h1", attr: "text"},
+		{spec: "a.title@href", attr: "href"},
+		{spec: "ul li[]", attr: "text", list: true},
+		{spec: "img[]@src", attr: "src", list: true},
+		{spec: "img@src[]", attr: "src", list: true},
+		{spec: `a[href$=".pdf"]@HREF`, attr: "href"},
+		{spec: "p.note@html", attr: "html"},
+		{spec: "@data-id", attr: "data-id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			field, err := parseFieldSelector("f", tt.spec)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if field.attr != tt.attr || field.list != tt.list {
+				t.Errorf("parseFieldSelector(%q) = attr %q list %v, want attr %q list %v", tt.spec, field.attr, field.list, tt.attr, tt.list)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "[]", "div[", "a@href@"} {
+		if _, err := parseFieldSelector("f", spec); err == nil {
+			t.Errorf("Expected error for selector %q", spec)
+		}
+	}
+}
+
+func TestExtractStructuredDataHandler_HTML(t *testing.T) {
+	t.Parallel()
+
+	result, err := newToolConfig().extractStructuredDataHandler(context.Background(), ExtractStructuredDataParams{
+		URL:  "https://shop.example.com/bikes/",
+		HTML: sampleListingHTML,
+		Fields: map[string]string{
+			"heading": "h1.page-title",
+			"tags":    "ul.tags li[]",
+			"first":   ".listing a.title@href",
+			"links":   ".listing a.title[]@href",
+			"images":  ".listing img[]@src",
+			"note":    "p.note@html",
+			"missing": ".sold-out",
+			"none":    ".sold-out[]",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]any{
+		"heading": "Used Bikes",
+		"tags":    []string{"road", "gravel"},
+		"first":   "https://shop.example.com/bikes/1",
+		"links":   []string{"https://shop.example.com/bikes/1", "https://other.example/bikes/2"},
+		"images":  []string{"https://shop.example.com/img/1.jpg"},
+		"note":    "Prices in <b>USD</b>",
+		"missing": nil,
+		"none":    []string{},
+	}
+	if !reflect.DeepEqual(result.Data, want) {
+		t.Errorf("Unexpected data:\ngot:  %#v\nwant: %#v", result.Data, want)
+	}
+	if result.Items != nil || result.ItemCount != 1 {
+		t.Errorf("Expected a single record, got items %v count %d", result.Items, result.ItemCount)
+	}
+}
+
+func TestExtractStructuredDataHandler_Items(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, sampleListingHTML)
+	}))
+	defer server.Close()
+
+	result, err := newToolConfig().extractStructuredDataHandler(context.Background(), ExtractStructuredDataParams{
+		URL:          server.URL + "/bikes",
+		ItemSelector: "div.listing",
+		Fields: map[string]string{
+			"id":    "@data-id",
+			"title": "a.title",
+			"url":   "a.title@href",
+			"price": ".price",
+			"image": "img@src",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []map[string]any{
+		{"id": "1", "title": "Steel Tourer", "url": server.URL + "/bikes/1", "price": "$450", "image": server.URL + "/img/1.jpg"},
+		{"id": "2", "title": "Carbon Racer", "url": "https://other.example/bikes/2", "price": "$1,200", "image": nil},
+	}
+	if !reflect.DeepEqual(result.Items, want) {
+		t.Errorf("Unexpected items:\ngot:  %#v\nwant: %#v", result.Items, want)
+	}
+	if result.ItemCount != 2 || result.Data != nil {
+		t.Errorf("Expected 2 items and no data, got count %d data %v", result.ItemCount, result.Data)
+	}
+	if len(requests) == 0 || requests[0] != "/robots.txt" {
+		t.Errorf("Expected robots.txt to be checked first, got %v", requests)
+	}
+}
+
+func TestExtractStructuredDataHandler_Errors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		params ExtractStructuredDataParams
+		errMsg string
+	}{
+		{
+			name:   "no input",
+			params: ExtractStructuredDataParams{Fields: map[string]string{"a": "a"}},
+			errMsg: "either url or html is required",
+		},
+		{
+			name:   "no fields",
+			params: ExtractStructuredDataParams{HTML: "<p></p>"},
+			errMsg: "fields must contain at least one selector",
+		},
+		{
+			name:   "invalid selector",
+			params: ExtractStructuredDataParams{HTML: "<p></p>", Fields: map[string]string{"bad": "div["}},
+			errMsg: `invalid selector for field "bad"`,
+		},
+		{
+			name:   "invalid item selector",
+			params: ExtractStructuredDataParams{HTML: "<p></p>", Fields: map[string]string{"a": "a"}, ItemSelector: ">>"},
+			errMsg: "invalid item_selector",
+		},
+		{
+			name:   "http error",
+			params: ExtractStructuredDataParams{URL: server.URL, Fields: map[string]string{"a": "a"}},
+			errMsg: "unexpected status code: 404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newToolConfig().extractStructuredDataHandler(context.Background(), tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestNewExtractStructuredDataTool(t *testing.T) {
+	t.Parallel()
+
+	tool := NewExtractStructuredDataTool()
+	if tool.Name() != "extract_structured_data" {
+		t.Errorf("Expected tool name 'extract_structured_data', got %q", tool.Name())
+	}
+	if tool.ParameterSchema() == nil {
+		t.Error("Expected parameter schema")
+	}
+}
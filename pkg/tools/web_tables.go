@@ -0,0 +1,398 @@
+// ABOUTME: HTML table extraction tool
+// ABOUTME: Normalizes headers, colspan and rowspan into a grid and returns tables as JSON rows or CSV
+
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
+	"github.com/lexlapax/go-llms/pkg/agent/tools"
+	sdomain "github.com/lexlapax/go-llms/pkg/schema/domain"
+	"golang.org/x/net/html"
+)
+
+// Extract Tables Tool
+
+type ExtractTablesParams struct {
+	URL        string `json:"url,omitempty" description:"The URL of the web page to extract tables from"`
+	HTML       string `json:"html,omitempty" description:"HTML to extract tables from instead of fetching a URL"`
+	Selector   string `json:"selector,omitempty" description:"CSS selector for the tables to extract (default: table)"`
+	TableIndex int    `json:"table_index,omitempty" description:"Only return the table at this 1-based position (default: all tables)"`
+	Format     string `json:"format,omitempty" description:"Output format: json or csv (default: json)"`
+	Timeout    int    `json:"timeout,omitempty" description:"Timeout in seconds (default: 30)"`
+}
+
+type ExtractTablesResult struct {
+	URL        string      `json:"url,omitempty"`
+	TableCount int         `json:"table_count"` // Tables matching the selector
+	Tables     []HTMLTable `json:"tables"`
+	FetchedAt  string      `json:"fetched_at"`
+}
+
+type HTMLTable struct {
+	Index       int        `json:"index"` // 1-based position among matching tables
+	Caption     string     `json:"caption,omitempty"`
+	ID          string     `json:"id,omitempty"`
+	Headers     []string   `json:"headers,omitempty"`
+	Rows        [][]string `json:"rows,omitempty"` // Set in json format
+	CSV         string     `json:"csv,omitempty"`  // Set in csv format, including the header row
+	RowCount    int        `json:"row_count"`
+	ColumnCount int        `json:"column_count"`
+	Truncated   bool       `json:"truncated,omitempty"` // Rows or columns beyond the size limits were dropped
+}
+
+var ExtractTablesParamSchema = &sdomain.Schema{
+	Type:        "object",
+	Description: "Parameters for extracting tables from a web page",
+	Properties: map[string]sdomain.Property{
+		"url": {
+			Type:        "string",
+			Description: "The URL of the web page to extract tables from",
+		},
+		"html": {
+			Type:        "string",
+			Description: "HTML to extract tables from instead of fetching a URL",
+		},
+		"selector": {
+			Type:        "string",
+			Description: "CSS selector for the tables to extract, e.g. 'table.wikitable' (default: table)",
+		},
+		"table_index": {
+			Type:        "integer",
+			Description: "Only return the table at this 1-based position among matching tables (default: all tables)",
+			Minimum:     float64Ptr(0),
+		},
+		"format": {
+			Type:        "string",
+			Description: "Output format: 'json' for header and row arrays, 'csv' for CSV text (default: json)",
+			Enum:        []string{"json", "csv"},
+		},
+		"timeout": {
+			Type:        "integer",
+			Description: "Timeout in seconds (default: 30)",
+			Minimum:     float64Ptr(1),
+			Maximum:     float64Ptr(300),
+		},
+	},
+}
+
+// NewExtractTablesTool creates a tool that extracts HTML tables
+func NewExtractTablesTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
+	return tools.NewTool(
+		"extract_tables",
+		"Extracts tables from a web page as headers and rows in JSON or as CSV",
+		cfg.extractTablesHandler,
+		ExtractTablesParamSchema,
+	)
+}
+
+func (c *toolConfig) extractTablesHandler(ctx context.Context, params ExtractTablesParams) (*ExtractTablesResult, error) {
+	if params.URL == "" && params.HTML == "" {
+		return nil, fmt.Errorf("either url or html is required")
+	}
+
+	format := "json"
+	if params.Format != "" {
+		format = params.Format
+	}
+	if format != "json" && format != "csv" {
+		return nil, fmt.Errorf("invalid format %q (must be 'json' or 'csv')", params.Format)
+	}
+
+	selector := "table"
+	if params.Selector != "" {
+		selector = params.Selector
+	}
+	sel, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+	}
+
+	timeout := 30
+	if params.Timeout > 0 {
+		timeout = params.Timeout
+	}
+
+	var doc *html.Node
+	if params.HTML != "" {
+		if doc, err = parseHTML(params.HTML); err != nil {
+			return nil, fmt.Errorf("parsing HTML: %w", err)
+		}
+	} else {
+		client := c.client(time.Duration(timeout) * time.Second)
+		if doc, _, err = c.fetchHTMLDocument(ctx, client, nil, params.URL, "go-flock/1.0 TableExtractor"); err != nil {
+			return nil, err
+		}
+	}
+
+	var tables []*html.Node
+	for _, n := range sel.MatchAll(doc) {
+		if isTag(n, "table") {
+			tables = append(tables, n)
+		}
+	}
+
+	if params.TableIndex > len(tables) {
+		return nil, fmt.Errorf("table_index %d out of range (found %d tables)", params.TableIndex, len(tables))
+	}
+
+	result := &ExtractTablesResult{
+		URL:        params.URL,
+		TableCount: len(tables),
+		Tables:     []HTMLTable{},
+		FetchedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	for i, n := range tables {
+		if params.TableIndex > 0 && params.TableIndex != i+1 {
+			continue
+		}
+
+		table := parseTable(n)
+		table.Index = i + 1
+		if format == "csv" {
+			csvText, err := tableCSV(table)
+			if err != nil {
+				return nil, fmt.Errorf("writing CSV: %w", err)
+			}
+			table.CSV = csvText
+			table.Rows = nil
+		}
+		result.Tables = append(result.Tables, table)
+	}
+
+	return result, nil
+}
+
+// Limits on the laid out grid, so malformed or hostile markup cannot blow it
+// up: a single span, the columns of a row and the cells of the padded grid
+const (
+	maxTableSpan    = 1000
+	maxTableColumns = 1000
+	maxTableCells   = 100_000
+)
+
+// tableRow is a row of a table before spans are expanded
+type tableRow struct {
+	cells  []*html.Node
+	header bool // row is in <thead> or made only of <th> cells
+}
+
+// parseTable lays out a table as a rectangular grid. Cells spanning several
+// columns or rows repeat their text in each position they cover. The leading
+// rows that are in <thead> or made only of <th> cells form the headers;
+// multiple header rows are joined per column with " / ".
+func parseTable(table *html.Node) HTMLTable {
+	result := HTMLTable{ID: domAttr(table, "id")}
+	if caption := domFind(table, func(n *html.Node) bool { return isTag(n, "caption") }); caption != nil && ownerTable(caption) == table {
+		result.Caption = cellText(caption)
+	}
+
+	var rows []tableRow
+	for _, tr := range domFindAll(table, func(n *html.Node) bool { return isTag(n, "tr") && ownerTable(n) == table }) {
+		thead := closestAncestor(tr, "thead")
+		row := tableRow{header: thead != nil && ownerTable(thead) == table}
+		allTH := true
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if isTag(c, "td", "th") {
+				row.cells = append(row.cells, c)
+				allTH = allTH && c.Data == "th"
+			}
+		}
+		if len(row.cells) == 0 {
+			continue
+		}
+		row.header = row.header || allTH
+		rows = append(rows, row)
+	}
+
+	grid, truncated := layoutTableGrid(rows)
+	result.Truncated = truncated
+
+	// Leading header rows become the headers; header rows further down are data
+	headerRows := 0
+	for headerRows < len(grid) && rows[headerRows].header {
+		headerRows++
+	}
+	if headerRows == len(grid) && headerRows > 1 {
+		headerRows = 1
+	}
+
+	columns := 0
+	for _, row := range grid {
+		columns = max(columns, len(row))
+	}
+	for i := range grid {
+		for len(grid[i]) < columns {
+			grid[i] = append(grid[i], "")
+		}
+	}
+
+	if headerRows > 0 {
+		result.Headers = make([]string, columns)
+		for col := range columns {
+			var parts []string
+			for _, row := range grid[:headerRows] {
+				if text := row[col]; text != "" && (len(parts) == 0 || parts[len(parts)-1] != text) {
+					parts = append(parts, text)
+				}
+			}
+			result.Headers[col] = strings.Join(parts, " / ")
+		}
+	}
+
+	result.Rows = grid[headerRows:]
+	if result.Rows == nil {
+		result.Rows = [][]string{}
+	}
+	result.RowCount = len(result.Rows)
+	result.ColumnCount = columns
+	return result
+}
+
+// layoutTableGrid expands colspan and rowspan into a grid of cell texts. It
+// stops at maxTableColumns columns and at the row that would take the padded
+// grid past maxTableCells, reporting whether anything was cut.
+func layoutTableGrid(rows []tableRow) (grid [][]string, truncated bool) {
+	type pendingSpan struct {
+		text string
+		rows int
+	}
+	var pending []pendingSpan // by column, cells still spanning down from earlier rows
+	grid = make([][]string, 0, len(rows))
+	columns := 0
+
+	for _, row := range rows {
+		var line []string
+		col := 0
+		// fillPending places the cells spanning down into this row, up to the
+		// next free column or, at the end of the row, through every column
+		// still spanned, leaving gaps empty
+		fillPending := func(all bool) {
+			last := col
+			if all {
+				for i := col; i < len(pending); i++ {
+					if pending[i].rows > 0 {
+						last = i + 1
+					}
+				}
+			}
+			for col < len(pending) && (pending[col].rows > 0 || col < last) {
+				if pending[col].rows > 0 {
+					line = append(line, pending[col].text)
+					pending[col].rows--
+				} else {
+					line = append(line, "")
+				}
+				col++
+			}
+		}
+
+	cells:
+		for _, cell := range row.cells {
+			fillPending(false)
+			text := cellText(cell)
+			colspan := tableSpan(cell, "colspan")
+			rowspan := tableSpan(cell, "rowspan")
+			for range colspan {
+				if col == maxTableColumns {
+					truncated = true
+					break cells
+				}
+				line = append(line, text)
+				for len(pending) <= col {
+					pending = append(pending, pendingSpan{})
+				}
+				pending[col] = pendingSpan{text: text, rows: rowspan - 1}
+				col++
+			}
+		}
+		fillPending(true)
+
+		columns = max(columns, len(line))
+		if (len(grid)+1)*columns > maxTableCells {
+			return grid, true
+		}
+		grid = append(grid, line)
+	}
+	return grid, truncated
+}
+
+// tableSpan reads a colspan or rowspan attribute, defaulting to 1
+func tableSpan(cell *html.Node, attr string) int {
+	span, err := strconv.Atoi(strings.TrimSpace(domAttr(cell, attr)))
+	if err != nil || span < 1 {
+		return 1
+	}
+	return min(span, maxTableSpan)
+}
+
+// ownerTable returns the table an element belongs to, so rows of nested
+// tables are not mixed into their parent
+func ownerTable(n *html.Node) *html.Node {
+	return closestAncestor(n, "table")
+}
+
+// cellText returns the text of a cell with whitespace collapsed. Nested
+// tables, hidden elements and Wikipedia-style citation markers are skipped.
+func cellText(cell *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if hiddenElements[n.Data] || n.Data == "table" || isCitationMarker(n) || isHiddenElement(n) {
+				return
+			}
+			if n.Data == "br" || blockElements[n.Data] {
+				b.WriteString(" ")
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for c := cell.FirstChild; c != nil; c = c.NextSibling {
+		walk(c)
+	}
+	return normalizeSpace(b.String())
+}
+
+// isCitationMarker reports whether n is a footnote reference such as [1]
+func isCitationMarker(n *html.Node) bool {
+	if !isTag(n, "sup") {
+		return false
+	}
+	for _, class := range strings.Fields(domAttr(n, "class")) {
+		if class == "reference" || class == "noprint" {
+			return true
+		}
+	}
+	return false
+}
+
+// tableCSV renders a table as CSV with the header row first
+func tableCSV(table HTMLTable) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if table.Headers != nil {
+		if err := w.Write(table.Headers); err != nil {
+			return "", err
+		}
+	}
+	if err := w.WriteAll(table.Rows); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
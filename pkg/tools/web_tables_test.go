@@ -0,0 +1,210 @@
+// ABOUTME: Unit tests for the extract_tables tool
+// ABOUTME: Tests header detection, colspan/rowspan layout, nested tables and CSV output
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+const sampleTablesHTML = `<html><body>
+<table class="layout"><tr><td>
+	<table class="wikitable" id="pop">
+		<caption>Population by city<sup class="reference">[1]</sup></caption>
+		<thead>
+			<tr><th rowspan="2">City</th><th colspan="2">Population</th></tr>
+			<tr><th>2010</th><th>2020</th></tr>
+		</thead>
+		<tbody>
+			<tr><td><a href="/wiki/Oslo">Oslo</a></td><td>599,230</td><td>697,010<sup class="reference">[2]</sup></td></tr>
+			<tr><td rowspan="2">Bergen</td><td colspan="2">about <b>280,000</b></td></tr>
+			<tr><td>271,949</td><td>285,911</td></tr>
+		</tbody>
+	</table>
+</td></tr></table>
+<table>
+	<tr><th>Key</th><th>Value</th></tr>
+	<tr><td>a, b</td><td>line one<br>line "two"</td></tr>
+</table>
+</body></html>`
+
+func TestParseTable(t *testing.T) {
+	t.Parallel()
+
+	doc, err := parseHTML(sampleTablesHTML)
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	tables := domFindAll(doc, func(n *html.Node) bool { return isTag(n, "table") && domAttr(n, "id") == "pop" })
+	if len(tables) != 1 {
+		t.Fatalf("Expected to find the population table")
+	}
+
+	table := parseTable(tables[0])
+
+	if table.Caption != "Population by city" {
+		t.Errorf("Expected caption without citation marker, got %q", table.Caption)
+	}
+	if table.ID != "pop" {
+		t.Errorf("Expected id 'pop', got %q", table.ID)
+	}
+	wantHeaders := []string{"City", "Population / 2010", "Population / 2020"}
+	if !reflect.DeepEqual(table.Headers, wantHeaders) {
+		t.Errorf("Unexpected headers: got %q, want %q", table.Headers, wantHeaders)
+	}
+	wantRows := [][]string{
+		{"Oslo", "599,230", "697,010"},
+		{"Bergen", "about 280,000", "about 280,000"},
+		{"Bergen", "271,949", "285,911"},
+	}
+	if !reflect.DeepEqual(table.Rows, wantRows) {
+		t.Errorf("Unexpected rows:\ngot:  %q\nwant: %q", table.Rows, wantRows)
+	}
+	if table.RowCount != 3 || table.ColumnCount != 3 {
+		t.Errorf("Expected 3x3 table, got %dx%d", table.RowCount, table.ColumnCount)
+	}
+}
+
+func TestParseTable_RaggedRows(t *testing.T) {
+	t.Parallel()
+
+	doc, err := parseHTML(`<table><tr><td>1</td></tr><tr><td>2</td><td>3</td><td colspan="0">4</td></tr></table>`)
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	table := parseTable(domFind(doc, func(n *html.Node) bool { return isTag(n, "table") }))
+
+	if table.Headers != nil {
+		t.Errorf("Expected no headers, got %q", table.Headers)
+	}
+	want := [][]string{{"1", "", ""}, {"2", "3", "4"}}
+	if !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("Expected padded rows %q, got %q", want, table.Rows)
+	}
+}
+
+func TestParseTable_RowspanAfterGap(t *testing.T) {
+	t.Parallel()
+
+	// The short second row leaves column 2 empty; the rowspan in column 3
+	// must still end there rather than shift into the third row
+	doc, err := parseHTML(`<table>
+		<tr><td>a</td><td>b</td><td rowspan="2">c</td></tr>
+		<tr><td>d</td></tr>
+		<tr><td>e</td><td>f</td></tr>
+	</table>`)
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	table := parseTable(domFind(doc, func(n *html.Node) bool { return isTag(n, "table") }))
+
+	want := [][]string{{"a", "b", "c"}, {"d", "", "c"}, {"e", "f", ""}}
+	if !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("Unexpected rows:\ngot:  %q\nwant: %q", table.Rows, want)
+	}
+}
+
+func TestParseTable_SizeLimits(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	b.WriteString(`<table><tr><td colspan="1000" rowspan="1000">x</td></tr>`)
+	for range 500 {
+		b.WriteString(`<tr><td>y</td><td>z</td></tr>`)
+	}
+	b.WriteString(`<tr>` + strings.Repeat(`<td>w</td>`, 5000) + `</tr></table>`)
+	doc, err := parseHTML(b.String())
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	table := parseTable(domFind(doc, func(n *html.Node) bool { return isTag(n, "table") }))
+
+	if !table.Truncated || table.ColumnCount != maxTableColumns || table.RowCount*table.ColumnCount > maxTableCells {
+		t.Errorf("Expected the grid cut to the limits, got %d rows of %d columns (truncated %v)", table.RowCount, table.ColumnCount, table.Truncated)
+	}
+}
+
+func TestExtractTablesHandler(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, sampleTablesHTML)
+	}))
+	defer server.Close()
+
+	t.Run("all tables", func(t *testing.T) {
+		result, err := newToolConfig().extractTablesHandler(context.Background(), ExtractTablesParams{URL: server.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.TableCount != 3 || len(result.Tables) != 3 {
+			t.Fatalf("Expected 3 tables including the layout table, got %d/%d", result.TableCount, len(result.Tables))
+		}
+		if result.Tables[1].Caption != "Population by city" || result.Tables[1].Index != 2 {
+			t.Errorf("Expected nested table second, got index %d caption %q", result.Tables[1].Index, result.Tables[1].Caption)
+		}
+		if got := result.Tables[0].Rows; len(got) != 1 || got[0][0] != "" {
+			t.Errorf("Expected layout table cell to exclude the nested table, got %q", got)
+		}
+	})
+
+	t.Run("selector, index and csv", func(t *testing.T) {
+		result, err := newToolConfig().extractTablesHandler(context.Background(), ExtractTablesParams{
+			HTML:       sampleTablesHTML,
+			Selector:   "body > table",
+			TableIndex: 2,
+			Format:     "csv",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.TableCount != 2 || len(result.Tables) != 1 {
+			t.Fatalf("Expected one of 2 matching tables, got %d/%d", len(result.Tables), result.TableCount)
+		}
+		table := result.Tables[0]
+		wantCSV := "Key,Value\n\"a, b\",\"line one line \"\"two\"\"\"\n"
+		if table.CSV != wantCSV {
+			t.Errorf("Unexpected CSV:\ngot:  %q\nwant: %q", table.CSV, wantCSV)
+		}
+		if table.Rows != nil {
+			t.Errorf("Expected rows to be omitted in csv format, got %q", table.Rows)
+		}
+	})
+}
+
+func TestExtractTablesHandler_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		params ExtractTablesParams
+		errMsg string
+	}{
+		{name: "no input", params: ExtractTablesParams{}, errMsg: "either url or html is required"},
+		{name: "bad format", params: ExtractTablesParams{HTML: "<table></table>", Format: "xml"}, errMsg: "invalid format"},
+		{name: "bad selector", params: ExtractTablesParams{HTML: "<table></table>", Selector: "table["}, errMsg: "invalid selector"},
+		{name: "index out of range", params: ExtractTablesParams{HTML: "<table></table>", TableIndex: 2}, errMsg: "table_index 2 out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newToolConfig().extractTablesHandler(context.Background(), tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -5,8 +5,8 @@ package tools
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -16,6 +16,7 @@ import (
 	domain "github.com/lexlapax/go-llms/pkg/agent/domain"
 	"github.com/lexlapax/go-llms/pkg/agent/tools"
 	sdomain "github.com/lexlapax/go-llms/pkg/schema/domain"
+	"golang.org/x/net/html"
 )
 
 // Tool Parameters
@@ -26,6 +27,13 @@ type FetchWebPageParams struct {
 	Headers         map[string]string `json:"headers,omitempty" description:"Additional HTTP headers"`
 	ExtractText     bool              `json:"extract_text,omitempty" description:"Extract only text content (default: true)"`
 	FollowRedirects bool              `json:"follow_redirects,omitempty" description:"Follow HTTP redirects (default: true)"`
+	ExtractMode     string            `json:"extract_mode,omitempty" description:"Content to extract from HTML pages: full or article (default: full)"`
+	OutputFormat    string            `json:"output_format,omitempty" description:"Format of extracted HTML content: text or markdown (default: text)"`
+	AllowBinary     bool              `json:"allow_binary,omitempty" description:"Return binary content such as images or PDFs base64-encoded instead of refusing it (default: false)"`
+	MaxTokens       int               `json:"max_tokens,omitempty" description:"Split content longer than this many estimated tokens into chunks"`
+	MaxChars        int               `json:"max_chars,omitempty" description:"Split content longer than this many characters into chunks"`
+	Chunk           int               `json:"chunk,omitempty" description:"Which chunk of split content to return (default: 1)"`
+	Session         string            `json:"session,omitempty" description:"Session whose cookies and default headers to use (default: the run's default session)"`
 }
 
 // Tool Results
@@ -36,6 +44,11 @@ type FetchWebPageResult struct {
 	ContentType string            `json:"content_type"`
 	StatusCode  int               `json:"status_code"`
 	Headers     map[string]string `json:"headers"`
+	Article     *ArticleInfo      `json:"article,omitempty"`   // Set in "article" extract mode
+	Encoding    string            `json:"encoding,omitempty"`  // "base64" for binary content
+	Truncated   bool              `json:"truncated,omitempty"` // The body exceeded the size limit and was cut short
+	Chunking    *ChunkInfo        `json:"chunking,omitempty"`  // Set when max_tokens or max_chars applies
+	Cache       string            `json:"cache,omitempty"`     // "hit" or "miss" when a cache is configured
 	FetchedAt   string            `json:"fetched_at"`
 }
 
@@ -71,21 +84,55 @@ var FetchWebPageParamSchema = &sdomain.Schema{
 			Type:        "boolean",
 			Description: "Follow HTTP redirects (default: true)",
 		},
+		"extract_mode": {
+			Type:        "string",
+			Description: "Content to extract from HTML pages: 'full' for all text, 'article' for the main article body with byline, published date, lead image and word count (default: full)",
+			Enum:        []string{"full", "article"},
+		},
+		"output_format": {
+			Type:        "string",
+			Description: "Format of extracted HTML content: 'text' for plain text, 'markdown' for CommonMark keeping headings, lists, links, code blocks and tables (default: text)",
+			Enum:        []string{"text", "markdown"},
+		},
+		"allow_binary": {
+			Type:        "boolean",
+			Description: "Return binary content such as images or PDFs base64-encoded instead of refusing it (default: false)",
+		},
+		"max_tokens": {
+			Type:        "integer",
+			Description: "Split content longer than this many estimated tokens into chunks at paragraph, sentence or word boundaries and return one chunk",
+			Minimum:     float64Ptr(1),
+		},
+		"max_chars": {
+			Type:        "integer",
+			Description: "Split content longer than this many characters into chunks and return one chunk",
+			Minimum:     float64Ptr(1),
+		},
+		"chunk": {
+			Type:        "integer",
+			Description: "Which chunk of split content to return; see chunking.total_chunks in the result (default: 1)",
+			Minimum:     float64Ptr(1),
+		},
+		"session": {
+			Type:        "string",
+			Description: "Name of the session whose cookies and default headers carry over between calls, e.g. to stay logged in (default: the run's default session)",
+		},
 	},
 	Required: []string{"url"},
 }
 
 // NewFetchWebPageTool creates a new web page fetching tool
-func NewFetchWebPageTool() domain.Tool {
+func NewFetchWebPageTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
 	return tools.NewTool(
 		"fetch_webpage",
 		"Fetches content from a web page and optionally extracts text",
-		fetchWebPageHandler,
+		cfg.fetchWebPageHandler,
 		FetchWebPageParamSchema,
 	)
 }
 
-func fetchWebPageHandler(ctx context.Context, params FetchWebPageParams) (*FetchWebPageResult, error) {
+func (c *toolConfig) fetchWebPageHandler(ctx context.Context, params FetchWebPageParams) (*FetchWebPageResult, error) {
 	// Set defaults
 	timeout := 30
 	if params.Timeout > 0 {
@@ -102,16 +149,32 @@ func fetchWebPageHandler(ctx context.Context, params FetchWebPageParams) (*Fetch
 		followRedirects = false
 	}
 
-	// Create HTTP client
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
+	extraction, err := newPageExtraction(extractText, params.ExtractMode, params.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+	if extraction.window, err = c.contentWindow(params.MaxTokens, params.MaxChars, params.Chunk); err != nil {
+		return nil, err
+	}
+	session, err := c.session(ctx, params.Session)
+	if err != nil {
+		return nil, err
 	}
 
-	// Configure redirect policy
-	if !followRedirects {
-		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+	// Create HTTP client
+	client := c.client(time.Duration(timeout) * time.Second)
+	session.attach(client)
+	ctx, cache := withCacheRecorder(ctx)
+
+	// Configure redirect policy; redirect targets are subject to robots.txt too
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !followRedirects {
 			return http.ErrUseLastResponse
 		}
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return c.checkRobots(req.Context(), client, req.URL)
 	}
 
 	// Create request
@@ -120,12 +183,17 @@ func fetchWebPageHandler(ctx context.Context, params FetchWebPageParams) (*Fetch
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
+	if err := c.checkRobots(ctx, client, req.URL); err != nil {
+		return nil, err
+	}
+
 	// Set headers
 	userAgent := "go-flock/1.0 WebFetcher"
 	if params.UserAgent != "" {
 		userAgent = params.UserAgent
 	}
 	req.Header.Set("User-Agent", userAgent)
+	session.applyHeaders(req)
 
 	// Add custom headers
 	for key, value := range params.Headers {
@@ -139,17 +207,97 @@ func fetchWebPageHandler(ctx context.Context, params FetchWebPageParams) (*Fetch
 	}
 	defer resp.Body.Close()
 
+	// Refuse binary content unless asked for it
+	if err := c.checkContentType(resp); err != nil {
+		if !params.AllowBinary {
+			return nil, err
+		}
+		extraction.binary = true
+	}
+
 	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	body, truncated, err := readBody(resp.Body, c.responseLimit(DefaultMaxResponseSize))
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
+	result, err := extraction.result(resp, body)
+	if err != nil {
+		return nil, err
+	}
+	result.URL = params.URL
+	result.Truncated = truncated
+	result.Cache = cache.status()
+
+	return result, nil
+}
+
+// pageExtraction holds the content options shared by tools that return a
+// fetched page, such as fetch_webpage and submit_form
+type pageExtraction struct {
+	extractText  bool
+	mode         string // "full" or "article"
+	outputFormat string // "text" or "markdown"
+	binary       bool   // Return the body base64-encoded as is
+	window       contentWindow
+}
+
+// newPageExtraction validates the extract_mode and output_format parameters
+func newPageExtraction(extractText bool, extractMode, outputFormat string) (pageExtraction, error) {
+	e := pageExtraction{extractText: extractText, mode: "full", outputFormat: "text"}
+
+	if extractMode != "" {
+		e.mode = extractMode
+	}
+	if e.mode != "full" && e.mode != "article" {
+		return e, fmt.Errorf("invalid extract_mode %q (must be 'full' or 'article')", extractMode)
+	}
+
+	if outputFormat != "" {
+		e.outputFormat = outputFormat
+	}
+	if e.outputFormat != "text" && e.outputFormat != "markdown" {
+		return e, fmt.Errorf("invalid output_format %q (must be 'text' or 'markdown')", outputFormat)
+	}
+
+	return e, nil
+}
+
+// result processes a fetched body into a FetchWebPageResult for the final
+// response URL
+func (e pageExtraction) result(resp *http.Response, body []byte) (*FetchWebPageResult, error) {
 	// Process content
-	content := string(body)
+	content := decodeBody(body, resp.Header.Get("Content-Type"))
+	encoding := ""
 	title := ""
+	var articleInfo *ArticleInfo
+
+	if e.binary {
+		// Binary content is passed through untouched rather than mangled as text
+		content = base64.StdEncoding.EncodeToString(body)
+		encoding = "base64"
+	} else if (e.mode == "article" || e.outputFormat == "markdown") && strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		title = extractTitle(content)
+
+		doc, err := parseHTML(content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing HTML: %w", err)
+		}
+		base := documentBaseURL(doc, resp.Request.URL)
 
-	if extractText && strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		root := doc
+		if e.mode == "article" {
+			art := extractArticle(doc, base)
+			root = art.content
+			articleInfo = &art.info
+		}
+
+		if e.outputFormat == "markdown" {
+			content = htmlToMarkdown(root, base)
+		} else {
+			content = domBlockText(root)
+		}
+	} else if e.extractText && strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
 		// Extract title
 		title = extractTitle(content)
 
@@ -157,17 +305,29 @@ func fetchWebPageHandler(ctx context.Context, params FetchWebPageParams) (*Fetch
 		content = extractTextFromHTML(content)
 	} else if strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
 		// Still extract title even if not extracting text
-		title = extractTitle(string(body))
+		title = extractTitle(content)
+	}
+
+	// Binary content is never split, as chunks of it are of no use on their own
+	var chunking *ChunkInfo
+	if !e.binary {
+		var err error
+		if content, chunking, err = e.window.apply(content); err != nil {
+			return nil, err
+		}
 	}
 
 	// Build result
 	result := &FetchWebPageResult{
-		URL:         params.URL,
+		URL:         resp.Request.URL.String(),
 		Title:       title,
 		Content:     content,
 		ContentType: resp.Header.Get("Content-Type"),
 		StatusCode:  resp.StatusCode,
 		Headers:     make(map[string]string),
+		Article:     articleInfo,
+		Encoding:    encoding,
+		Chunking:    chunking,
 		FetchedAt:   time.Now().UTC().Format(time.RFC3339),
 	}
 
@@ -243,6 +403,7 @@ type ExtractLinksParams struct {
 	IncludeEmail    bool   `json:"include_email,omitempty" description:"Include email links (default: true)"`
 	IncludeMedia    bool   `json:"include_media,omitempty" description:"Include media links (images, videos) (default: false)"`
 	Timeout         int    `json:"timeout,omitempty" description:"Timeout in seconds (default: 30)"`
+	Session         string `json:"session,omitempty" description:"Session whose cookies and default headers to use (default: the run's default session)"`
 }
 
 type ExtractLinksResult struct {
@@ -252,6 +413,7 @@ type ExtractLinksResult struct {
 	ExternalLinks []LinkInfo  `json:"external_links"`
 	EmailLinks    []string    `json:"email_links"`
 	MediaLinks    []MediaLink `json:"media_links,omitempty"`
+	Truncated     bool        `json:"truncated,omitempty"` // The page exceeded the size limit; later links are missing
 	FetchedAt     string      `json:"fetched_at"`
 }
 
@@ -297,20 +459,25 @@ var ExtractLinksParamSchema = &sdomain.Schema{
 			Minimum:     float64Ptr(1),
 			Maximum:     float64Ptr(300),
 		},
+		"session": {
+			Type:        "string",
+			Description: "Name of the session whose cookies and default headers carry over between calls, e.g. to stay logged in (default: the run's default session)",
+		},
 	},
 	Required: []string{"url"},
 }
 
-func NewExtractLinksTool() domain.Tool {
+func NewExtractLinksTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
 	return tools.NewTool(
 		"extract_links",
 		"Extracts all links from a web page, categorized by type",
-		extractLinksHandler,
+		cfg.extractLinksHandler,
 		ExtractLinksParamSchema,
 	)
 }
 
-func extractLinksHandler(ctx context.Context, params ExtractLinksParams) (*ExtractLinksResult, error) {
+func (c *toolConfig) extractLinksHandler(ctx context.Context, params ExtractLinksParams) (*ExtractLinksResult, error) {
 	// Set defaults
 	includeExternal := true
 	if !params.IncludeExternal {
@@ -337,9 +504,19 @@ func extractLinksHandler(ctx context.Context, params ExtractLinksParams) (*Extra
 		return nil, fmt.Errorf("parsing URL: %w", err)
 	}
 
-	// Fetch the web page
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
+	session, err := c.session(ctx, params.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the web page; redirect targets are subject to robots.txt too
+	client := c.client(time.Duration(timeout) * time.Second)
+	session.attach(client)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return c.checkRobots(req.Context(), client, req.URL)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", params.URL, nil)
@@ -348,6 +525,11 @@ func extractLinksHandler(ctx context.Context, params ExtractLinksParams) (*Extra
 	}
 
 	req.Header.Set("User-Agent", "go-flock/1.0 LinkExtractor")
+	session.applyHeaders(req)
+
+	if err := c.checkRobots(ctx, client, req.URL); err != nil {
+		return nil, err
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -355,16 +537,21 @@ func extractLinksHandler(ctx context.Context, params ExtractLinksParams) (*Extra
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	if err := c.checkContentType(resp); err != nil {
+		return nil, err
+	}
+
+	body, truncated, err := readBody(resp.Body, c.responseLimit(DefaultMaxResponseSize))
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
-	html := string(body)
+	html := decodeHTML(body, resp.Header.Get("Content-Type"))
 
 	// Initialize result
 	result := &ExtractLinksResult{
 		URL:           params.URL,
+		Truncated:     truncated,
 		InternalLinks: []LinkInfo{},
 		ExternalLinks: []LinkInfo{},
 		EmailLinks:    []string{},
@@ -373,48 +560,16 @@ func extractLinksHandler(ctx context.Context, params ExtractLinksParams) (*Extra
 	}
 
 	// Extract anchor links
-	anchorRegex := regexp.MustCompile(`(?i)<a[^>]+href\s*=\s*["']([^"']+)["'][^>]*>([^<]*)</a>`)
-	anchorMatches := anchorRegex.FindAllStringSubmatch(html, -1)
-
-	for _, match := range anchorMatches {
-		if len(match) < 3 {
-			continue
-		}
-
-		href := strings.TrimSpace(match[1])
-		text := strings.TrimSpace(match[2])
-
-		// Skip empty hrefs
-		if href == "" || href == "#" {
-			continue
-		}
-
-		// Check for email links
-		if strings.HasPrefix(href, "mailto:") {
-			if includeEmail {
-				email := strings.TrimPrefix(href, "mailto:")
-				result.EmailLinks = append(result.EmailLinks, email)
-			}
-			continue
-		}
-
-		// Parse and resolve URL
-		linkURL, err := resolveURL(baseURL, href)
+	links, emails := findAnchorLinks(html, baseURL)
+	if includeEmail {
+		result.EmailLinks = append(result.EmailLinks, emails...)
+	}
+	for _, linkInfo := range links {
+		linkURL, err := url.Parse(linkInfo.URL)
 		if err != nil {
 			continue
 		}
 
-		linkInfo := LinkInfo{
-			URL:  linkURL.String(),
-			Text: text,
-		}
-
-		// Extract title attribute if present
-		titleRegex := regexp.MustCompile(`title\s*=\s*["']([^"']+)["']`)
-		if titleMatch := titleRegex.FindStringSubmatch(match[0]); len(titleMatch) > 1 {
-			linkInfo.Title = titleMatch[1]
-		}
-
 		// Categorize link
 		if isInternalLink(baseURL, linkURL) {
 			if includeInternal {
@@ -471,6 +626,59 @@ func extractLinksHandler(ctx context.Context, params ExtractLinksParams) (*Extra
 	return result, nil
 }
 
+var (
+	anchorRegex    = regexp.MustCompile(`(?i)<a[^>]+href\s*=\s*["']([^"']+)["'][^>]*>([^<]*)</a>`)
+	linkTitleRegex = regexp.MustCompile(`title\s*=\s*["']([^"']+)["']`)
+)
+
+// findAnchorLinks returns the <a href> links of a page resolved against
+// baseURL, and the addresses of its mailto: links. Empty and "#" hrefs are
+// skipped.
+func findAnchorLinks(html string, baseURL *url.URL) ([]LinkInfo, []string) {
+	var links []LinkInfo
+	var emails []string
+
+	for _, match := range anchorRegex.FindAllStringSubmatch(html, -1) {
+		if len(match) < 3 {
+			continue
+		}
+
+		href := strings.TrimSpace(match[1])
+		text := strings.TrimSpace(match[2])
+
+		// Skip empty hrefs
+		if href == "" || href == "#" {
+			continue
+		}
+
+		// Check for email links
+		if strings.HasPrefix(href, "mailto:") {
+			emails = append(emails, strings.TrimPrefix(href, "mailto:"))
+			continue
+		}
+
+		// Parse and resolve URL
+		linkURL, err := resolveURL(baseURL, href)
+		if err != nil {
+			continue
+		}
+
+		linkInfo := LinkInfo{
+			URL:  linkURL.String(),
+			Text: text,
+		}
+
+		// Extract title attribute if present
+		if titleMatch := linkTitleRegex.FindStringSubmatch(match[0]); len(titleMatch) > 1 {
+			linkInfo.Title = titleMatch[1]
+		}
+
+		links = append(links, linkInfo)
+	}
+
+	return links, emails
+}
+
 // resolveURL resolves a potentially relative URL against a base URL
 func resolveURL(base *url.URL, href string) (*url.URL, error) {
 	// Parse the href
@@ -488,6 +696,54 @@ func isInternalLink(base, link *url.URL) bool {
 	return link.Host == base.Host || link.Host == ""
 }
 
+// fetchHTMLDocument fetches and parses an HTML page for the DOM-based tools,
+// honoring robots.txt, including on redirects. It returns the document and the
+// URL it was served from after redirects; documentBaseURL applies <base href>.
+func (c *toolConfig) fetchHTMLDocument(ctx context.Context, client *http.Client, session *Session, pageURL, userAgent string) (*html.Node, *url.URL, error) {
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return c.checkRobots(req.Context(), client, req.URL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	session.applyHeaders(req)
+
+	if err := c.checkRobots(ctx, client, req.URL); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching web page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if err := c.checkContentType(resp); err != nil {
+		return nil, nil, err
+	}
+
+	// A page cut short still parses; the DOM tools work with what arrived
+	body, _, err := readBody(resp.Body, c.responseLimit(DefaultMaxResponseSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	doc, err := parseHTML(decodeHTML(body, resp.Header.Get("Content-Type")))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+	return doc, resp.Request.URL, nil
+}
+
 // Extract Metadata Tool
 
 type ExtractMetadataParams struct {
@@ -504,7 +760,16 @@ type ExtractMetadataResult struct {
 	OpenGraph   map[string]string `json:"open_graph,omitempty"`
 	Twitter     map[string]string `json:"twitter,omitempty"`
 	Meta        map[string]string `json:"meta"`
-	FetchedAt   string            `json:"fetched_at"`
+
+	// Typed metadata from JSON-LD, microdata, Highwire and Dublin Core tags
+	Article          *ArticleMetadata      `json:"article,omitempty"`
+	ScholarlyArticle *ScholarlyMetadata    `json:"scholarly_article,omitempty"`
+	Organization     *OrganizationMetadata `json:"organization,omitempty"`
+	SchemaTypes      []string              `json:"schema_types,omitempty"`
+
+	Truncated bool   `json:"truncated,omitempty"` // The page exceeded the size limit and was cut short
+	Cache     string `json:"cache,omitempty"`     // "hit" or "miss" when a cache is configured
+	FetchedAt string `json:"fetched_at"`
 }
 
 var ExtractMetadataParamSchema = &sdomain.Schema{
@@ -525,16 +790,17 @@ var ExtractMetadataParamSchema = &sdomain.Schema{
 	Required: []string{"url"},
 }
 
-func NewExtractMetadataTool() domain.Tool {
+func NewExtractMetadataTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
 	return tools.NewTool(
 		"extract_metadata",
 		"Extracts metadata from a web page including Open Graph and Twitter Card data",
-		extractMetadataHandler,
+		cfg.extractMetadataHandler,
 		ExtractMetadataParamSchema,
 	)
 }
 
-func extractMetadataHandler(ctx context.Context, params ExtractMetadataParams) (*ExtractMetadataResult, error) {
+func (c *toolConfig) extractMetadataHandler(ctx context.Context, params ExtractMetadataParams) (*ExtractMetadataResult, error) {
 	// Set default timeout
 	timeout := 30
 	if params.Timeout > 0 {
@@ -542,9 +808,8 @@ func extractMetadataHandler(ctx context.Context, params ExtractMetadataParams) (
 	}
 
 	// Create HTTP client
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
-	}
+	client := c.client(time.Duration(timeout) * time.Second)
+	ctx, cache := withCacheRecorder(ctx)
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", params.URL, nil)
@@ -561,21 +826,27 @@ func extractMetadataHandler(ctx context.Context, params ExtractMetadataParams) (
 	}
 	defer resp.Body.Close()
 
+	if err := c.checkContentType(resp); err != nil {
+		return nil, err
+	}
+
 	// Read response
-	body, err := io.ReadAll(resp.Body)
+	body, truncated, err := readBody(resp.Body, c.responseLimit(DefaultMaxResponseSize))
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
-	html := string(body)
+	html := decodeHTML(body, resp.Header.Get("Content-Type"))
 
 	// Initialize result
 	result := &ExtractMetadataResult{
 		URL:       params.URL,
+		Truncated: truncated,
 		OpenGraph: make(map[string]string),
 		Twitter:   make(map[string]string),
 		Meta:      make(map[string]string),
 		Keywords:  []string{},
+		Cache:     cache.status(),
 		FetchedAt: time.Now().UTC().Format(time.RFC3339),
 	}
 
@@ -638,6 +909,17 @@ func extractMetadataHandler(ctx context.Context, params ExtractMetadataParams) (
 		}
 	}
 
+	// Extract structured data
+	doc, err := parseHTML(html)
+	if err != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+	structured := extractStructuredMetadata(doc, documentBaseURL(doc, resp.Request.URL))
+	result.Article = structured.Article
+	result.ScholarlyArticle = structured.Scholarly
+	result.Organization = structured.Organization
+	result.SchemaTypes = structured.Types
+
 	return result, nil
 }
 
@@ -707,16 +989,17 @@ var CheckURLStatusParamSchema = &sdomain.Schema{
 	Required: []string{"url"},
 }
 
-func NewCheckURLStatusTool() domain.Tool {
+func NewCheckURLStatusTool(opts ...ToolOption) domain.Tool {
+	cfg := newToolConfig(opts...)
 	return tools.NewTool(
 		"check_url_status",
 		"Checks if a URL is accessible and returns status information",
-		checkURLStatusHandler,
+		cfg.checkURLStatusHandler,
 		CheckURLStatusParamSchema,
 	)
 }
 
-func checkURLStatusHandler(ctx context.Context, params CheckURLStatusParams) (*CheckURLStatusResult, error) {
+func (c *toolConfig) checkURLStatusHandler(ctx context.Context, params CheckURLStatusParams) (*CheckURLStatusResult, error) {
 	// Set defaults
 	timeout := 10
 	if params.Timeout > 0 {
@@ -732,9 +1015,7 @@ func checkURLStatusHandler(ctx context.Context, params CheckURLStatusParams) (*C
 	startTime := time.Now()
 
 	// Create HTTP client
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
-	}
+	client := c.client(time.Duration(timeout) * time.Second)
 
 	// Track redirect chain
 	var redirectChain []string
@@ -837,7 +1118,4 @@ func checkURLStatusHandler(ctx context.Context, params CheckURLStatusParams) (*C
 }
 
 // Future tools could include:
-// - extract_structured_data: Extract specific data using CSS selectors
 // - screenshot_webpage: Capture visual representation
-// - submit_form: Submit forms on web pages
-// - extract_tables: Extract tabular data from HTML tables
@@ -63,7 +63,7 @@ func TestFetchWebPageHandler_Success(t *testing.T) {
 		ExtractText: true,
 	}
 
-	result, err := fetchWebPageHandler(ctx, params)
+	result, err := newToolConfig().fetchWebPageHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -103,7 +103,7 @@ func TestFetchWebPageHandler_NoTextExtraction(t *testing.T) {
 		ExtractText: false,
 	}
 
-	result, err := fetchWebPageHandler(ctx, params)
+	result, err := newToolConfig().fetchWebPageHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -120,6 +120,11 @@ func TestFetchWebPageHandler_NoTextExtraction(t *testing.T) {
 
 func TestFetchWebPageHandler_CustomHeaders(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+
 		// Check custom headers
 		if r.Header.Get("X-Custom-Header") != "test-value" {
 			t.Errorf("Expected custom header 'test-value', got %s", r.Header.Get("X-Custom-Header"))
@@ -141,7 +146,7 @@ func TestFetchWebPageHandler_CustomHeaders(t *testing.T) {
 		},
 	}
 
-	_, err := fetchWebPageHandler(ctx, params)
+	_, err := newToolConfig().fetchWebPageHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -167,7 +172,7 @@ func TestFetchWebPageHandler_Redirect(t *testing.T) {
 		FollowRedirects: true,
 	}
 
-	result, err := fetchWebPageHandler(ctx, params)
+	result, err := newToolConfig().fetchWebPageHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -193,7 +198,7 @@ func TestFetchWebPageHandler_NoFollowRedirect(t *testing.T) {
 		FollowRedirects: false,
 	}
 
-	result, err := fetchWebPageHandler(ctx, params)
+	result, err := newToolConfig().fetchWebPageHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -217,7 +222,7 @@ func TestFetchWebPageHandler_Timeout(t *testing.T) {
 		Timeout: 1, // 1 second timeout
 	}
 
-	_, err := fetchWebPageHandler(ctx, params)
+	_, err := newToolConfig().fetchWebPageHandler(ctx, params)
 	if err == nil {
 		t.Error("Expected timeout error, got nil")
 	}
@@ -233,7 +238,7 @@ func TestFetchWebPageHandler_InvalidURL(t *testing.T) {
 		URL: "not-a-valid-url",
 	}
 
-	_, err := fetchWebPageHandler(ctx, params)
+	_, err := newToolConfig().fetchWebPageHandler(ctx, params)
 	if err == nil {
 		t.Error("Expected error for invalid URL, got nil")
 	}
@@ -241,6 +246,10 @@ func TestFetchWebPageHandler_InvalidURL(t *testing.T) {
 
 func TestFetchWebPageHandler_ServerError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "Internal Server Error")
 	}))
@@ -251,7 +260,7 @@ func TestFetchWebPageHandler_ServerError(t *testing.T) {
 		URL: server.URL,
 	}
 
-	result, err := fetchWebPageHandler(ctx, params)
+	result, err := newToolConfig().fetchWebPageHandler(ctx, params)
 	// Should not error, but should capture the status code
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
@@ -275,7 +284,7 @@ func TestFetchWebPageHandler_NonHTMLContent(t *testing.T) {
 		ExtractText: true,
 	}
 
-	result, err := fetchWebPageHandler(ctx, params)
+	result, err := newToolConfig().fetchWebPageHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -324,7 +333,7 @@ func TestExtractLinksHandler_Success(t *testing.T) {
 		IncludeMedia:    true,
 	}
 
-	result, err := extractLinksHandler(ctx, params)
+	result, err := newToolConfig().extractLinksHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -379,7 +388,7 @@ func TestExtractLinksHandler_FilterTypes(t *testing.T) {
 		IncludeMedia:    false,
 	}
 
-	result, err := extractLinksHandler(ctx, params)
+	result, err := newToolConfig().extractLinksHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -415,7 +424,7 @@ func TestExtractLinksHandler_NoLinks(t *testing.T) {
 		URL: server.URL,
 	}
 
-	result, err := extractLinksHandler(ctx, params)
+	result, err := newToolConfig().extractLinksHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -444,7 +453,7 @@ func TestExtractLinksHandler_RelativeURLResolution(t *testing.T) {
 		URL: server.URL + "/subdir/page.html",
 	}
 
-	result, err := extractLinksHandler(ctx, params)
+	result, err := newToolConfig().extractLinksHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -487,7 +496,7 @@ func TestExtractMetadataHandler_Success(t *testing.T) {
 		URL: server.URL,
 	}
 
-	result, err := extractMetadataHandler(ctx, params)
+	result, err := newToolConfig().extractMetadataHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -569,7 +578,7 @@ func TestExtractMetadataHandler_ComplexMetadata(t *testing.T) {
 		URL: server.URL,
 	}
 
-	result, err := extractMetadataHandler(ctx, params)
+	result, err := newToolConfig().extractMetadataHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -619,7 +628,7 @@ func TestExtractMetadataHandler_NoMetadata(t *testing.T) {
 		URL: server.URL,
 	}
 
-	result, err := extractMetadataHandler(ctx, params)
+	result, err := newToolConfig().extractMetadataHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -668,7 +677,7 @@ func TestCheckURLStatusHandler_Alive(t *testing.T) {
 		URL: server.URL,
 	}
 
-	result, err := checkURLStatusHandler(ctx, params)
+	result, err := newToolConfig().checkURLStatusHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -712,7 +721,7 @@ func TestCheckURLStatusHandler_Redirect(t *testing.T) {
 		FollowRedirects: true,
 	}
 
-	result, err := checkURLStatusHandler(ctx, params)
+	result, err := newToolConfig().checkURLStatusHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -741,7 +750,7 @@ func TestCheckURLStatusHandler_Dead(t *testing.T) {
 		URL: server.URL,
 	}
 
-	result, err := checkURLStatusHandler(ctx, params)
+	result, err := newToolConfig().checkURLStatusHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -768,7 +777,7 @@ func TestCheckURLStatusHandler_Timeout(t *testing.T) {
 		Timeout: 1,
 	}
 
-	_, err := checkURLStatusHandler(ctx, params)
+	_, err := newToolConfig().checkURLStatusHandler(ctx, params)
 	if err == nil {
 		t.Error("Expected timeout error, got nil")
 	}
@@ -790,7 +799,7 @@ func TestCheckURLStatusHandler_NoFollowRedirect(t *testing.T) {
 		FollowRedirects: false,
 	}
 
-	result, err := checkURLStatusHandler(ctx, params)
+	result, err := newToolConfig().checkURLStatusHandler(ctx, params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}